@@ -0,0 +1,138 @@
+package assessment
+
+import (
+	"math"
+)
+
+// 三参数 Logistic 模型 (3PL) 的固定参数
+// 题目难度由词频决定，区分度统一，猜测参数取四选一的随机猜对概率
+const (
+	discrimination = 1.0
+	guessing       = 0.25
+)
+
+const (
+	// pivotRank 难度为0的词频排名，即普通学习者掌握一半的位置
+	pivotRank = 3000.0
+	// rankScale 每单位能力对应的 ln(词频排名) 跨度
+	rankScale = 0.8
+	// thetaMin / thetaMax 能力估计范围
+	thetaMin = -4.0
+	thetaMax = 4.0
+	// gridPoints EAP积分网格点数
+	gridPoints = 81
+)
+
+// Response 一次作答
+type Response struct {
+	Difficulty float64
+	Correct    bool
+}
+
+// DifficultyForRank 根据词频排名计算题目难度
+// 排名越靠后（越少见）难度越高，难度与排名的对数成正比
+func DifficultyForRank(rank int) float64 {
+	if rank < 1 {
+		rank = 1
+	}
+	return (math.Log(float64(rank)) - math.Log(pivotRank)) / rankScale
+}
+
+// RankForDifficulty DifficultyForRank 的反函数
+func RankForDifficulty(b float64) int {
+	return int(math.Round(math.Exp(b*rankScale + math.Log(pivotRank))))
+}
+
+// probability 能力为 theta 的用户答对难度为 b 的题目的概率
+func probability(theta, b float64) float64 {
+	return guessing + (1-guessing)/(1+math.Exp(-discrimination*(theta-b)))
+}
+
+// Information 题目在 theta 处的 Fisher 信息量
+// 自适应选题时选择信息量最大的题目
+func Information(theta, b float64) float64 {
+	p := probability(theta, b)
+	q := 1 - p
+	r := (p - guessing) / (1 - guessing)
+	return discrimination * discrimination * q / p * r * r
+}
+
+// EstimateAbility 以标准正态先验的期望后验 (EAP) 估计能力
+// 全部答对或全部答错时依然收敛，适合题目较少的自适应测试
+// 返回: 能力估计值和后验标准差
+func EstimateAbility(responses []Response) (float64, float64) {
+	step := (thetaMax - thetaMin) / float64(gridPoints-1)
+	var sumW, sumWT float64
+	weights := make([]float64, gridPoints)
+	thetas := make([]float64, gridPoints)
+	for i := range weights {
+		theta := thetaMin + float64(i)*step
+		logL := -theta * theta / 2
+		for _, r := range responses {
+			p := probability(theta, r.Difficulty)
+			if r.Correct {
+				logL += math.Log(p)
+			} else {
+				logL += math.Log(1 - p)
+			}
+		}
+		thetas[i] = theta
+		weights[i] = math.Exp(logL)
+		sumW += weights[i]
+		sumWT += weights[i] * theta
+	}
+	if sumW == 0 {
+		return 0, 1
+	}
+	mean := sumWT / sumW
+
+	var variance float64
+	for i, w := range weights {
+		d := thetas[i] - mean
+		variance += w * d * d
+	}
+	return mean, math.Sqrt(variance / sumW)
+}
+
+// VocabularySize 根据能力估计词汇量
+// 即掌握概率为50%的词频排名，更常用的词大多认识，更少见的词大多不认识
+func VocabularySize(theta float64) int {
+	return RankForDifficulty(theta)
+}
+
+// cefrBands 词汇量对应的CEFR等级下限
+var cefrBands = []struct {
+	Level string
+	Min   int
+}{
+	{"C2", 8000},
+	{"C1", 5000},
+	{"B2", 3250},
+	{"B1", 2000},
+	{"A2", 1000},
+	{"A1", 0},
+}
+
+// CEFRLevel 根据词汇量返回CEFR等级
+func CEFRLevel(size int) string {
+	for _, band := range cefrBands {
+		if size >= band.Min {
+			return band.Level
+		}
+	}
+	return "A1"
+}
+
+// VocabularyScore 将词汇量映射到0-100分
+// 按对数刻度，250词为0分，16000词为100分
+func VocabularyScore(size int) int {
+	const low, high = 250.0, 16000.0
+	if size <= int(low) {
+		return 0
+	}
+	score := 100 * (math.Log(float64(size)) - math.Log(low)) / (math.Log(high) - math.Log(low))
+	if score > 100 {
+		score = 100
+	}
+	return int(math.Round(score))
+}
@@ -0,0 +1,113 @@
+package assessment
+
+import (
+	"math"
+	"testing"
+)
+
+func TestDifficultyForRank(t *testing.T) {
+	tests := []struct {
+		rank int
+		want float64
+	}{
+		{3000, 0},
+		{0, DifficultyForRank(1)},
+		{int(math.Round(3000 * math.E)), 1 / rankScale},
+	}
+	for _, tt := range tests {
+		if got := DifficultyForRank(tt.rank); math.Abs(got-tt.want) > 1e-4 {
+			t.Errorf("DifficultyForRank(%d) = %v, want %v", tt.rank, got, tt.want)
+		}
+	}
+	for _, rank := range []int{1, 500, 3000, 12000} {
+		if got := RankForDifficulty(DifficultyForRank(rank)); got != rank {
+			t.Errorf("RankForDifficulty(DifficultyForRank(%d)) = %d", rank, got)
+		}
+	}
+}
+
+func TestEstimateAbility(t *testing.T) {
+	items := []float64{-2, -1, 0, 1, 2}
+	answer := func(correct ...bool) []Response {
+		rs := make([]Response, len(correct))
+		for i, c := range correct {
+			rs[i] = Response{Difficulty: items[i], Correct: c}
+		}
+		return rs
+	}
+	tests := []struct {
+		name     string
+		resp     []Response
+		min, max float64
+	}{
+		{"no answers gives prior", nil, -0.01, 0.01},
+		{"all correct", answer(true, true, true, true, true), 0.5, thetaMax},
+		{"all wrong", answer(false, false, false, false, false), thetaMin, -0.5},
+		{"easy right hard wrong", answer(true, true, true, false, false), -1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			theta, se := EstimateAbility(tt.resp)
+			if theta < tt.min || theta > tt.max {
+				t.Errorf("theta = %v, want in [%v, %v]", theta, tt.min, tt.max)
+			}
+			if se <= 0 || se > 1 || math.IsNaN(se) {
+				t.Errorf("se = %v", se)
+			}
+		})
+	}
+
+	// 作答越多后验越集中
+	_, few := EstimateAbility(answer(true, false))
+	_, many := EstimateAbility(answer(true, true, true, false, false))
+	if many >= few {
+		t.Errorf("se with 5 answers %v >= se with 2 answers %v", many, few)
+	}
+}
+
+func TestInformation(t *testing.T) {
+	// 能力与难度相差越远，题目信息量越小
+	near, far := Information(0, 0.2), Information(0, 3)
+	if near <= far || far <= 0 {
+		t.Errorf("Information near = %v, far = %v", near, far)
+	}
+}
+
+func TestCEFRLevel(t *testing.T) {
+	tests := []struct {
+		size int
+		want string
+	}{
+		{0, "A1"},
+		{999, "A1"},
+		{1000, "A2"},
+		{2000, "B1"},
+		{3249, "B1"},
+		{3250, "B2"},
+		{5000, "C1"},
+		{20000, "C2"},
+	}
+	for _, tt := range tests {
+		if got := CEFRLevel(tt.size); got != tt.want {
+			t.Errorf("CEFRLevel(%d) = %s, want %s", tt.size, got, tt.want)
+		}
+	}
+}
+
+func TestVocabularyScore(t *testing.T) {
+	tests := []struct {
+		size, want int
+	}{
+		{0, 0},
+		{250, 0},
+		{1000, 33},
+		{4000, 67},
+		{16000, 100},
+		{50000, 100},
+	}
+	for _, tt := range tests {
+		if got := VocabularyScore(tt.size); got != tt.want {
+			t.Errorf("VocabularyScore(%d) = %d, want %d", tt.size, got, tt.want)
+		}
+	}
+}
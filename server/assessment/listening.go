@@ -0,0 +1,153 @@
+package assessment
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+	"server/models"
+)
+
+const (
+	// ListeningItemsPerPhenomenon 每种听力现象的题数
+	ListeningItemsPerPhenomenon = 2
+	// MaxPlays 每题最多播放次数
+	MaxPlays = 2
+	// PlayURLTTL 每次播放下发的签名地址有效期，过期后需重新计一次播放
+	PlayURLTTL = 2 * time.Minute
+	// replayCredit 第二次播放后答对的得分比例
+	replayCredit = 0.8
+)
+
+// 听力测试错误
+var (
+	ErrListeningBankTooSmall = errors.New("听力题库不足")
+	ErrPlayLimitReached      = errors.New("播放次数已用完")
+	ErrNotPlayed             = errors.New("请先播放音频")
+	ErrAudioNotHosted        = errors.New("题目音频不是托管音频")
+)
+
+// phenomenonWeights 各听力现象在综合分中的权重
+// 常速理解最能反映真实听力，慢速只作参考
+var phenomenonWeights = map[string]float64{
+	models.PhenomenonSlow:     0.1,
+	models.PhenomenonNormal:   0.3,
+	models.PhenomenonLiaison:  0.2,
+	models.PhenomenonWeakForm: 0.2,
+	models.PhenomenonElision:  0.2,
+}
+
+// listeningBands 听力分数对应的CEFR等级下限
+var listeningBands = []struct {
+	Level string
+	Min   int
+}{
+	{"C2", 90},
+	{"C1", 75},
+	{"B2", 60},
+	{"B1", 45},
+	{"A2", 25},
+	{"A1", 0},
+}
+
+// ListeningResult 听力测试结果
+type ListeningResult struct {
+	SubScores map[string]int `json:"sub_scores"` // 各听力现象得分 (0-100)
+	Score     int            `json:"score"`      // 综合分数 (0-100)
+	Level     string         `json:"level"`      // CEFR等级
+}
+
+// CheckListeningItemBank 检查每种听力现象的题目是否足够
+func CheckListeningItemBank(db *gorm.DB) error {
+	for _, p := range models.ListeningPhenomena {
+		var count int64
+		if err := db.Model(&models.ListeningItem{}).Where("phenomenon = ?", p).Count(&count).Error; err != nil {
+			return err
+		}
+		if count < ListeningItemsPerPhenomenon {
+			return ErrListeningBankTooSmall
+		}
+	}
+	return nil
+}
+
+// SelectListeningItems 为测评会话抽取题目
+// 按慢速、常速、连读、弱读、吞音的顺序，每种现象随机抽取固定题数
+func SelectListeningItems(db *gorm.DB, sessionID uint) ([]models.ListeningTestItem, error) {
+	items := make([]models.ListeningTestItem, 0, len(models.ListeningPhenomena)*ListeningItemsPerPhenomenon)
+	for _, p := range models.ListeningPhenomena {
+		var bank []models.ListeningItem
+		if err := db.Where("phenomenon = ?", p).Order("RANDOM()").
+			Limit(ListeningItemsPerPhenomenon).Find(&bank).Error; err != nil {
+			return nil, err
+		}
+		if len(bank) < ListeningItemsPerPhenomenon {
+			return nil, ErrListeningBankTooSmall
+		}
+		for _, it := range bank {
+			items = append(items, models.ListeningTestItem{
+				SessionID:    sessionID,
+				Seq:          len(items) + 1,
+				ItemID:       it.ID,
+				Phenomenon:   it.Phenomenon,
+				Options:      it.Options,
+				CorrectIndex: it.CorrectIndex,
+			})
+		}
+	}
+	return items, nil
+}
+
+// ScoreListening 计算各听力现象得分和综合分
+// 第一次播放即答对得满分，重播后答对按 replayCredit 计分
+func ScoreListening(items []models.ListeningTestItem) ListeningResult {
+	credit := make(map[string]float64)
+	total := make(map[string]int)
+	for _, it := range items {
+		total[it.Phenomenon]++
+		if it.Correct == nil || !*it.Correct {
+			continue
+		}
+		if it.Plays > 1 {
+			credit[it.Phenomenon] += replayCredit
+		} else {
+			credit[it.Phenomenon]++
+		}
+	}
+
+	result := ListeningResult{SubScores: make(map[string]int)}
+	var weighted float64
+	for _, p := range models.ListeningPhenomena {
+		var score float64
+		if total[p] > 0 {
+			score = 100 * credit[p] / float64(total[p])
+		}
+		result.SubScores[p] = int(math.Round(score))
+		weighted += phenomenonWeights[p] * score
+	}
+	result.Score = int(math.Round(weighted))
+	result.Level = ListeningLevel(result.Score)
+	return result
+}
+
+// ListeningLevel 根据听力分数返回CEFR等级
+func ListeningLevel(score int) string {
+	for _, band := range listeningBands {
+		if score >= band.Min {
+			return band.Level
+		}
+	}
+	return "A1"
+}
+
+// ApplyToProfile 将测试结果写入听力分项水平
+func (r ListeningResult) ApplyToProfile(p *models.ListeningProfile) {
+	p.SlowScore = r.SubScores[models.PhenomenonSlow]
+	p.NormalScore = r.SubScores[models.PhenomenonNormal]
+	p.LiaisonScore = r.SubScores[models.PhenomenonLiaison]
+	p.WeakFormScore = r.SubScores[models.PhenomenonWeakForm]
+	p.ElisionScore = r.SubScores[models.PhenomenonElision]
+	p.OverallScore = r.Score
+	p.Level = r.Level
+}
@@ -0,0 +1,181 @@
+package assessment
+
+import (
+	"errors"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"server/models"
+)
+
+const (
+	// VocabTestItems 词汇测试题数
+	VocabTestItems = 15
+	// ItemTimeLimit 每题作答时限
+	ItemTimeLimit = 30 * time.Second
+	// answerGrace 网络延迟容忍时间
+	answerGrace = 5 * time.Second
+	// candidatesPerSide 选题时在目标难度两侧各取的候选单词数
+	candidatesPerSide = 6
+	// topCandidates 在信息量最高的若干题中随机选择，避免所有人拿到相同题目
+	topCandidates = 4
+	// optionCount 每题选项数
+	optionCount = 4
+)
+
+// 词汇测试错误
+var (
+	ErrItemBankTooSmall = errors.New("词汇题库不足")
+	ErrSessionFinished  = errors.New("测评已结束")
+	ErrItemMismatch     = errors.New("题目不是当前待答题目")
+)
+
+// VocabResult 词汇测试结果
+type VocabResult struct {
+	Theta     float64 `json:"theta"`
+	StdErr    float64 `json:"std_err"`
+	VocabSize int     `json:"vocab_size"` // 估计词汇量
+	Level     string  `json:"level"`      // CEFR等级
+	Score     int     `json:"score"`      // 词汇分数 (0-100)
+}
+
+// CheckVocabItemBank 检查题库是否足够完成一次测试
+func CheckVocabItemBank(db *gorm.DB) error {
+	var count int64
+	if err := db.Model(&models.Word{}).Where("frequency > 0").Count(&count).Error; err != nil {
+		return err
+	}
+	if count < VocabTestItems+optionCount-1 {
+		return ErrItemBankTooSmall
+	}
+	return nil
+}
+
+// SelectVocabItem 根据当前能力估计选出下一题
+// 在目标难度附近取候选单词，按 Fisher 信息量排序后从前几名中随机选择
+func SelectVocabItem(db *gorm.DB, session *models.AssessmentSession, usedWordIDs []uint) (*models.VocabTestItem, error) {
+	target := RankForDifficulty(session.Theta)
+
+	exclude := usedWordIDs
+	if len(exclude) == 0 {
+		exclude = []uint{0}
+	}
+
+	var above, below []models.Word
+	if err := db.Where("frequency >= ? AND id NOT IN ?", target, exclude).
+		Order("frequency ASC").Limit(candidatesPerSide).Find(&above).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Where("frequency > 0 AND frequency < ? AND id NOT IN ?", target, exclude).
+		Order("frequency DESC").Limit(candidatesPerSide).Find(&below).Error; err != nil {
+		return nil, err
+	}
+	candidates := append(above, below...)
+	if len(candidates) == 0 {
+		return nil, ErrItemBankTooSmall
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return Information(session.Theta, DifficultyForRank(candidates[i].Frequency)) >
+			Information(session.Theta, DifficultyForRank(candidates[j].Frequency))
+	})
+	n := topCandidates
+	if n > len(candidates) {
+		n = len(candidates)
+	}
+	word := candidates[rand.IntN(n)]
+
+	options, correct, err := buildOptions(db, word)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.VocabTestItem{
+		SessionID:    session.ID,
+		Seq:          session.Answered + 1,
+		WordID:       word.ID,
+		Word:         word.Headword,
+		Difficulty:   DifficultyForRank(word.Frequency),
+		Options:      options,
+		CorrectIndex: correct,
+		ServedAt:     time.Now(),
+	}, nil
+}
+
+// buildOptions 生成释义选项
+// 干扰项优先取词频相近的单词，使选项难度与题目一致
+func buildOptions(db *gorm.DB, word models.Word) ([]string, int, error) {
+	correct := primaryMeaning(word.Meaning)
+	seen := map[string]bool{correct: true}
+	options := []string{correct}
+
+	queries := []*gorm.DB{
+		db.Where("id <> ? AND frequency BETWEEN ? AND ?", word.ID, word.Frequency/2, word.Frequency*2+10),
+		db.Where("id <> ?", word.ID),
+	}
+	for _, q := range queries {
+		var pool []models.Word
+		if err := q.Order("RANDOM()").Limit(optionCount * 3).Find(&pool).Error; err != nil {
+			return nil, 0, err
+		}
+		for _, w := range pool {
+			m := primaryMeaning(w.Meaning)
+			if m == "" || seen[m] {
+				continue
+			}
+			seen[m] = true
+			options = append(options, m)
+			if len(options) == optionCount {
+				break
+			}
+		}
+		if len(options) == optionCount {
+			break
+		}
+	}
+	if len(options) < optionCount {
+		return nil, 0, ErrItemBankTooSmall
+	}
+
+	rand.Shuffle(len(options), func(i, j int) { options[i], options[j] = options[j], options[i] })
+	for i, o := range options {
+		if o == correct {
+			return options, i, nil
+		}
+	}
+	return options, 0, nil
+}
+
+// primaryMeaning 取释义的第一行作为选项
+func primaryMeaning(meaning string) string {
+	line, _, _ := strings.Cut(meaning, "\n")
+	return strings.TrimSpace(line)
+}
+
+// AnswerExpired 判断题目是否已超时
+func AnswerExpired(item *models.VocabTestItem, now time.Time) bool {
+	return now.Sub(item.ServedAt) > ItemTimeLimit+answerGrace
+}
+
+// ScoreVocab 根据全部已答题目估计能力和词汇量
+func ScoreVocab(items []models.VocabTestItem) VocabResult {
+	responses := make([]Response, 0, len(items))
+	for _, it := range items {
+		if it.Correct == nil {
+			continue
+		}
+		responses = append(responses, Response{Difficulty: it.Difficulty, Correct: *it.Correct})
+	}
+	theta, se := EstimateAbility(responses)
+	size := VocabularySize(theta)
+	return VocabResult{
+		Theta:     theta,
+		StdErr:    se,
+		VocabSize: size,
+		Level:     CEFRLevel(size),
+		Score:     VocabularyScore(size),
+	}
+}
@@ -0,0 +1,193 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"server/models"
+	"server/speech"
+	"server/storage"
+	"server/utils"
+	"server/waveform"
+)
+
+// 音频上传错误
+var (
+	ErrUnsupportedType = errors.New("仅支持 WAV 格式的音频")
+	ErrTooLarge        = errors.New("音频文件过大")
+	ErrEmpty           = errors.New("音频文件为空")
+	ErrInvalidSpeed    = errors.New("播放速度无效")
+)
+
+const (
+	// contentType 托管音频统一为 WAV，变速需要解码为 PCM
+	contentType = "audio/wav"
+	// speedSteps 播放速度按 1/speedSteps（0.05）取整，限制缓存的变速版本数量
+	speedSteps = 20
+	// urlPrefix 托管音频的访问路径
+	urlPrefix = "/api/audio/"
+)
+
+// Save 校验并保存上传的音频
+// 先写对象存储再写数据库，数据库写入失败时删除已上传的对象
+func Save(ctx context.Context, db *gorm.DB, store storage.Storage, title string, data []byte, maxSize int) (*models.AudioAsset, error) {
+	if len(data) == 0 {
+		return nil, ErrEmpty
+	}
+	if maxSize > 0 && len(data) > maxSize {
+		return nil, ErrTooLarge
+	}
+	pcm, err := speech.DecodeWAVChannels(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+
+	key, err := newKey()
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	if err := store.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return nil, fmt.Errorf("store audio: %w", err)
+	}
+
+	asset := &models.AudioAsset{
+		Title:       title,
+		StorageKey:  key,
+		ContentType: contentType,
+		ContentHash: hex.EncodeToString(sum[:]),
+		Size:        int64(len(data)),
+		Duration:    pcm.Duration(),
+		SampleRate:  pcm.SampleRate,
+		Channels:    len(pcm.Channels),
+	}
+	if err := db.Create(asset).Error; err != nil {
+		_ = store.Delete(ctx, key)
+		return nil, err
+	}
+	// 波形由后台生成，入队失败不影响上传，查询波形时会重新入队
+	if err := waveform.Enqueue(db, models.WaveformSourceAudio, asset.ID); err != nil {
+		utils.Warn("audio.Save - Enqueue waveform failed: AudioID=%d: %v", asset.ID, err)
+	}
+	return asset, nil
+}
+
+// Delete 删除音频及其波形、缓存的变速版本
+func Delete(ctx context.Context, db *gorm.DB, store storage.Storage, cacheDir string, asset *models.AudioAsset) error {
+	if err := db.Delete(asset).Error; err != nil {
+		return err
+	}
+	if err := waveform.Remove(db, models.WaveformSourceAudio, asset.ID); err != nil {
+		return err
+	}
+	if err := store.Delete(ctx, asset.StorageKey); err != nil {
+		return err
+	}
+	return RemoveVariants(cacheDir, asset.ID)
+}
+
+// newKey 生成对象存储 key
+func newKey() (string, error) {
+	buf := make([]byte, 12)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "audio/" + hex.EncodeToString(buf) + ".wav", nil
+}
+
+// ParseSpeed 解析播放速度，空字符串表示原速
+// 速度需在 [MinSpeed, MaxSpeed] 范围内，按 0.05 取整
+func ParseSpeed(s string) (float64, error) {
+	if s == "" {
+		return 1, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsNaN(v) || v < speech.MinSpeed || v > speech.MaxSpeed {
+		return 0, ErrInvalidSpeed
+	}
+	return math.Round(v*speedSteps) / speedSteps, nil
+}
+
+// FormatSpeed 格式化播放速度，如 0.75、1、1.25
+func FormatSpeed(speed float64) string {
+	return strconv.FormatFloat(speed, 'f', -1, 64)
+}
+
+// URL 托管音频的访问地址
+func URL(id uint, speed float64) string {
+	u := urlPrefix + strconv.FormatUint(uint64(id), 10)
+	if speed != 1 {
+		u += "?speed=" + FormatSpeed(speed)
+	}
+	return u
+}
+
+// SpeedURL 返回音频地址对应速度的版本
+// 只有托管音频能在服务端变速，外部地址原样返回，由客户端调整播放速率
+func SpeedURL(audioURL string, speed float64) (string, bool) {
+	id, ok := AssetID(audioURL)
+	if !ok {
+		return audioURL, false
+	}
+	return URL(id, speed), true
+}
+
+// Clip 截取托管音频 [start, end] 秒的片段并编码为 WAV，用作跟读评分的原声
+// 外部地址无法截取、或片段超出音频范围时返回 ok=false
+func Clip(ctx context.Context, db *gorm.DB, store storage.Storage, audioURL string, start, end float64) ([]byte, bool, error) {
+	id, ok := AssetID(audioURL)
+	if !ok {
+		return nil, false, nil
+	}
+	var asset models.AudioAsset
+	if err := db.First(&asset, id).Error; err != nil {
+		return nil, false, err
+	}
+	obj, err := store.Open(ctx, asset.StorageKey)
+	if err != nil {
+		return nil, false, err
+	}
+	data, err := io.ReadAll(obj.Body)
+	obj.Body.Close()
+	if err != nil {
+		return nil, false, fmt.Errorf("read audio: %w", err)
+	}
+	pcm, err := speech.DecodeWAVChannels(data)
+	if err != nil {
+		return nil, false, fmt.Errorf("decode audio: %w", err)
+	}
+
+	from := min(max(int(start*float64(pcm.SampleRate)), 0), pcm.Frames())
+	to := min(max(int(math.Ceil(end*float64(pcm.SampleRate))), from), pcm.Frames())
+	if to == from {
+		return nil, false, nil
+	}
+	clip := &speech.PCM{Channels: make([][]float64, len(pcm.Channels)), SampleRate: pcm.SampleRate}
+	for ch, samples := range pcm.Channels {
+		clip.Channels[ch] = samples[from:to]
+	}
+	return speech.EncodeWAV(clip), true, nil
+}
+
+// AssetID 解析托管音频地址中的音频ID，外部地址返回 false
+func AssetID(audioURL string) (uint, bool) {
+	rest, ok := strings.CutPrefix(audioURL, urlPrefix)
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(rest, 10, 64)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,210 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"server/models"
+	"server/speech"
+	"server/storage"
+	"server/utils"
+)
+
+// renderLocks 每个变速版本一把锁，避免并发请求重复渲染同一文件
+// 渲染结束后删除，只保留正在渲染的版本
+var renderLocks sync.Map
+
+// tempPrefix 渲染中的临时文件名前缀
+const tempPrefix = ".render-"
+
+// variantPath 变速版本的缓存文件路径
+func variantPath(cacheDir string, id uint, speed float64) string {
+	return filepath.Join(cacheDir, strconv.FormatUint(uint64(id), 10), FormatSpeed(speed)+".wav")
+}
+
+// Open 打开指定速度的音频，返回可随机读取的文件以支持 Range 请求
+// 原速时对象存储返回的内容可以 seek（本地存储）则直接使用，否则与变速版本一样缓存到本地
+func Open(ctx context.Context, store storage.Storage, cacheDir string, asset *models.AudioAsset, speed float64) (io.ReadSeekCloser, error) {
+	if speed == 1 {
+		if _, err := os.Stat(variantPath(cacheDir, asset.ID, speed)); err != nil {
+			obj, err := store.Open(ctx, asset.StorageKey)
+			if err != nil {
+				return nil, err
+			}
+			if rs, ok := obj.Body.(io.ReadSeekCloser); ok {
+				return rs, nil
+			}
+			obj.Body.Close()
+		}
+	}
+	path, err := Variant(ctx, store, cacheDir, asset, speed)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		// 刚好被缓存清理删除时重新渲染一次
+		if path, err = Variant(ctx, store, cacheDir, asset, speed); err != nil {
+			return nil, err
+		}
+		f, err = os.Open(path)
+	}
+	return f, err
+}
+
+// Variant 返回变速版本的本地文件路径，不存在时从原始音频渲染并写入缓存
+// 原速版本即原始文件的副本；先写临时文件再重命名，读取方不会看到写了一半的文件
+func Variant(ctx context.Context, store storage.Storage, cacheDir string, asset *models.AudioAsset, speed float64) (string, error) {
+	path := variantPath(cacheDir, asset.ID, speed)
+	if _, err := os.Stat(path); err == nil {
+		touch(path)
+		return path, nil
+	}
+
+	v, _ := renderLocks.LoadOrStore(path, &sync.Mutex{})
+	mu := v.(*sync.Mutex)
+	mu.Lock()
+	defer func() {
+		renderLocks.Delete(path)
+		mu.Unlock()
+	}()
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	obj, err := store.Open(ctx, asset.StorageKey)
+	if err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(obj.Body)
+	obj.Body.Close()
+	if err != nil {
+		return "", fmt.Errorf("read audio: %w", err)
+	}
+	rendered := data
+	if speed != 1 {
+		pcm, err := speech.DecodeWAVChannels(data)
+		if err != nil {
+			return "", fmt.Errorf("decode audio: %w", err)
+		}
+		rendered = speech.EncodeWAV(speech.TimeStretch(pcm, speed))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), tempPrefix+"*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := tmp.Write(rendered); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return path, nil
+}
+
+// touch 更新缓存文件的修改时间，作为最近使用时间供清理时参考
+func touch(path string) {
+	now := time.Now()
+	os.Chtimes(path, now, now)
+}
+
+// StartCachePruner 启动后台任务，定期将缓存目录清理到 maxBytes 以内
+func StartCachePruner(cacheDir string, maxBytes int64, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			n, err := PruneCache(cacheDir, maxBytes, time.Now())
+			if err != nil {
+				utils.Error("Audio cache - Prune failed after %d deletions: %v", n, err)
+			} else if n > 0 {
+				utils.Info("Audio cache - Pruned %d cached variants", n)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// PruneCache 缓存目录超过 maxBytes 时按最近使用时间从旧到新删除缓存文件，返回删除数量
+// 超过一小时的临时文件视为渲染中断的残留，一并删除；正在读取的文件删除后仍可读完
+func PruneCache(cacheDir string, maxBytes int64, now time.Time) (int, error) {
+	type entry struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []entry
+	var total int64
+	removed := 0
+	err := filepath.WalkDir(cacheDir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), tempPrefix) {
+			if now.Sub(info.ModTime()) > time.Hour && os.Remove(path) == nil {
+				removed++
+			}
+			return nil
+		}
+		files = append(files, entry{path, info.Size(), info.ModTime()})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return removed, err
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, f := range files {
+		if total <= maxBytes {
+			break
+		}
+		if err := os.Remove(f.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return removed, err
+		}
+		total -= f.size
+		removed++
+		// 音频的版本都已删除时顺带删除其目录，目录非空时删除失败可忽略
+		os.Remove(filepath.Dir(f.path))
+	}
+	return removed, nil
+}
+
+// RemoveVariants 删除音频缓存的全部变速版本
+func RemoveVariants(cacheDir string, id uint) error {
+	err := os.RemoveAll(filepath.Join(cacheDir, strconv.FormatUint(uint64(id), 10)))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,185 @@
+package avatar
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"regexp"
+	"slices"
+
+	"github.com/gabriel-vasile/mimetype"
+	"golang.org/x/image/draw"
+	"golang.org/x/image/webp"
+	"server/storage"
+)
+
+// Sizes 生成的缩略图边长（像素）
+var Sizes = []int{64, 128, 256}
+
+const (
+	// MaxUploadSize 头像文件大小上限
+	MaxUploadSize = 10 << 20
+	// minDimension 原图短边下限
+	minDimension = 32
+	// maxPixels 原图像素上限，在解码前检查以防止解压炸弹
+	maxPixels = 40_000_000
+	// jpegQuality 缩略图 JPEG 质量
+	jpegQuality = 85
+)
+
+// 头像处理错误
+var (
+	ErrUnsupportedType = errors.New("仅支持 JPEG、PNG、WebP 格式的图片")
+	ErrInvalidImage    = errors.New("图片已损坏或无法解析")
+	ErrTooSmall        = errors.New("图片尺寸过小")
+	ErrTooManyPixels   = errors.New("图片尺寸过大")
+)
+
+// versionPattern 头像版本号格式
+var versionPattern = regexp.MustCompile(`^[0-9a-f]{16}$`)
+
+// decoders 支持的图片格式
+var decoders = map[string]struct {
+	decode       func([]byte) (image.Image, error)
+	decodeConfig func([]byte) (image.Config, error)
+}{
+	"image/jpeg": {
+		decode:       func(b []byte) (image.Image, error) { return jpeg.Decode(bytes.NewReader(b)) },
+		decodeConfig: func(b []byte) (image.Config, error) { return jpeg.DecodeConfig(bytes.NewReader(b)) },
+	},
+	"image/png": {
+		decode:       func(b []byte) (image.Image, error) { return png.Decode(bytes.NewReader(b)) },
+		decodeConfig: func(b []byte) (image.Config, error) { return png.DecodeConfig(bytes.NewReader(b)) },
+	},
+	"image/webp": {
+		decode:       func(b []byte) (image.Image, error) { return webp.Decode(bytes.NewReader(b)) },
+		decodeConfig: func(b []byte) (image.Config, error) { return webp.DecodeConfig(bytes.NewReader(b)) },
+	},
+}
+
+// Process 校验图片并生成各尺寸的正方形缩略图
+// 按内容识别格式，JPEG 按 EXIF 方向转正；输出重新编码的 JPEG，原图中的 EXIF 等元数据全部丢弃
+func Process(data []byte) (map[int][]byte, error) {
+	contentType := mimetype.Detect(data).String()
+	dec, ok := decoders[contentType]
+	if !ok {
+		return nil, ErrUnsupportedType
+	}
+
+	cfg, err := dec.decodeConfig(data)
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	if cfg.Width < minDimension || cfg.Height < minDimension {
+		return nil, ErrTooSmall
+	}
+	if cfg.Width*cfg.Height > maxPixels {
+		return nil, ErrTooManyPixels
+	}
+
+	img, err := dec.decode(data)
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+
+	// 先裁剪并缩小到最大尺寸，再按 EXIF 方向转正，逐像素旋转只处理缩略图大小的图片
+	// 居中裁剪正方形与旋转、翻转可交换顺序
+	square := cropSquare(img)
+	side := slices.Max(Sizes)
+	base := image.NewRGBA(image.Rect(0, 0, side, side))
+	// 透明区域铺白底，JPEG 不支持透明
+	draw.Draw(base, base.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(base, base.Bounds(), square, square.Bounds(), draw.Over, nil)
+	var oriented image.Image = base
+	if contentType == "image/jpeg" {
+		oriented = applyOrientation(base, jpegOrientation(data))
+	}
+
+	thumbs := make(map[int][]byte, len(Sizes))
+	for _, size := range Sizes {
+		dst := image.NewRGBA(image.Rect(0, 0, size, size))
+		draw.CatmullRom.Scale(dst, dst.Bounds(), oriented, oriented.Bounds(), draw.Src, nil)
+
+		var buf bytes.Buffer
+		if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+		thumbs[size] = buf.Bytes()
+	}
+	return thumbs, nil
+}
+
+// cropSquare 居中裁剪为正方形
+func cropSquare(img image.Image) image.Image {
+	b := img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	x0 := b.Min.X + (b.Dx()-side)/2
+	y0 := b.Min.Y + (b.Dy()-side)/2
+	rect := image.Rect(x0, y0, x0+side, y0+side)
+	if sub, ok := img.(interface {
+		SubImage(image.Rectangle) image.Image
+	}); ok {
+		return sub.SubImage(rect)
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, side, side))
+	draw.Draw(dst, dst.Bounds(), img, rect.Min, draw.Src)
+	return dst
+}
+
+// NewVersion 生成头像版本号
+// 每次上传使用新版本号，URL 随之变化，旧缓存自然失效
+func NewVersion() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ValidVersion 检查版本号格式
+func ValidVersion(v string) bool {
+	return versionPattern.MatchString(v)
+}
+
+// Dir 头像在对象存储中的目录
+func Dir(userID uint, version string) string {
+	return fmt.Sprintf("avatars/%d/%s", userID, version)
+}
+
+// Key 指定尺寸缩略图的对象 key
+func Key(dir string, size int) string {
+	return fmt.Sprintf("%s/%d.jpg", dir, size)
+}
+
+// URL 指定尺寸缩略图的访问地址
+func URL(userID uint, version string, size int) string {
+	return fmt.Sprintf("/api/avatars/%d/%s/%d.jpg", userID, version, size)
+}
+
+// Save 将全部缩略图上传到 dir 目录
+func Save(ctx context.Context, store storage.Storage, dir string, thumbs map[int][]byte) error {
+	for size, data := range thumbs {
+		if err := store.Put(ctx, Key(dir, size), bytes.NewReader(data), int64(len(data)), "image/jpeg"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remove 删除 dir 目录下的全部缩略图
+func Remove(ctx context.Context, store storage.Storage, dir string) error {
+	var firstErr error
+	for _, size := range Sizes {
+		if err := store.Delete(ctx, Key(dir, size)); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,330 @@
+package cloze
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// maxBlanks 每句最多挖空数
+	maxBlanks = 8
+	// minDistractors / maxDistractors 干扰项数量范围
+	minDistractors = 2
+	maxDistractors = 4
+	// keywordBoost 人工标注关键词的权重倍数
+	keywordBoost = 3.0
+	// knownBoost 用户已学过的单词的权重倍数
+	// L2 练的是把认识的词从语流中听出来，优先挖用户见过的词
+	knownBoost = 2.0
+	// properNounWeight 句中大写开头的专有名词权重
+	properNounWeight = 0.3
+	// neighborPenalty 已挖空单词相邻单词的权重倍数，避免连续挖空
+	neighborPenalty = 0.3
+)
+
+// levelRatios 各等级的目标挖空比例（占句子单词数）
+var levelRatios = map[string]float64{
+	"A1": 0.15,
+	"A2": 0.2,
+	"B1": 0.25,
+	"B2": 0.3,
+	"C1": 0.35,
+	"C2": 0.4,
+}
+
+// Ratio 返回等级对应的挖空比例，未知等级按 B1 处理
+func Ratio(level string) float64 {
+	if r, ok := levelRatios[strings.ToUpper(level)]; ok {
+		return r
+	}
+	return levelRatios["B1"]
+}
+
+// Options 挖空参数
+type Options struct {
+	Level    string          // 用户等级 (A1-C2)，决定挖空比例
+	Keywords []string        // 人工标注的关键词，优先挖空
+	Known    map[string]bool // 用户已学过的单词（小写原形）
+	Pool     []string        // 干扰项候选，通常取自同一材料的其他句子
+	Seed     uint64          // 随机种子，相同种子产生相同结果
+}
+
+// Part 挖空后句子的一段，Text 与 Blank 二选一
+type Part struct {
+	Text  string `json:"text,omitempty"`
+	Blank *int   `json:"blank,omitempty"` // 空格编号，对应 Blanks 下标
+}
+
+// Blank 一个空格
+type Blank struct {
+	Index  int    `json:"index"`
+	Answer string `json:"answer"` // 原句中的写法
+	Start  int    `json:"start"`  // 在原句中的字节偏移
+	End    int    `json:"end"`
+}
+
+// Cloze 挖空结果
+type Cloze struct {
+	Parts  []Part   `json:"parts"`
+	Blanks []Blank  `json:"blanks"`
+	Chips  []string `json:"chips"` // 答案与干扰项打乱后的词块
+	Ratio  float64  `json:"ratio"` // 目标挖空比例
+}
+
+// Seed 由用户、句子和第几次练习生成随机种子
+// 同一次练习重复请求得到相同的挖空，换一次练习则重新抽取
+func Seed(userID, sentenceID uint, attempt int) uint64 {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "cloze:%d:%d:%d", userID, sentenceID, attempt)
+	return h.Sum64()
+}
+
+// Generate 为句子生成挖空和词块
+func Generate(text string, opts Options) *Cloze {
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x9e3779b97f4a7c15))
+	tokens := Tokenize(text)
+	result := &Cloze{Ratio: Ratio(opts.Level), Parts: []Part{}, Blanks: []Blank{}, Chips: []string{}}
+
+	keywords := make(map[string]bool, len(opts.Keywords))
+	for _, k := range opts.Keywords {
+		keywords[normalize(strings.TrimSpace(k))] = true
+	}
+
+	// 计算每个单词的挖空权重
+	weights := make([]float64, len(tokens))
+	starts := make(map[int]bool)
+	wordCount, firstWord := 0, true
+	for i, t := range tokens {
+		if !t.Word {
+			// 合并后的句子可能包含多个短句，句末标点后的单词也按句首处理
+			if strings.ContainsAny(t.Text, ".!?") {
+				firstWord = true
+			}
+			continue
+		}
+		wordCount++
+		w := normalize(t.Text)
+		sentenceStart := firstWord
+		starts[i] = sentenceStart
+		firstWord = false
+		if !IsContentWord(t.Text) && !keywords[w] {
+			continue
+		}
+		weight := posWeight(t.Text)
+		if !sentenceStart && w != "i" && isCapitalized(t.Text) {
+			weight = properNounWeight
+		}
+		if keywords[w] {
+			weight *= keywordBoost
+		}
+		for _, lemma := range Lemmas(t.Text) {
+			if opts.Known[lemma] {
+				weight *= knownBoost
+				break
+			}
+		}
+		weights[i] = weight
+	}
+
+	candidates := 0
+	for _, w := range weights {
+		if w > 0 {
+			candidates++
+		}
+	}
+	n := int(math.Round(result.Ratio * float64(wordCount)))
+	n = min(max(n, 1), candidates, maxBlanks)
+
+	// 按权重不放回抽样
+	chosen := make(map[int]bool, n)
+	for len(chosen) < n {
+		total := 0.0
+		for _, w := range weights {
+			total += w
+		}
+		if total <= 0 {
+			break
+		}
+		r := rng.Float64() * total
+		pick := -1
+		for i, w := range weights {
+			if w <= 0 {
+				continue
+			}
+			pick = i
+			if r < w {
+				break
+			}
+			r -= w
+		}
+		chosen[pick] = true
+		weights[pick] = 0
+		for _, j := range []int{prevWord(tokens, pick), nextWord(tokens, pick)} {
+			if j >= 0 {
+				weights[j] *= neighborPenalty
+			}
+		}
+	}
+
+	// 按原句顺序组装
+	var text0 strings.Builder
+	flush := func() {
+		if text0.Len() > 0 {
+			result.Parts = append(result.Parts, Part{Text: text0.String()})
+			text0.Reset()
+		}
+	}
+	for i, t := range tokens {
+		if !chosen[i] {
+			text0.WriteString(t.Text)
+			continue
+		}
+		flush()
+		idx := len(result.Blanks)
+		result.Blanks = append(result.Blanks, Blank{Index: idx, Answer: t.Text, Start: t.Start, End: t.End})
+		result.Parts = append(result.Parts, Part{Blank: &idx})
+	}
+	flush()
+
+	answers := make([]string, 0, len(result.Blanks))
+	for i, t := range tokens {
+		if chosen[i] {
+			answers = append(answers, chipText(t.Text, starts[i]))
+		}
+	}
+	result.Chips = chips(tokens, answers, opts.Pool, rng)
+	return result
+}
+
+// chips 生成答案和干扰项词块并打乱
+// 干扰项优先从候选池中选择与答案首字母相同、长度相近、词尾相同的词，不足时用答案的其他屈折形式补足
+func chips(tokens []Token, answers []string, pool []string, rng *rand.Rand) []string {
+	if len(answers) == 0 {
+		return []string{}
+	}
+	used := make(map[string]bool)
+	for _, t := range tokens {
+		if t.Word {
+			used[normalize(t.Text)] = true
+		}
+	}
+
+	out := make([]string, 0, len(answers)+maxDistractors)
+	out = append(out, answers...)
+
+	want := min(max(len(answers)/2+1, minDistractors), maxDistractors)
+	candidates := make([]string, 0, len(pool))
+	seen := make(map[string]bool, len(pool))
+	for _, p := range pool {
+		w := normalize(p)
+		if used[w] || seen[w] || !IsContentWord(w) {
+			continue
+		}
+		seen[w] = true
+		candidates = append(candidates, w)
+	}
+
+	distractors := 0
+	for i := 0; distractors < want && i < len(answers)*2; i++ {
+		answer := normalize(answers[i%len(answers)])
+		best, bestScore := -1, -1.0
+		for j, c := range candidates {
+			if used[c] {
+				continue
+			}
+			if score := similarity(answer, c) + rng.Float64(); score > bestScore {
+				best, bestScore = j, score
+			}
+		}
+		if best < 0 {
+			break
+		}
+		used[candidates[best]] = true
+		out = append(out, candidates[best])
+		distractors++
+	}
+	for _, a := range answers {
+		if distractors >= want {
+			break
+		}
+		if v := variant(a); v != "" && !used[v] {
+			used[v] = true
+			out = append(out, v)
+			distractors++
+		}
+	}
+
+	rng.Shuffle(len(out), func(i, j int) { out[i], out[j] = out[j], out[i] })
+	return out
+}
+
+// similarity 干扰项与答案的相似度，越像越容易混淆
+func similarity(answer, candidate string) float64 {
+	score := 0.0
+	if answer[0] == candidate[0] {
+		score += 2
+	}
+	if d := len(answer) - len(candidate); d >= -2 && d <= 2 {
+		score++
+	}
+	for _, suffix := range []string{"ing", "ed", "ly", "s"} {
+		if strings.HasSuffix(answer, suffix) && strings.HasSuffix(candidate, suffix) {
+			score++
+			break
+		}
+	}
+	return score
+}
+
+// chipText 词块显示形式：句首单词改为小写，避免提示位置；句中的专有名词和 I 保持原样
+func chipText(answer string, sentenceStart bool) string {
+	if !sentenceStart || answer == "I" || strings.HasPrefix(answer, "I'") {
+		return answer
+	}
+	r, size := utf8.DecodeRuneInString(answer)
+	return string(unicode.ToLower(r)) + answer[size:]
+}
+
+// isCapitalized 首字母是否大写
+func isCapitalized(word string) bool {
+	r, _ := utf8.DecodeRuneInString(word)
+	return unicode.IsUpper(r)
+}
+
+// prevWord 返回前一个单词的下标，没有时返回 -1
+func prevWord(tokens []Token, i int) int {
+	for j := i - 1; j >= 0; j-- {
+		if tokens[j].Word {
+			return j
+		}
+	}
+	return -1
+}
+
+// nextWord 返回后一个单词的下标，没有时返回 -1
+func nextWord(tokens []Token, i int) int {
+	for j := i + 1; j < len(tokens); j++ {
+		if tokens[j].Word {
+			return j
+		}
+	}
+	return -1
+}
+
+// ContentWords 提取文本中的实词（小写），用于构建干扰项候选池
+func ContentWords(text string) []string {
+	var out []string
+	for _, t := range Tokenize(text) {
+		if t.Word && IsContentWord(t.Text) && !isNumber(t.Text) {
+			out = append(out, normalize(t.Text))
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,162 @@
+package cloze
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+const longSentence = "Yesterday the engineers quickly repaired seventeen broken elevators while nervous tenants waited outside the building, complaining loudly about terrible management."
+
+// rebuild 用答案填回空格，应还原原句
+func rebuild(c *Cloze) string {
+	var sb strings.Builder
+	for _, p := range c.Parts {
+		if p.Blank != nil {
+			sb.WriteString(c.Blanks[*p.Blank].Answer)
+		} else {
+			sb.WriteString(p.Text)
+		}
+	}
+	return sb.String()
+}
+
+func TestGenerateDeterministic(t *testing.T) {
+	opts := Options{Level: "B2", Pool: ContentWords("The plumbers slowly replaced twelve cracked windows."), Seed: Seed(1, 42, 0)}
+	a, b := Generate(longSentence, opts), Generate(longSentence, opts)
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("same seed gave different cloze:\n%+v\n%+v", a, b)
+	}
+
+	differs := false
+	for attempt := 1; attempt <= 5 && !differs; attempt++ {
+		opts.Seed = Seed(1, 42, attempt)
+		differs = !reflect.DeepEqual(a, Generate(longSentence, opts))
+	}
+	if !differs {
+		t.Error("different attempts always gave the same cloze")
+	}
+}
+
+func TestGenerateBlanks(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		opts     Options
+		blanks   int
+		answers  []string // 非空时要求挖空的答案
+		minChips int
+	}{
+		{"A1 ratio", longSentence, Options{Level: "A1"}, 3, nil, 5},
+		{"C2 ratio capped", longSentence, Options{Level: "c2"}, maxBlanks, nil, 12},
+		{"unknown level uses B1", longSentence, Options{Level: "X9"}, 5, nil, 7},
+		{"at least one blank", "I want to go home.", Options{Level: "A1"}, 1, []string{"want", "home"}, 2},
+		{"only function words", "It is what it is.", Options{Level: "C2"}, 0, nil, 0},
+		{"keyword may be a function word", "It is about time.", Options{Level: "C2", Keywords: []string{" About "}}, 2, []string{"about", "time"}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Generate(tt.text, tt.opts)
+			if len(c.Blanks) != tt.blanks {
+				t.Fatalf("blanks = %d, want %d (%+v)", len(c.Blanks), tt.blanks, c.Blanks)
+			}
+			if got := rebuild(c); got != tt.text {
+				t.Errorf("rebuilt = %q, want %q", got, tt.text)
+			}
+			for i, b := range c.Blanks {
+				if b.Index != i || tt.text[b.Start:b.End] != b.Answer {
+					t.Errorf("blank %d = %+v", i, b)
+				}
+				if !IsContentWord(b.Answer) && !slices.Contains(tt.answers, b.Answer) {
+					t.Errorf("function word %q blanked", b.Answer)
+				}
+				if tt.answers != nil && !slices.Contains(tt.answers, b.Answer) {
+					t.Errorf("blank %q not in %v", b.Answer, tt.answers)
+				}
+				if !slices.Contains(c.Chips, strings.ToLower(b.Answer)) && !slices.Contains(c.Chips, b.Answer) {
+					t.Errorf("chips %v missing answer %q", c.Chips, b.Answer)
+				}
+			}
+			if len(c.Chips) < tt.minChips || len(c.Chips) > len(c.Blanks)+maxDistractors {
+				t.Errorf("chips = %v", c.Chips)
+			}
+		})
+	}
+}
+
+func TestGenerateChips(t *testing.T) {
+	// 句首单词在词块中改为小写，干扰项不与句中单词重复
+	c := Generate("Yesterday.", Options{Level: "A1", Pool: []string{"yesterday", "tomorrow", "the", "tomorrow", "someday"}})
+	want := []string{"someday", "tomorrow", "yesterday"}
+	got := slices.Clone(c.Chips)
+	slices.Sort(got)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("chips = %v, want %v", got, want)
+	}
+
+	// 候选池为空时用答案的屈折形式补足
+	c = Generate("Cooking", Options{Level: "A1"})
+	got = slices.Clone(c.Chips)
+	slices.Sort(got)
+	if want := []string{"cooked", "cooking"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("chips = %v, want %v", got, want)
+	}
+}
+
+func TestRatio(t *testing.T) {
+	tests := []struct {
+		level string
+		want  float64
+	}{
+		{"A1", 0.15},
+		{"b2", 0.3},
+		{"C2", 0.4},
+		{"", 0.25},
+	}
+	for _, tt := range tests {
+		if got := Ratio(tt.level); got != tt.want {
+			t.Errorf("Ratio(%q) = %v, want %v", tt.level, got, tt.want)
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	text := "Don't stop—it’s well-known, 'ok'?"
+	var words []string
+	var sb strings.Builder
+	for _, tok := range Tokenize(text) {
+		sb.WriteString(tok.Text)
+		if tok.Word {
+			words = append(words, tok.Text)
+		}
+	}
+	if sb.String() != text {
+		t.Errorf("tokens join to %q", sb.String())
+	}
+	if want := []string{"Don't", "stop", "it’s", "well-known", "ok"}; !reflect.DeepEqual(words, want) {
+		t.Errorf("words = %q, want %q", words, want)
+	}
+}
+
+func TestLemmas(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"Stories", "story"},
+		{"watches", "watch"},
+		{"cats", "cat"},
+		{"tried", "try"},
+		{"stopped", "stop"},
+		{"liked", "like"},
+		{"running", "run"},
+		{"making", "make"},
+		{"glass", "glass"},
+	}
+	for _, tt := range tests {
+		if got := Lemmas(tt.word); !slices.Contains(got, tt.want) {
+			t.Errorf("Lemmas(%q) = %v, want to contain %q", tt.word, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package cloze
+
+import (
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Token 句子中的一个片段
+type Token struct {
+	Text  string
+	Word  bool // 单词或数字；否则为空白和标点
+	Start int  // 在原句中的字节偏移
+	End   int
+}
+
+// Tokenize 将句子切分为单词和非单词片段，拼接后与原句一致
+// 单词内部的撇号和连字符保留，如 don't、well-known
+func Tokenize(text string) []Token {
+	var tokens []Token
+	start := 0
+	inWord := false
+	for i, r := range text {
+		w := isWordRune(r) || inWord && isJoiner(r) && nextIsWordRune(text, i+utf8.RuneLen(r))
+		if i > 0 && w != inWord {
+			tokens = append(tokens, Token{Text: text[start:i], Word: inWord, Start: start, End: i})
+			start = i
+		}
+		inWord = w
+	}
+	if start < len(text) {
+		tokens = append(tokens, Token{Text: text[start:], Word: inWord, Start: start, End: len(text)})
+	}
+	return tokens
+}
+
+// isWordRune 字母或数字
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// isJoiner 可出现在单词内部的连接符
+func isJoiner(r rune) bool {
+	return r == '\'' || r == '’' || r == '-'
+}
+
+// nextIsWordRune 判断偏移 i 处是否为字母或数字
+func nextIsWordRune(s string, i int) bool {
+	if i >= len(s) {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(s[i:])
+	return isWordRune(r)
+}
+
+// functionWords 功能词，不作为挖空候选
+// 包括冠词、代词、介词、连词、助动词、情态动词和常见限定词
+var functionWords = toSet(`a an the this that these those
+i me my mine you your yours he him his she her hers it its we us our ours they them their theirs
+myself yourself himself herself itself ourselves themselves
+what which who whom whose where when why how
+am is are was were be been being do does did done have has had having
+will would shall should can could may might must
+and or but nor so yet if than then because though although while as
+of in on at to for from by with about into onto over under up down out off
+through during before after above below between among against without within upon
+not no yes all any some each every both either neither such own same other
+just also too very only even still much many more most less least
+there here now oh ah okay ok yeah hey um uh
+i'm you're he's she's it's we're they're i've you've we've they've i'd you'd he'd she'd we'd they'd
+i'll you'll he'll she'll we'll they'll isn't aren't wasn't weren't don't doesn't didn't
+haven't hasn't hadn't won't wouldn't can't couldn't shouldn't mustn't let's that's there's what's`)
+
+// toSet 将空白分隔的单词转为集合
+func toSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+// normalize 统一为小写并把弯撇号替换为直撇号
+func normalize(word string) string {
+	return strings.ToLower(strings.ReplaceAll(word, "’", "'"))
+}
+
+// IsContentWord 判断是否为实词（名词、动词、形容词、副词或数字）
+func IsContentWord(word string) bool {
+	w := normalize(word)
+	if functionWords[w] {
+		return false
+	}
+	if isNumber(w) {
+		return true
+	}
+	return utf8.RuneCountInString(w) >= 3
+}
+
+// isNumber 判断是否为纯数字
+func isNumber(w string) bool {
+	for _, r := range w {
+		if !unicode.IsDigit(r) {
+			return false
+		}
+	}
+	return w != ""
+}
+
+// posWeight 按词形猜测词性并给出挖空权重
+// 名词、动词、形容词的典型后缀权重较高，-ly 副词次之，无法判断的实词取基础权重
+func posWeight(word string) float64 {
+	w := normalize(word)
+	if isNumber(w) {
+		return 1.2 // 数字是听力中容易听错的信息点
+	}
+	for _, suffix := range []string{"tion", "sion", "ment", "ness", "ity", "ance", "ence", "ship", "ful", "ous", "ive", "able", "ible", "ical", "ing", "ed", "ise", "ize"} {
+		if strings.HasSuffix(w, suffix) && len(w) > len(suffix)+2 {
+			return 1.3
+		}
+	}
+	if strings.HasSuffix(w, "ly") {
+		return 0.6
+	}
+	return 1.0
+}
+
+// Lemmas 返回单词及其可能的原形，用于匹配词库
+// 只处理规则变化：复数、第三人称单数、过去式和现在分词
+func Lemmas(word string) []string {
+	w := normalize(word)
+	forms := []string{w}
+	add := func(s string) {
+		if len(s) >= 2 && !slices.Contains(forms, s) {
+			forms = append(forms, s)
+		}
+	}
+	switch {
+	case strings.HasSuffix(w, "ies"):
+		add(w[:len(w)-3] + "y")
+	case strings.HasSuffix(w, "es"):
+		add(w[:len(w)-2])
+		add(w[:len(w)-1])
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss"):
+		add(w[:len(w)-1])
+	case strings.HasSuffix(w, "ied"):
+		add(w[:len(w)-3] + "y")
+	case strings.HasSuffix(w, "ed"):
+		add(w[:len(w)-2])
+		add(w[:len(w)-1])
+		add(undouble(w[:len(w)-2]))
+	case strings.HasSuffix(w, "ing"):
+		add(w[:len(w)-3])
+		add(w[:len(w)-3] + "e")
+		add(undouble(w[:len(w)-3]))
+	}
+	return forms
+}
+
+// undouble 去掉重复的末尾辅音，如 stopp → stop
+func undouble(s string) string {
+	n := len(s)
+	if n >= 3 && s[n-1] == s[n-2] && !strings.ContainsRune("aeiou", rune(s[n-1])) {
+		return s[:n-1]
+	}
+	return s
+}
+
+// variant 生成单词的另一种屈折形式，作为干扰项的兜底
+// 听力中词尾 -ed/-s/-ing 常被弱读，是常见的听错点
+func variant(word string) string {
+	w := normalize(word)
+	switch {
+	case strings.HasSuffix(w, "ing") && len(w) > 5:
+		return w[:len(w)-3] + "ed"
+	case strings.HasSuffix(w, "ed") && len(w) > 4:
+		return w[:len(w)-2] + "ing"
+	case strings.HasSuffix(w, "s") && !strings.HasSuffix(w, "ss") && len(w) > 3:
+		return w[:len(w)-1]
+	case isNumber(w):
+		return ""
+	default:
+		return w + "s"
+	}
+}
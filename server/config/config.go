@@ -0,0 +1,219 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+)
+
+// Config 服务端配置
+// 从JSON配置文件加载，部分字段可被环境变量覆盖
+type Config struct {
+	JWT        JWTConfig        `json:"jwt"`
+	Study      StudyConfig      `json:"study"`
+	Speaking   SpeakingConfig   `json:"speaking"`
+	Storage    StorageConfig    `json:"storage"`
+	Recordings RecordingsConfig `json:"recordings"`
+	Listening  ListeningConfig  `json:"listening"`
+	Audio      AudioConfig      `json:"audio"`
+	Dialogue   DialogueConfig   `json:"dialogue"`
+}
+
+// DialogueConfig 实战对话配置
+type DialogueConfig struct {
+	Model    string       `json:"model"`     // 对话引擎：scripted 离线剧本（默认）/ openai 兼容 OpenAI 接口的大模型
+	MaxTurns int          `json:"max_turns"` // 单次对话学习者最多发言次数，达到后自动结束
+	OpenAI   OpenAIConfig `json:"openai"`
+}
+
+// OpenAIConfig 兼容 OpenAI Chat Completions 接口的大模型服务
+type OpenAIConfig struct {
+	BaseURL        string  `json:"base_url"`        // 接口地址，如 https://api.openai.com/v1
+	APIKey         string  `json:"api_key"`         // 访问密钥，可由 KAIKOUYI_LLM_API_KEY 覆盖
+	Model          string  `json:"model"`           // 模型名称
+	Temperature    float64 `json:"temperature"`     // 采样温度
+	TimeoutSeconds int     `json:"timeout_seconds"` // 单次请求超时
+}
+
+// AudioConfig 托管音频配置
+type AudioConfig struct {
+	MaxSizeMB           int    `json:"max_size_mb"`            // 上传音频大小上限
+	CacheDir            string `json:"cache_dir"`              // 变速音频缓存目录
+	CacheMaxSizeMB      int    `json:"cache_max_size_mb"`      // 缓存目录大小上限，超出时删除最久未使用的版本，0 表示不限制
+	SigningKey          string `json:"signing_key"`            // 签名地址的HMAC密钥，可由 KAIKOUYI_AUDIO_SIGNING_KEY 覆盖
+	SignedURLTTLMinutes int    `json:"signed_url_ttl_minutes"` // 签名地址有效期
+	FFmpegPath          string `json:"ffmpeg_path"`            // 生成波形时解码 MP3/AAC 等格式，为空时仅支持 WAV
+}
+
+// ListeningConfig 听力训练配置
+type ListeningConfig struct {
+	L3UnlockAccuracy float64 `json:"l3_unlock_accuracy"` // 解锁 L3 所需的 L2 正确率 (0-1)
+	StagePassScore   int     `json:"stage_pass_score"`   // 四阶训练每个阶段的通过分数 (0-100)
+}
+
+// StorageConfig 文件存储配置
+type StorageConfig struct {
+	Backend  string   `json:"backend"`   // local / s3
+	LocalDir string   `json:"local_dir"` // 本地存储根目录
+	S3       S3Config `json:"s3"`
+}
+
+// S3Config S3 兼容对象存储配置
+type S3Config struct {
+	Endpoint  string `json:"endpoint"`   // 服务地址，如 http://127.0.0.1:9000
+	Region    string `json:"region"`     // 区域，默认 us-east-1
+	Bucket    string `json:"bucket"`     // 存储桶
+	AccessKey string `json:"access_key"` // 访问密钥，可由 KAIKOUYI_S3_ACCESS_KEY 覆盖
+	SecretKey string `json:"secret_key"` // 私有密钥，可由 KAIKOUYI_S3_SECRET_KEY 覆盖
+	PathStyle bool   `json:"path_style"` // 使用路径风格URL（MinIO 需开启）
+}
+
+// RecordingsConfig 用户录音配置
+type RecordingsConfig struct {
+	MaxSizeMB          int `json:"max_size_mb"`          // 单个录音大小上限
+	MaxDurationSeconds int `json:"max_duration_seconds"` // 单个录音时长上限
+	RetentionDays      int `json:"retention_days"`       // 保留天数，0 表示永久保留
+}
+
+// SpeakingConfig 口语评分配置
+type SpeakingConfig struct {
+	Scorer             string `json:"scorer"`               // 发音评分引擎，内置 local 离线引擎
+	ShadowingPassScore int    `json:"shadowing_pass_score"` // 影子跟读单句达标分数 (0-100)
+}
+
+// StudyConfig 学习日与连续学习配置
+type StudyConfig struct {
+	DefaultTimezone       string `json:"default_timezone"`         // 用户未设置时区时使用的IANA时区
+	DayRolloverHour       int    `json:"day_rollover_hour"`        // 学习日切换时刻（0-23点），用户可单独设置
+	StreakFreezesPerMonth int    `json:"streak_freezes_per_month"` // 开启保护的用户每月可跳过的天数
+}
+
+// JWTConfig JWT签名配置
+type JWTConfig struct {
+	ActiveKID string   `json:"active_kid"` // 当前用于签名的密钥ID
+	Keys      []JWTKey `json:"keys"`       // 所有有效密钥，轮换期间旧密钥仅用于验证
+}
+
+// JWTKey 单个签名密钥
+// HS256 使用 Secret；RS256/EdDSA 使用 PEM 格式的私钥或公钥文件
+type JWTKey struct {
+	KID            string `json:"kid"`              // 密钥ID，写入JWT头部
+	Algorithm      string `json:"alg"`              // HS256 / RS256 / EdDSA
+	Secret         string `json:"secret"`           // HS256 共享密钥
+	PrivateKeyFile string `json:"private_key_file"` // 私钥文件（PKCS#1/PKCS#8）
+	PublicKeyFile  string `json:"public_key_file"`  // 公钥文件，仅提供时该密钥只用于验证
+}
+
+// cfg 全局配置实例
+var cfg = defaultConfig()
+
+// defaultConfig 返回默认配置
+func defaultConfig() *Config {
+	return &Config{
+		Study: StudyConfig{
+			DefaultTimezone:       "Asia/Shanghai",
+			DayRolloverHour:       4,
+			StreakFreezesPerMonth: 2,
+		},
+		Speaking: SpeakingConfig{
+			Scorer:             "local",
+			ShadowingPassScore: 80,
+		},
+		Storage: StorageConfig{
+			Backend:  "local",
+			LocalDir: "./data/storage",
+		},
+		Recordings: RecordingsConfig{
+			MaxSizeMB:          10,
+			MaxDurationSeconds: 120,
+			RetentionDays:      30,
+		},
+		Listening: ListeningConfig{
+			L3UnlockAccuracy: 0.8,
+			StagePassScore:   80,
+		},
+		Audio: AudioConfig{
+			MaxSizeMB:           100,
+			CacheDir:            "./data/audio_cache",
+			CacheMaxSizeMB:      1024,
+			SignedURLTTLMinutes: 360,
+		},
+		Dialogue: DialogueConfig{
+			Model:    "scripted",
+			MaxTurns: 20,
+			OpenAI: OpenAIConfig{
+				Temperature:    0.7,
+				TimeoutSeconds: 30,
+			},
+		},
+	}
+}
+
+// Load 加载配置文件
+// 文件不存在时使用默认配置，随后应用环境变量覆盖
+func Load(path string) (*Config, error) {
+	c := defaultConfig()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(data, c); err != nil {
+				return nil, fmt.Errorf("parse config %s: %w", path, err)
+			}
+			log.Printf("Config loaded from %s", path)
+		case os.IsNotExist(err):
+			log.Printf("Config file %s not found, using defaults", path)
+		default:
+			return nil, fmt.Errorf("read config %s: %w", path, err)
+		}
+	}
+
+	applyEnv(c)
+	if err := validate(c); err != nil {
+		return nil, err
+	}
+	cfg = c
+	return c, nil
+}
+
+// Get 获取当前配置
+func Get() *Config {
+	return cfg
+}
+
+// applyEnv 应用环境变量覆盖
+func applyEnv(c *Config) {
+	// KAIKOUYI_JWT_SECRET 提供单个HS256密钥，便于简单部署
+	if secret := os.Getenv("KAIKOUYI_JWT_SECRET"); secret != "" && len(c.JWT.Keys) == 0 {
+		c.JWT.Keys = []JWTKey{{KID: "default", Algorithm: "HS256", Secret: secret}}
+		c.JWT.ActiveKID = "default"
+	}
+	if kid := os.Getenv("KAIKOUYI_JWT_ACTIVE_KID"); kid != "" {
+		c.JWT.ActiveKID = kid
+	}
+	if key := os.Getenv("KAIKOUYI_S3_ACCESS_KEY"); key != "" {
+		c.Storage.S3.AccessKey = key
+	}
+	if key := os.Getenv("KAIKOUYI_S3_SECRET_KEY"); key != "" {
+		c.Storage.S3.SecretKey = key
+	}
+	if key := os.Getenv("KAIKOUYI_AUDIO_SIGNING_KEY"); key != "" {
+		c.Audio.SigningKey = key
+	}
+	if p := os.Getenv("KAIKOUYI_FFMPEG_PATH"); p != "" {
+		c.Audio.FFmpegPath = p
+	}
+	if key := os.Getenv("KAIKOUYI_LLM_API_KEY"); key != "" {
+		c.Dialogue.OpenAI.APIKey = key
+	}
+}
+
+// validate 检查配置取值范围
+func validate(c *Config) error {
+	if a := c.Listening.L3UnlockAccuracy; a < 0 || a > 1 {
+		return fmt.Errorf("listening.l3_unlock_accuracy must be between 0 and 1, got %v", a)
+	}
+	return nil
+}
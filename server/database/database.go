@@ -33,14 +33,63 @@ func InitDB() {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
-	err = DB.AutoMigrate(&models.User{})
+	err = DB.AutoMigrate(
+		&models.User{},
+		&models.RefreshToken{},
+		&models.Word{},
+		&models.UserWordProgress{},
+		&models.Wordbook{},
+		&models.WordbookWord{},
+		&models.UserWordbook{},
+		&models.StudyEvent{},
+		&models.AssessmentSession{},
+		&models.VocabTestItem{},
+		&models.ListeningItem{},
+		&models.ListeningTestItem{},
+		&models.ListeningProfile{},
+		&models.Recording{},
+		&models.ListeningMaterial{},
+		&models.ListeningSentence{},
+		&models.ListeningMaterialTag{},
+		&models.ListeningProgress{},
+		&models.ListeningSentenceProgress{},
+		&models.DrillItem{},
+		&models.DrillSession{},
+		&models.DrillSessionItem{},
+		&models.UserPhenomenonStat{},
+		&models.AudioAsset{},
+		&models.SpeedStageProgress{},
+		&models.Waveform{},
+		&models.WaveformPeaks{},
+		&models.ShadowingMaterial{},
+		&models.ShadowingAttempt{},
+		&models.ShadowingProgress{},
+		&models.DialogueScenario{},
+		&models.DialogueSession{},
+		&models.DialogueTurn{},
+		&models.DialogueHint{},
+	)
 	if err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
 
+	// 删除被新索引取代的旧索引：单词的唯一索引改为只约束未删除的单词，个人词库的所有者改为唯一索引
+	dropIndex(&models.Word{}, "idx_words_headword")
+	dropIndex(&models.Wordbook{}, "idx_wordbooks_owner_id")
+
 	log.Println("Database initialized successfully")
 }
 
+// dropIndex 删除旧版本建立的索引，不存在时忽略
+func dropIndex(model interface{}, name string) {
+	if !DB.Migrator().HasIndex(model, name) {
+		return
+	}
+	if err := DB.Migrator().DropIndex(model, name); err != nil {
+		log.Fatalf("Failed to drop index %s: %v", name, err)
+	}
+}
+
 // CloseDB 关闭数据库连接
 func CloseDB() {
 	sqlDB, err := DB.DB()
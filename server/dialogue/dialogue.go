@@ -0,0 +1,160 @@
+package dialogue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"server/config"
+	"server/models"
+	"server/utils"
+)
+
+// 对话错误
+var (
+	ErrNoScript        = errors.New("场景没有剧本，无法离线对话")
+	ErrEmptyReply      = errors.New("对话引擎没有返回内容")
+	ErrSessionFinished = errors.New("对话已结束")
+	ErrTurnConflict    = errors.New("上一轮对话尚未完成，请稍后再试")
+)
+
+// Message 对话历史中的一条发言
+type Message struct {
+	Role string // assistant / user
+	Text string
+}
+
+// Request 生成回复的请求
+type Request struct {
+	Scenario *models.DialogueScenario
+	History  []Message // 按时间顺序，最后一条为学习者刚说的话；为空时生成开场白
+}
+
+// Reply 对话引擎的回复
+type Reply struct {
+	Text        string
+	Translation string // 剧本台词提供中文翻译，大模型回复为空
+	Done        bool   // 场景目标已完成，对话结束
+	Engine      string // 实际生成回复的引擎
+}
+
+// DialogueModel 对话引擎
+// 内置离线剧本引擎 scripted；兼容 OpenAI 接口的大模型服务实现为 openai，调用失败时回退到剧本
+type DialogueModel interface {
+	// Name 引擎名称，对应配置中的 dialogue.model
+	Name() string
+	// Reply 根据场景和对话历史生成 AI 角色的下一句
+	Reply(ctx context.Context, req *Request) (*Reply, error)
+	// Hint 学习者不知道怎么接话时，给出由易到难的建议回答
+	Hint(ctx context.Context, req *HintRequest) (*Hints, error)
+}
+
+// model 全局对话引擎
+var model DialogueModel
+
+// Init 根据配置初始化对话引擎
+func Init(cfg config.DialogueConfig) error {
+	m, err := New(cfg)
+	if err != nil {
+		return err
+	}
+	model = m
+	return nil
+}
+
+// Get 获取全局对话引擎
+func Get() DialogueModel {
+	return model
+}
+
+// New 创建对话引擎
+func New(cfg config.DialogueConfig) (DialogueModel, error) {
+	switch cfg.Model {
+	case "", ScriptedName:
+		return Scripted{}, nil
+	case OpenAIName:
+		m, err := NewOpenAI(cfg.OpenAI)
+		if err != nil {
+			return nil, err
+		}
+		return &fallback{primary: m, secondary: Scripted{}}, nil
+	}
+	return nil, fmt.Errorf("unknown dialogue model %q", cfg.Model)
+}
+
+// fallback 主引擎失败时使用备用引擎，保证对话不中断
+type fallback struct {
+	primary   DialogueModel
+	secondary DialogueModel
+}
+
+// Name 引擎名称
+func (f *fallback) Name() string {
+	return f.primary.Name()
+}
+
+// Reply 生成回复
+func (f *fallback) Reply(ctx context.Context, req *Request) (*Reply, error) {
+	reply, err := f.primary.Reply(ctx, req)
+	if err == nil {
+		return reply, nil
+	}
+	utils.Warn("Dialogue - %s failed, falling back to %s: %v", f.primary.Name(), f.secondary.Name(), err)
+	return f.secondary.Reply(ctx, req)
+}
+
+// Hint 生成建议回答
+func (f *fallback) Hint(ctx context.Context, req *HintRequest) (*Hints, error) {
+	hints, err := f.primary.Hint(ctx, req)
+	if err == nil {
+		return hints, nil
+	}
+	utils.Warn("Dialogue - %s hint failed, falling back to %s: %v", f.primary.Name(), f.secondary.Name(), err)
+	return f.secondary.Hint(ctx, req)
+}
+
+// systemPrompt 大模型的角色设定
+// 剧本作为参考流程提供，模型可以根据学习者的实际回答自由发挥
+func systemPrompt(s *models.DialogueScenario) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "You are role-playing as %s in the scenario \"%s\" with an English learner who plays %s.\n",
+		orDefault(s.AIRole, "a conversation partner"), s.Title, orDefault(s.UserRole, "themself"))
+	if s.Description != "" {
+		fmt.Fprintf(&b, "Scenario: %s\n", s.Description)
+	}
+	if s.Goal != "" {
+		fmt.Fprintf(&b, "The learner's goal: %s\n", s.Goal)
+	}
+	if s.Difficulty != "" {
+		fmt.Fprintf(&b, "The learner's level is CEFR %s; use vocabulary and sentence length suitable for that level.\n", s.Difficulty)
+	}
+	b.WriteString("Stay in character and in the scenario. Reply with one to three short, natural spoken English sentences, " +
+		"react to what the learner actually said, and ask a follow-up question when it keeps the conversation going. " +
+		"Do not correct the learner's grammar or explain anything. " +
+		"When the goal has been reached and the conversation has come to a natural end, append " + endMarker + " to your final reply.\n")
+	if s.Prompt != "" {
+		b.WriteString(s.Prompt + "\n")
+	}
+	if len(s.Lines) > 0 {
+		b.WriteString("Reference flow (adapt freely):\n")
+		for _, l := range s.Lines {
+			role := orDefault(s.AIRole, "You")
+			if l.Role == models.DialogueRoleUser {
+				role = orDefault(s.UserRole, "Learner")
+			}
+			fmt.Fprintf(&b, "%s: %s\n", role, l.Text)
+		}
+	}
+	return b.String()
+}
+
+// endMarker 大模型用于表示对话结束的标记
+const endMarker = "[END]"
+
+func orDefault(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
@@ -0,0 +1,150 @@
+package dialogue
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"server/models"
+)
+
+// maxSuggestions 每次求助最多给出的建议数
+const maxSuggestions = 3
+
+// HintRequest 生成建议回答的请求
+type HintRequest struct {
+	Scenario *models.DialogueScenario
+	History  []Message // 按时间顺序，最后一条为 AI 刚说的话
+	Level    string    // 学习者的词汇等级 (A1-C2)，为空时按 A1 处理
+}
+
+// Hints 对话引擎给出的建议回答
+type Hints struct {
+	Suggestions []models.DialogueSuggestion
+	Engine      string // 实际生成建议的引擎
+}
+
+// genericHints 剧本无法给出参考回答时的通用求助说法
+var genericHints = []models.DialogueSuggestion{
+	{Level: models.DialogueHintSimple, Text: "Sorry, again please?", Translation: "抱歉，请再说一遍？", KeyPhrases: []string{"again please"}},
+	{Level: models.DialogueHintNatural, Text: "Sorry, could you say that again more slowly?", Translation: "抱歉，能再慢一点说一遍吗？", KeyPhrases: []string{"could you say that again", "more slowly"}},
+	{Level: models.DialogueHintAdvanced, Text: "Sorry, I'm not sure I follow. Could you put that another way?", Translation: "抱歉，我没太明白。能换个说法吗？", KeyPhrases: []string{"I'm not sure I follow", "put that another way"}},
+}
+
+// RecommendedHintLevel 按词汇等级推荐建议回答的难度
+// A1-A2 推荐简单说法，B1-B2 推荐自然说法，C1 及以上推荐进阶说法
+func RecommendedHintLevel(vocabularyLevel string) string {
+	switch vocabularyLevel {
+	case "B1", "B2":
+		return models.DialogueHintNatural
+	case "C1", "C2":
+		return models.DialogueHintAdvanced
+	}
+	return models.DialogueHintSimple
+}
+
+// Hint 为学习者当前这一轮生成建议回答并记录求助
+// 同一轮重复求助直接返回已有建议，不再调用引擎，也不重复计数
+func Hint(ctx context.Context, db *gorm.DB, m DialogueModel, session *models.DialogueSession, scenario *models.DialogueScenario,
+	vocabularyLevel string) (*models.DialogueHint, error) {
+	if session.Status != models.DialogueStatusActive {
+		return nil, ErrSessionFinished
+	}
+
+	var turns []models.DialogueTurn
+	if err := db.Where("session_id = ?", session.ID).Order("seq ASC").Find(&turns).Error; err != nil {
+		return nil, err
+	}
+	seq := len(turns) + 1
+	if hint, err := findHint(db, session.ID, seq); err == nil {
+		return hint, nil
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	history := make([]Message, 0, len(turns))
+	for _, t := range turns {
+		history = append(history, Message{Role: t.Role, Text: t.Text})
+	}
+	hints, err := m.Hint(ctx, &HintRequest{Scenario: scenario, History: history, Level: vocabularyLevel})
+	if err != nil {
+		return nil, err
+	}
+	suggestions := cleanSuggestions(hints.Suggestions)
+	if len(suggestions) == 0 {
+		return nil, ErrEmptyReply
+	}
+
+	hint := &models.DialogueHint{
+		SessionID:   session.ID,
+		Seq:         seq,
+		UserID:      session.UserID,
+		Recommended: RecommendedHintLevel(vocabularyLevel),
+		Engine:      hints.Engine,
+		Suggestions: suggestions,
+	}
+	created := false
+	err = db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(hint)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		created = true
+		return tx.Model(&models.DialogueSession{}).Where("id = ?", session.ID).
+			Update("hints_used", gorm.Expr("hints_used + 1")).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !created {
+		// 并发求助时另一请求已写入
+		return findHint(db, session.ID, seq)
+	}
+	session.HintsUsed++
+	return hint, nil
+}
+
+// findHint 查询某一轮的求助记录
+func findHint(db *gorm.DB, sessionID uint, seq int) (*models.DialogueHint, error) {
+	var hint models.DialogueHint
+	if err := db.Where("session_id = ? AND seq = ?", sessionID, seq).First(&hint).Error; err != nil {
+		return nil, err
+	}
+	return &hint, nil
+}
+
+// cleanSuggestions 去除空白和重复的建议，每个难度只保留一条，按由易到难排列
+func cleanSuggestions(in []models.DialogueSuggestion) []models.DialogueSuggestion {
+	byLevel := map[string]models.DialogueSuggestion{}
+	seen := map[string]bool{}
+	for _, s := range in {
+		s.Text = strings.TrimSpace(s.Text)
+		s.Translation = strings.TrimSpace(s.Translation)
+		key := normalize(s.Text)
+		if key == "" || seen[key] || !models.IsValidDialogueHintLevel(s.Level) {
+			continue
+		}
+		if _, ok := byLevel[s.Level]; ok {
+			continue
+		}
+		phrases := make([]string, 0, len(s.KeyPhrases))
+		for _, p := range s.KeyPhrases {
+			if p = strings.TrimSpace(p); p != "" {
+				phrases = append(phrases, p)
+			}
+		}
+		s.KeyPhrases = phrases
+		seen[key] = true
+		byLevel[s.Level] = s
+	}
+
+	out := make([]models.DialogueSuggestion, 0, maxSuggestions)
+	for _, level := range models.DialogueHintLevels {
+		if s, ok := byLevel[level]; ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
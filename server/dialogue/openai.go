@@ -0,0 +1,191 @@
+package dialogue
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"server/config"
+	"server/models"
+)
+
+// OpenAIName 兼容 OpenAI 接口的大模型引擎名称
+const OpenAIName = "openai"
+
+const (
+	// maxReplyTokens 单次回复的最大 token 数，对话回复应简短
+	maxReplyTokens = 200
+	// maxHintTokens 建议回答的最大 token 数，包含三条建议及翻译
+	maxHintTokens = 600
+	// maxErrorBody 错误信息中保留的响应体长度
+	maxErrorBody = 500
+	// openingPrompt 开场时代替学习者发言，部分服务要求至少一条用户消息
+	openingPrompt = "(The learner has joined. Start the role-play with your first line.)"
+)
+
+// OpenAI 兼容 OpenAI Chat Completions 接口的大模型
+// 适用于 OpenAI 及提供相同接口的服务（如 vLLM、Ollama、各类国内大模型网关）
+type OpenAI struct {
+	baseURL     string
+	apiKey      string
+	model       string
+	temperature float64
+	client      *http.Client
+}
+
+// NewOpenAI 创建大模型引擎
+func NewOpenAI(cfg config.OpenAIConfig) (*OpenAI, error) {
+	if cfg.BaseURL == "" || cfg.Model == "" {
+		return nil, fmt.Errorf("openai dialogue model requires base_url and model")
+	}
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &OpenAI{
+		baseURL:     strings.TrimRight(cfg.BaseURL, "/"),
+		apiKey:      cfg.APIKey,
+		model:       cfg.Model,
+		temperature: cfg.Temperature,
+		client:      &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Name 引擎名称
+func (m *OpenAI) Name() string {
+	return OpenAIName
+}
+
+// chatMessage Chat Completions 消息
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatRequest Chat Completions 请求
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+	MaxTokens   int           `json:"max_tokens"`
+}
+
+// chatResponse Chat Completions 响应，只解析需要的字段
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+// Reply 调用大模型生成回复
+// 回复以 [END] 结尾表示场景目标已完成
+func (m *OpenAI) Reply(ctx context.Context, req *Request) (*Reply, error) {
+	messages := chatMessages(req.Scenario, req.History)
+	if len(req.History) == 0 {
+		messages = append(messages, chatMessage{Role: "user", Content: openingPrompt})
+	}
+
+	text, err := m.complete(ctx, messages, maxReplyTokens)
+	if err != nil {
+		return nil, err
+	}
+	done := strings.Contains(text, endMarker)
+	text = strings.TrimSpace(strings.ReplaceAll(text, endMarker, ""))
+	if text == "" {
+		return nil, ErrEmptyReply
+	}
+	return &Reply{Text: text, Done: done, Engine: OpenAIName}, nil
+}
+
+// Hint 调用大模型生成三种难度的建议回答
+// 要求模型只输出 JSON，兼容部分模型在 JSON 外包裹代码块的情况
+func (m *OpenAI) Hint(ctx context.Context, req *HintRequest) (*Hints, error) {
+	messages := chatMessages(req.Scenario, req.History)
+	messages = append(messages, chatMessage{Role: "user", Content: hintPrompt(req)})
+
+	text, err := m.complete(ctx, messages, maxHintTokens)
+	if err != nil {
+		return nil, err
+	}
+	if i, j := strings.Index(text, "{"), strings.LastIndex(text, "}"); i >= 0 && j > i {
+		text = text[i : j+1]
+	}
+	var out struct {
+		Suggestions []models.DialogueSuggestion `json:"suggestions"`
+	}
+	if err := json.Unmarshal([]byte(text), &out); err != nil {
+		return nil, fmt.Errorf("decode hint: %w", err)
+	}
+	if len(cleanSuggestions(out.Suggestions)) == 0 {
+		return nil, ErrEmptyReply
+	}
+	return &Hints{Suggestions: out.Suggestions, Engine: OpenAIName}, nil
+}
+
+// hintPrompt 跳出角色扮演，请模型为学习者给出建议回答
+func hintPrompt(req *HintRequest) string {
+	level := orDefault(req.Level, "A1")
+	return "(Step out of the role-play.) The learner, whose English vocabulary is at CEFR " + level +
+		" level, does not know how to reply to your last line. Suggest three replies the learner could say next, in character as " +
+		orDefault(req.Scenario.UserRole, "the learner") + ": a simple one using only basic words, a natural one a native speaker would use, " +
+		"and an advanced one with richer expressions. For each give a Chinese translation and the one to three key phrases worth learning. " +
+		`Respond with JSON only, in the form {"suggestions":[{"level":"simple","text":"...","translation":"...","key_phrases":["..."]},` +
+		`{"level":"natural",...},{"level":"advanced",...}]}.`
+}
+
+// chatMessages 角色设定加对话历史
+func chatMessages(scenario *models.DialogueScenario, history []Message) []chatMessage {
+	messages := []chatMessage{{Role: "system", Content: systemPrompt(scenario)}}
+	for _, h := range history {
+		role := "user"
+		if h.Role == models.DialogueRoleAssistant {
+			role = "assistant"
+		}
+		messages = append(messages, chatMessage{Role: role, Content: h.Text})
+	}
+	return messages
+}
+
+// complete 调用 Chat Completions 接口，返回第一条回复的内容
+func (m *OpenAI) complete(ctx context.Context, messages []chatMessage, maxTokens int) (string, error) {
+	body, err := json.Marshal(chatRequest{
+		Model:       m.model,
+		Messages:    messages,
+		Temperature: m.temperature,
+		MaxTokens:   maxTokens,
+	})
+	if err != nil {
+		return "", err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, m.baseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if m.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+m.apiKey)
+	}
+
+	resp, err := m.client.Do(httpReq)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return "", fmt.Errorf("chat completions: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	var out chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("decode chat completions: %w", err)
+	}
+	if len(out.Choices) == 0 {
+		return "", ErrEmptyReply
+	}
+	return strings.TrimSpace(out.Choices[0].Message.Content), nil
+}
@@ -0,0 +1,194 @@
+package dialogue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"server/config"
+	"server/models"
+	"server/utils"
+)
+
+// testScenario 测试用场景
+var testScenario = &models.DialogueScenario{
+	Slug:  "cafe",
+	Title: "Ordering coffee",
+	Lines: []models.DialogueLine{
+		{Role: models.DialogueRoleAssistant, Text: "Hi, what can I get you?", Translation: "你好，想喝点什么？"},
+		{Role: models.DialogueRoleUser, Text: "A latte, please."},
+		{Role: models.DialogueRoleAssistant, Text: "Here you go."},
+	},
+}
+
+// chatServer 启动模拟的 Chat Completions 服务，按 handler 返回状态码和回复内容
+func chatServer(t *testing.T, handler func(req chatRequest) (int, string)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("path = %s, want /v1/chat/completions", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer test-key" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var req chatRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		status, content := handler(req)
+		w.WriteHeader(status)
+		if status != http.StatusOK {
+			w.Write([]byte(content))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"role": "assistant", "content": content}}},
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func openAIConfig(srv *httptest.Server) config.OpenAIConfig {
+	return config.OpenAIConfig{BaseURL: srv.URL + "/v1/", APIKey: "test-key", Model: "test-model", TimeoutSeconds: 5}
+}
+
+func TestOpenAIReply(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		wantText string
+		wantDone bool
+		wantErr  error
+	}{
+		{"plain reply", "  Sure, hot or iced?  ", "Sure, hot or iced?", false, nil},
+		{"end marker", "Enjoy your coffee! [END]", "Enjoy your coffee!", true, nil},
+		{"end marker only", "[END]", "", false, ErrEmptyReply},
+		{"empty", "", "", false, ErrEmptyReply},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := chatServer(t, func(req chatRequest) (int, string) {
+				if req.Model != "test-model" || req.MaxTokens != maxReplyTokens {
+					t.Errorf("model = %q, max_tokens = %d", req.Model, req.MaxTokens)
+				}
+				if len(req.Messages) != 3 || req.Messages[0].Role != "system" {
+					t.Errorf("messages = %+v, want system prompt and history", req.Messages)
+				}
+				return http.StatusOK, tt.content
+			})
+			m, err := NewOpenAI(openAIConfig(srv))
+			if err != nil {
+				t.Fatal(err)
+			}
+			history := []Message{
+				{Role: models.DialogueRoleAssistant, Text: "Hi, what can I get you?"},
+				{Role: models.DialogueRoleUser, Text: "A latte, please."},
+			}
+			reply, err := m.Reply(context.Background(), &Request{Scenario: testScenario, History: history})
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if reply.Text != tt.wantText || reply.Done != tt.wantDone || reply.Engine != OpenAIName {
+				t.Errorf("reply = %+v, want text %q done %v", reply, tt.wantText, tt.wantDone)
+			}
+		})
+	}
+}
+
+func TestOpenAIOpening(t *testing.T) {
+	srv := chatServer(t, func(req chatRequest) (int, string) {
+		last := req.Messages[len(req.Messages)-1]
+		if last.Role != "user" || last.Content != openingPrompt {
+			t.Errorf("last message = %+v, want opening prompt", last)
+		}
+		return http.StatusOK, "Hi! What can I get you today?"
+	})
+	m, err := NewOpenAI(openAIConfig(srv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Reply(context.Background(), &Request{Scenario: testScenario}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenAIHint(t *testing.T) {
+	srv := chatServer(t, func(req chatRequest) (int, string) {
+		return http.StatusOK, "```json\n" + `{"suggestions":[` +
+			`{"level":"simple","text":"Coffee, please.","translation":"请给我咖啡。","key_phrases":["please"]},` +
+			`{"level":"natural","text":"Could I get a latte?","translation":"我能要一杯拿铁吗？"},` +
+			`{"level":"advanced","text":"I'd love a large oat latte.","translation":"我想要一大杯燕麦拿铁。"}]}` + "\n```"
+	})
+	m, err := NewOpenAI(openAIConfig(srv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hints, err := m.Hint(context.Background(), &HintRequest{Scenario: testScenario, Level: "A2"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hints.Suggestions) != 3 || hints.Suggestions[1].Text != "Could I get a latte?" || hints.Engine != OpenAIName {
+		t.Errorf("hints = %+v", hints)
+	}
+}
+
+func TestOpenAIErrorStatus(t *testing.T) {
+	srv := chatServer(t, func(req chatRequest) (int, string) {
+		return http.StatusTooManyRequests, `{"error":{"message":"rate limited"}}`
+	})
+	m, err := NewOpenAI(openAIConfig(srv))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = m.Reply(context.Background(), &Request{Scenario: testScenario})
+	if err == nil || !strings.Contains(err.Error(), "429") || !strings.Contains(err.Error(), "rate limited") {
+		t.Fatalf("err = %v, want status and body", err)
+	}
+}
+
+func TestOpenAIFallback(t *testing.T) {
+	// 回退时记录警告日志，测试中未初始化日志文件
+	utils.SetLogLevel("ERROR")
+	t.Cleanup(func() { utils.SetLogLevel("INFO") })
+
+	srv := chatServer(t, func(req chatRequest) (int, string) {
+		return http.StatusServiceUnavailable, "upstream unavailable"
+	})
+	m, err := New(config.DialogueConfig{Model: OpenAIName, OpenAI: openAIConfig(srv)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Name() != OpenAIName {
+		t.Errorf("Name() = %q, want %q", m.Name(), OpenAIName)
+	}
+
+	reply, err := m.Reply(context.Background(), &Request{Scenario: testScenario})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reply.Engine != ScriptedName || reply.Text != "Hi, what can I get you?" {
+		t.Errorf("reply = %+v, want scripted opening line", reply)
+	}
+
+	hints, err := m.Hint(context.Background(), &HintRequest{
+		Scenario: testScenario,
+		History:  []Message{{Role: models.DialogueRoleAssistant, Text: "Hi, what can I get you?"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hints.Engine != ScriptedName || len(hints.Suggestions) == 0 {
+		t.Errorf("hints = %+v, want scripted suggestions", hints)
+	}
+}
@@ -0,0 +1,296 @@
+package dialogue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"server/dictation"
+	"server/grammar"
+	"server/models"
+	"server/speech"
+	"server/storage"
+	"server/utils"
+)
+
+const (
+	// naturalAccuracy 与参考回答的相似度达到该值时视为说法地道，只推荐进阶说法
+	naturalAccuracy = 0.8
+	// goodSpeakingScore 口语综合分达到该值时列为亮点
+	goodSpeakingScore = 80.0
+	// maxSpeakingFeedback 报告中保留的口语改进建议条数
+	maxSpeakingFeedback = 3
+)
+
+// Report 对话复盘报告
+type Report struct {
+	SessionID     uint             `json:"session_id"`
+	Scenario      string           `json:"scenario"` // 场景标识
+	Title         string           `json:"title"`    // 场景标题
+	Goal          string           `json:"goal"`
+	Status        string           `json:"status"`
+	Duration      float64          `json:"duration"` // 对话用时（秒）
+	UserTurns     int              `json:"user_turns"`
+	HintsUsed     int              `json:"hints_used"`
+	GrammarIssues int              `json:"grammar_issues"` // 语法问题总数
+	CleanTurns    int              `json:"clean_turns"`    // 没有发现语法问题的发言数
+	NaturalTurns  int              `json:"natural_turns"`  // 与参考回答一样地道的发言数
+	Highlights    []string         `json:"highlights"`     // 做得好的地方
+	Speaking      *SpeakingSummary `json:"speaking"`       // 口语评分汇总，没有可评分的语音发言时为空
+	PendingScores int              `json:"pending_scores"` // 仍在后台评分中的语音发言数
+	Turns         []TurnReview     `json:"turns"`
+}
+
+// TurnReview 学习者一次发言的复盘
+type TurnReview struct {
+	Seq       int                      `json:"seq"`
+	Text      string                   `json:"text"`
+	Hinted    bool                     `json:"hinted"`              // 这一轮使用了救命按钮
+	Issues    []grammar.Issue          `json:"issues"`              // 语法问题
+	Corrected string                   `json:"corrected,omitempty"` // 按建议改正后的句子
+	Reference string                   `json:"reference,omitempty"` // 剧本中的参考回答
+	Better    []models.DialogueVariant `json:"better,omitempty"`    // 更地道的说法
+	Score     *TurnScore               `json:"score,omitempty"`     // 语音发言的评分
+}
+
+// TurnScore 一次语音发言的评分
+type TurnScore struct {
+	Pronunciation float64 `json:"pronunciation"`
+	Fluency       float64 `json:"fluency"`
+	Intonation    float64 `json:"intonation"`
+	Overall       float64 `json:"overall"`
+}
+
+// SpeakingSummary 各轮语音发言评分的汇总，按发言的单词数加权
+type SpeakingSummary struct {
+	ScoredTurns   int      `json:"scored_turns"`
+	Pronunciation float64  `json:"pronunciation"`
+	Fluency       float64  `json:"fluency"`
+	Intonation    float64  `json:"intonation"`
+	Overall       float64  `json:"overall"`
+	Feedback      []string `json:"feedback"` // 出现最多的改进建议
+}
+
+// ScoreTurns 为尚未评分的语音发言评分并保存
+// 录音不存在、格式不支持等无法评分的情况记录原因，不再重试；超时或取消时保留未评分状态，由后台评分任务重试
+func ScoreTurns(ctx context.Context, db *gorm.DB, store storage.Storage, scorer speech.PronunciationScorer, turns []models.DialogueTurn, now time.Time) error {
+	for i := range turns {
+		t := &turns[i]
+		if t.Role != models.DialogueRoleUser || t.RecordingID == nil || t.ScoredAt != nil {
+			continue
+		}
+		score, err := scoreTurn(ctx, db, store, scorer, t)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return ctxErr
+			}
+			utils.Warn("dialogue.ScoreTurns - TurnID: %d not scored: %v", t.ID, err)
+			t.ScoreError = truncate(err.Error(), 255)
+		} else {
+			t.Pronunciation, t.Fluency, t.Intonation, t.Overall = score.Pronunciation, score.Fluency, score.Intonation, score.Overall
+			t.Feedback, t.ScoreEngine = score.Feedback, score.Engine
+		}
+		t.ScoredAt = &now
+		err = db.Model(t).Select("pronunciation", "fluency", "intonation", "overall", "feedback", "score_engine", "score_error", "scored_at").
+			Updates(t).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scoreTurn 读取发言录音并评分，以发言文本作为参考文本
+func scoreTurn(ctx context.Context, db *gorm.DB, store storage.Storage, scorer speech.PronunciationScorer, t *models.DialogueTurn) (*speech.Score, error) {
+	var rec models.Recording
+	if err := db.First(&rec, *t.RecordingID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("录音已删除或过期")
+		}
+		return nil, err
+	}
+	obj, err := store.Open(ctx, rec.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, errors.New("录音已删除或过期")
+		}
+		return nil, err
+	}
+	defer obj.Body.Close()
+	data, err := io.ReadAll(obj.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read recording: %w", err)
+	}
+	return scorer.Score(ctx, &speech.ScoreRequest{
+		Audio:         data,
+		Format:        strings.TrimPrefix(path.Ext(rec.StorageKey), "."),
+		ReferenceText: t.Text,
+		Language:      "en-US",
+	})
+}
+
+// BuildReport 根据对话记录生成复盘报告
+// 语法问题由规则检查得出；更地道的说法取自剧本中对应位置的参考回答及其进阶说法，
+// 对应位置按离线剧本引擎的规则重放对话历史确定
+func BuildReport(session *models.DialogueSession, scenario *models.DialogueScenario, turns []models.DialogueTurn, hints []models.DialogueHint) *Report {
+	r := &Report{
+		SessionID: session.ID,
+		Scenario:  scenario.Slug,
+		Title:     scenario.Title,
+		Goal:      scenario.Goal,
+		Status:    session.Status,
+		UserTurns: session.UserTurns,
+		HintsUsed: session.HintsUsed,
+		Turns:     []TurnReview{},
+	}
+	if session.CompletedAt != nil {
+		r.Duration = math.Round(session.CompletedAt.Sub(session.CreatedAt).Seconds())
+	}
+	hinted := map[int]bool{}
+	for _, h := range hints {
+		hinted[h.Seq] = true
+	}
+
+	lines := scenario.Lines
+	var history []Message
+	var scored []models.DialogueTurn
+	for _, t := range turns {
+		if t.Role != models.DialogueRoleUser {
+			history = append(history, Message{Role: t.Role, Text: t.Text})
+			continue
+		}
+		review := TurnReview{Seq: t.Seq, Text: t.Text, Hinted: hinted[t.Seq], Issues: grammar.Check(t.Text)}
+		if review.Issues == nil {
+			review.Issues = []grammar.Issue{}
+		}
+		if len(review.Issues) > 0 {
+			review.Corrected = grammar.Apply(t.Text, review.Issues)
+			r.GrammarIssues += len(review.Issues)
+		} else {
+			r.CleanTurns++
+		}
+
+		if len(lines) > 0 {
+			if pos, _ := replay(lines, history); pos < len(lines) && lines[pos].Role == models.DialogueRoleUser {
+				expected := lines[pos]
+				natural := dictation.Grade(expected.Text, t.Text).Accuracy >= naturalAccuracy
+				if natural {
+					r.NaturalTurns++
+				} else {
+					review.Reference = expected.Text
+					review.Better = append(review.Better, models.DialogueVariant{
+						Level: models.DialogueHintNatural, Text: expected.Text, Translation: expected.Translation,
+					})
+				}
+				for _, v := range expected.Variants {
+					if v.Level == models.DialogueHintAdvanced {
+						review.Better = append(review.Better, v)
+					}
+				}
+			}
+		}
+
+		if t.RecordingID != nil && t.ScoredAt == nil {
+			r.PendingScores++
+		}
+		if t.Scored() {
+			review.Score = &TurnScore{Pronunciation: t.Pronunciation, Fluency: t.Fluency, Intonation: t.Intonation, Overall: t.Overall}
+			scored = append(scored, t)
+		}
+		r.Turns = append(r.Turns, review)
+		history = append(history, Message{Role: t.Role, Text: t.Text})
+	}
+
+	r.Speaking = summarizeSpeaking(scored)
+	scriptDone := false
+	if len(lines) > 0 {
+		pos, _ := replay(lines, history)
+		scriptDone = pos >= len(lines)
+	}
+	r.Highlights = highlights(r, scriptDone)
+	return r
+}
+
+// summarizeSpeaking 按发言的单词数加权汇总评分，长句的表现更能代表口语水平
+func summarizeSpeaking(turns []models.DialogueTurn) *SpeakingSummary {
+	if len(turns) == 0 {
+		return nil
+	}
+	s := &SpeakingSummary{ScoredTurns: len(turns), Feedback: []string{}}
+	var total float64
+	counts := map[string]int{}
+	var order []string
+	for _, t := range turns {
+		w := math.Max(1, float64(len(speech.Words(t.Text))))
+		total += w
+		s.Pronunciation += w * t.Pronunciation
+		s.Fluency += w * t.Fluency
+		s.Intonation += w * t.Intonation
+		s.Overall += w * t.Overall
+		for _, f := range t.Feedback {
+			if counts[f] == 0 {
+				order = append(order, f)
+			}
+			counts[f]++
+		}
+	}
+	s.Pronunciation = round1(s.Pronunciation / total)
+	s.Fluency = round1(s.Fluency / total)
+	s.Intonation = round1(s.Intonation / total)
+	s.Overall = round1(s.Overall / total)
+
+	sort.SliceStable(order, func(i, j int) bool { return counts[order[i]] > counts[order[j]] })
+	s.Feedback = append(s.Feedback, order[:min(len(order), maxSpeakingFeedback)]...)
+	return s
+}
+
+// highlights 总结做得好的地方，至少给出一条鼓励
+func highlights(r *Report, scriptDone bool) []string {
+	out := []string{}
+	if scriptDone {
+		if r.Goal != "" {
+			out = append(out, "完成了场景目标："+r.Goal)
+		} else {
+			out = append(out, "完整地完成了整个场景对话")
+		}
+	}
+	if r.UserTurns > 0 {
+		if r.CleanTurns == len(r.Turns) {
+			out = append(out, "所有发言都没有发现语法问题")
+		} else if r.CleanTurns > 0 {
+			out = append(out, fmt.Sprintf("%d/%d 句发言没有发现语法问题", r.CleanTurns, len(r.Turns)))
+		}
+		if r.NaturalTurns > 0 {
+			out = append(out, fmt.Sprintf("%d 句回答和参考说法一样地道", r.NaturalTurns))
+		}
+		if r.HintsUsed == 0 {
+			out = append(out, "全程没有使用救命按钮，独立完成了对话")
+		}
+	}
+	if r.Speaking != nil && r.Speaking.Overall >= goodSpeakingScore {
+		out = append(out, fmt.Sprintf("发音清晰自然，口语综合评分 %.0f", r.Speaking.Overall))
+	}
+	if len(out) == 0 {
+		out = append(out, "坚持完成了对话，多练几次会越来越流利")
+	}
+	return out
+}
+
+func round1(x float64) float64 {
+	return math.Round(x*10) / 10
+}
+
+// truncate 按字符截断
+func truncate(s string, n int) string {
+	if r := []rune(s); len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
@@ -0,0 +1,80 @@
+package dialogue
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"server/models"
+)
+
+// slugPattern 场景标识：小写字母、数字、下划线和连字符
+var slugPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,49}$`)
+
+// ValidateScenario 校验并规范化场景
+// 剧本由 AI 台词开场，台词角色只能是 assistant 或 user
+func ValidateScenario(s *models.DialogueScenario) error {
+	s.Slug = strings.ToLower(strings.TrimSpace(s.Slug))
+	if !slugPattern.MatchString(s.Slug) {
+		return fmt.Errorf("场景标识无效，只能包含小写字母、数字、下划线和连字符")
+	}
+	s.Title = strings.TrimSpace(s.Title)
+	if s.Title == "" {
+		return fmt.Errorf("场景标题不能为空")
+	}
+	s.Difficulty = strings.ToUpper(strings.TrimSpace(s.Difficulty))
+	if s.Difficulty != "" && !models.IsValidCEFRLevel(s.Difficulty) {
+		return fmt.Errorf("难度等级无效，应为 A1-C2")
+	}
+
+	if len(s.Lines) == 0 || s.Lines[0].Role != models.DialogueRoleAssistant {
+		return fmt.Errorf("剧本的第一句须为 AI 台词，作为对话开场白")
+	}
+	for i := range s.Lines {
+		l := &s.Lines[i]
+		l.Text = strings.TrimSpace(l.Text)
+		if l.Text == "" {
+			return fmt.Errorf("第%d句台词为空", i+1)
+		}
+		switch l.Role {
+		case models.DialogueRoleAssistant:
+			if len(l.Variants) > 0 {
+				return fmt.Errorf("第%d句为 AI 台词，不能设置其他说法", i+1)
+			}
+		case models.DialogueRoleUser:
+		default:
+			return fmt.Errorf("第%d句角色无效，应为 assistant 或 user", i+1)
+		}
+		for j := range l.Variants {
+			v := &l.Variants[j]
+			v.Text = strings.TrimSpace(v.Text)
+			if v.Text == "" {
+				return fmt.Errorf("第%d句的其他说法为空", i+1)
+			}
+			if v.Level != models.DialogueHintSimple && v.Level != models.DialogueHintAdvanced {
+				return fmt.Errorf("第%d句的其他说法难度无效，应为 simple 或 advanced", i+1)
+			}
+		}
+		keywords := l.Keywords[:0]
+		for _, k := range l.Keywords {
+			if k = strings.TrimSpace(k); k != "" {
+				keywords = append(keywords, k)
+			}
+		}
+		l.Keywords = keywords
+	}
+	return nil
+}
+
+// SaveScenario 按场景标识创建或更新场景
+// 已有对话记录保存的是发言原文，更新剧本不影响历史记录
+func SaveScenario(db *gorm.DB, s *models.DialogueScenario) error {
+	return db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "slug"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"title", "description", "difficulty", "ai_role", "user_role", "goal", "prompt", "lines", "updated_at", "deleted_at",
+		}),
+	}).Create(s).Error
+}
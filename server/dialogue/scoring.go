@@ -0,0 +1,103 @@
+package dialogue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"gorm.io/gorm"
+	"server/models"
+	"server/speech"
+	"server/storage"
+	"server/utils"
+)
+
+const (
+	// scoreBatchSize 每次从数据库取出的待评分发言数
+	scoreBatchSize = 20
+	// scoreTimeout 单条发言的评分超时时间，超时的发言留到下一轮重试
+	scoreTimeout = 30 * time.Second
+)
+
+// scoreKick 唤醒评分任务，容量为 1，多次通知只唤醒一次
+var scoreKick = make(chan struct{}, 1)
+
+// NotifyScoring 有新的语音发言时唤醒评分任务
+func NotifyScoring() {
+	select {
+	case scoreKick <- struct{}{}:
+	default:
+	}
+}
+
+// StartScoring 启动后台任务，为语音发言评分
+// 新发言提交时立即唤醒，另外每隔 interval 检查一次，接上重启前和超时未完成的评分
+func StartScoring(db *gorm.DB, store storage.Storage, scorer speech.PronunciationScorer, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			n, err := ScorePending(context.Background(), db, store, scorer)
+			if err != nil {
+				utils.Error("Dialogue scoring - Run failed after %d turns: %v", n, err)
+			} else if n > 0 {
+				utils.Info("Dialogue scoring - Scored %d turns", n)
+			}
+			select {
+			case <-ticker.C:
+			case <-scoreKick:
+			}
+		}
+	}()
+}
+
+// ScorePending 为当前所有尚未评分的语音发言评分，返回处理数量
+// 每条发言每轮只处理一次，超时的发言保留未评分状态，留到下一轮重试
+func ScorePending(ctx context.Context, db *gorm.DB, store storage.Storage, scorer speech.PronunciationScorer) (int, error) {
+	processed := 0
+	var lastID uint
+	for {
+		var batch []models.DialogueTurn
+		err := db.Where("role = ? AND recording_id IS NOT NULL AND scored_at IS NULL AND id > ?", models.DialogueRoleUser, lastID).
+			Order("id ASC").Limit(scoreBatchSize).Find(&batch).Error
+		if err != nil {
+			return processed, err
+		}
+		if len(batch) == 0 {
+			return processed, nil
+		}
+		for i := range batch {
+			if err := scoreGuarded(ctx, db, store, scorer, batch[i:i+1]); err != nil {
+				if ctx.Err() != nil {
+					return processed, ctx.Err()
+				}
+				if !errors.Is(err, context.DeadlineExceeded) {
+					return processed, err
+				}
+				utils.Warn("Dialogue scoring - TurnID: %d timed out, retry later", batch[i].ID)
+			}
+			lastID = batch[i].ID
+			processed++
+		}
+	}
+}
+
+// scoreGuarded 为一条发言评分
+// 评分引擎处理用户上传的录音时 panic 不会拖垮服务，panic 记录为该发言的评分失败原因，不再重试
+func scoreGuarded(ctx context.Context, db *gorm.DB, store storage.Storage, scorer speech.PronunciationScorer, turn []models.DialogueTurn) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			t := &turn[0]
+			utils.Error("Dialogue scoring - TurnID: %d panic: %v\n%s", t.ID, r, debug.Stack())
+			now := time.Now()
+			t.ScoreError = truncate(fmt.Sprintf("评分异常: %v", r), 255)
+			t.ScoredAt = &now
+			err = db.Model(t).Select("score_error", "scored_at").Updates(t).Error
+		}
+	}()
+	turnCtx, cancel := context.WithTimeout(ctx, scoreTimeout)
+	defer cancel()
+	return ScoreTurns(turnCtx, db, store, scorer, turn, time.Now())
+}
@@ -0,0 +1,71 @@
+package dialogue
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"server/models"
+	"server/speech"
+	"server/storage"
+	"server/utils"
+)
+
+// panicScorer 处理录音时 panic 的评分引擎
+type panicScorer struct{}
+
+func (panicScorer) Name() string { return "panic" }
+
+func (panicScorer) Score(ctx context.Context, req *speech.ScoreRequest) (*speech.Score, error) {
+	panic("integer divide by zero")
+}
+
+func TestScorePendingRecoversPanic(t *testing.T) {
+	// panic 以错误级别记录，测试中未初始化日志文件
+	utils.SetLogLevel("FATAL")
+	t.Cleanup(func() { utils.SetLogLevel("INFO") })
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.Recording{}, &models.DialogueTurn{}); err != nil {
+		t.Fatal(err)
+	}
+	store, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := store.Put(ctx, "recordings/1/a.wav", bytes.NewReader([]byte("RIFF")), 4, "audio/wav"); err != nil {
+		t.Fatal(err)
+	}
+	rec := models.Recording{UserID: 1, StorageKey: "recordings/1/a.wav", ContentType: "audio/wav"}
+	if err := db.Create(&rec).Error; err != nil {
+		t.Fatal(err)
+	}
+	turn := models.DialogueTurn{SessionID: 1, Seq: 2, Role: models.DialogueRoleUser, Text: "A latte, please.", RecordingID: &rec.ID}
+	if err := db.Create(&turn).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	n, err := ScorePending(ctx, db, store, panicScorer{})
+	if err != nil || n != 1 {
+		t.Fatalf("ScorePending = %d, %v, want 1, nil", n, err)
+	}
+	if err := db.First(&turn, turn.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if turn.ScoredAt == nil || !strings.Contains(turn.ScoreError, "integer divide by zero") {
+		t.Errorf("turn = %+v, want panic recorded as score error", turn)
+	}
+
+	// 已记录失败原因的发言不再重试
+	if n, err := ScorePending(ctx, db, store, panicScorer{}); err != nil || n != 0 {
+		t.Errorf("second ScorePending = %d, %v, want 0, nil", n, err)
+	}
+}
@@ -0,0 +1,181 @@
+package dialogue
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"server/models"
+)
+
+// ScriptedName 离线剧本引擎名称
+const ScriptedName = "scripted"
+
+// maxMisses 学习者连续没有回应到剧本要点的次数上限，超过后不再重问，直接推进剧情
+const maxMisses = 2
+
+// retryLines 学习者没有回应到要点时的追问，依次使用
+var retryLines = []struct {
+	Text        string
+	Translation string
+}{
+	{"Sorry, could you say that again?", "抱歉，能再说一遍吗？"},
+	{"Sorry, I didn't quite catch that. You could say: ", "抱歉，我没太听明白。你可以这样说："},
+}
+
+// Scripted 离线剧本引擎
+// 按 DialogueScenario.Lines 推进对话：学习者的回答包含参考回答的关键词时进入下一段，
+// 否则追问，连续 maxMisses 次未命中后直接推进。相同的对话历史总是得到相同的回复
+type Scripted struct{}
+
+// Name 引擎名称
+func (Scripted) Name() string {
+	return ScriptedName
+}
+
+// Reply 根据对话历史重放剧本，返回下一句台词
+func (Scripted) Reply(ctx context.Context, req *Request) (*Reply, error) {
+	lines := req.Scenario.Lines
+	if len(lines) == 0 {
+		return nil, ErrNoScript
+	}
+	pos, reply := replay(lines, req.History)
+	return &Reply{Text: reply.Text, Translation: reply.Translation, Done: pos >= len(lines), Engine: ScriptedName}, nil
+}
+
+// Hint 以剧本中下一句学习者台词为参考给出建议回答
+// 参考回答为自然说法，简单/进阶说法取自台词的 variants；没有简单说法时以关键词作为最短回答
+func (Scripted) Hint(ctx context.Context, req *HintRequest) (*Hints, error) {
+	lines := req.Scenario.Lines
+	if len(lines) == 0 {
+		return nil, ErrNoScript
+	}
+	pos, _ := replay(lines, req.History)
+	if pos >= len(lines) {
+		return &Hints{Suggestions: genericHints, Engine: ScriptedName}, nil
+	}
+
+	expected := lines[pos]
+	out := []models.DialogueSuggestion{{
+		Level:       models.DialogueHintNatural,
+		Text:        expected.Text,
+		Translation: expected.Translation,
+		KeyPhrases:  keyPhrases(expected.Keywords, expected.Text),
+	}}
+	hasSimple := false
+	for _, v := range expected.Variants {
+		hasSimple = hasSimple || v.Level == models.DialogueHintSimple
+		out = append(out, models.DialogueSuggestion{
+			Level:       v.Level,
+			Text:        v.Text,
+			Translation: v.Translation,
+			KeyPhrases:  keyPhrases(expected.Keywords, v.Text),
+		})
+	}
+	if !hasSimple && len(expected.Keywords) > 0 {
+		kw := strings.TrimSpace(expected.Keywords[0])
+		if kw != "" && normalize(kw) != normalize(expected.Text) {
+			out = append(out, models.DialogueSuggestion{
+				Level:      models.DialogueHintSimple,
+				Text:       strings.ToUpper(kw[:1]) + kw[1:] + ".",
+				KeyPhrases: []string{kw},
+			})
+		}
+	}
+	return &Hints{Suggestions: out, Engine: ScriptedName}, nil
+}
+
+// keyPhrases 关键词中出现在该说法里的部分
+func keyPhrases(keywords []string, text string) []string {
+	said := " " + normalize(text) + " "
+	var out []string
+	for _, kw := range keywords {
+		if n := normalize(kw); n != "" && strings.Contains(said, " "+n+" ") {
+			out = append(out, strings.TrimSpace(kw))
+		}
+	}
+	return out
+}
+
+// replay 按对话历史推进剧本，返回下一句学习者台词的位置和 AI 最近一次的回复
+func replay(lines []models.DialogueLine, history []Message) (int, scriptedReply) {
+	pos, reply := assistantLines(lines, 0)
+	misses := 0
+	for _, m := range history {
+		if m.Role != models.DialogueRoleUser {
+			continue
+		}
+		if pos >= len(lines) {
+			// 剧本已结束，正常情况下对话已随之结束
+			reply = scriptedReply{Text: "It was nice talking with you. Goodbye!", Translation: "很高兴和你聊天，再见！"}
+			continue
+		}
+		expected := lines[pos]
+		if !matches(expected, m.Text) && misses < maxMisses {
+			reply = retry(expected, misses)
+			misses++
+			continue
+		}
+		misses = 0
+		pos, reply = assistantLines(lines, pos+1)
+		if reply.Text == "" {
+			reply = scriptedReply{Text: "Okay.", Translation: "好的。"}
+		}
+	}
+	return pos, reply
+}
+
+// scriptedReply 剧本生成的一次回复
+type scriptedReply struct {
+	Text        string
+	Translation string
+}
+
+// assistantLines 从 pos 开始取出连续的 AI 台词，返回下一句学习者台词的位置
+func assistantLines(lines []models.DialogueLine, pos int) (int, scriptedReply) {
+	var text, translation []string
+	for ; pos < len(lines) && lines[pos].Role != models.DialogueRoleUser; pos++ {
+		text = append(text, lines[pos].Text)
+		if lines[pos].Translation != "" {
+			translation = append(translation, lines[pos].Translation)
+		}
+	}
+	return pos, scriptedReply{Text: strings.Join(text, " "), Translation: strings.Join(translation, "")}
+}
+
+// retry 第 n 次追问，最后一次给出参考回答
+func retry(expected models.DialogueLine, n int) scriptedReply {
+	r := retryLines[min(n, len(retryLines)-1)]
+	if n < len(retryLines)-1 {
+		return scriptedReply{Text: r.Text, Translation: r.Translation}
+	}
+	return scriptedReply{Text: r.Text + "\"" + expected.Text + "\"", Translation: r.Translation + expected.Text}
+}
+
+// matches 学习者的回答是否回应了剧本中的参考回答
+func matches(expected models.DialogueLine, text string) bool {
+	said := " " + normalize(text) + " "
+	if strings.TrimSpace(said) == "" {
+		return false
+	}
+	if len(expected.Keywords) == 0 {
+		return true
+	}
+	for _, kw := range expected.Keywords {
+		if kw = normalize(kw); kw != "" && strings.Contains(said, " "+kw+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize 转为小写并将标点替换为空格，按单词边界匹配关键词
+func normalize(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
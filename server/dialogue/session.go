@@ -0,0 +1,120 @@
+package dialogue
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"server/models"
+)
+
+// Start 创建对话并生成开场白
+// 开场白为空时不创建对话，返回 ErrEmptyReply
+func Start(ctx context.Context, db *gorm.DB, m DialogueModel, userID uint, scenario *models.DialogueScenario, now time.Time) (*models.DialogueSession, error) {
+	reply, err := m.Reply(ctx, &Request{Scenario: scenario})
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(reply.Text) == "" {
+		return nil, ErrEmptyReply
+	}
+
+	session := &models.DialogueSession{
+		UserID:     userID,
+		ScenarioID: scenario.ID,
+		Status:     models.DialogueStatusActive,
+		Engine:     m.Name(),
+	}
+	if reply.Done {
+		session.Status, session.CompletedAt = models.DialogueStatusCompleted, &now
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(session).Error; err != nil {
+			return err
+		}
+		turn := assistantTurn(session.ID, 1, reply)
+		if err := tx.Create(&turn).Error; err != nil {
+			return err
+		}
+		session.Turns = []models.DialogueTurn{turn}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// Say 学习者发言并生成 AI 的回复
+// 大模型调用耗时较长，生成回复期间不持有事务；并发提交时只有一轮能写入，其余返回 ErrTurnConflict
+func Say(ctx context.Context, db *gorm.DB, m DialogueModel, session *models.DialogueSession, scenario *models.DialogueScenario,
+	text string, recordingID *uint, maxTurns int, now time.Time) (*models.DialogueTurn, *models.DialogueTurn, error) {
+	if session.Status != models.DialogueStatusActive {
+		return nil, nil, ErrSessionFinished
+	}
+
+	var turns []models.DialogueTurn
+	if err := db.Where("session_id = ?", session.ID).Order("seq ASC").Find(&turns).Error; err != nil {
+		return nil, nil, err
+	}
+	history := make([]Message, 0, len(turns)+1)
+	for _, t := range turns {
+		history = append(history, Message{Role: t.Role, Text: t.Text})
+	}
+	history = append(history, Message{Role: models.DialogueRoleUser, Text: text})
+
+	reply, err := m.Reply(ctx, &Request{Scenario: scenario, History: history})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	seq := len(turns) + 1
+	userTurn := models.DialogueTurn{SessionID: session.ID, Seq: seq, Role: models.DialogueRoleUser, Text: text, RecordingID: recordingID}
+	replyTurn := assistantTurn(session.ID, seq+1, reply)
+	prevTurns := session.UserTurns
+	updates := map[string]interface{}{"user_turns": prevTurns + 1}
+	if reply.Done || (maxTurns > 0 && prevTurns+1 >= maxTurns) {
+		updates["status"] = models.DialogueStatusCompleted
+		updates["completed_at"] = now
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(session).Where("status = ? AND user_turns = ?", models.DialogueStatusActive, prevTurns).Updates(updates)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrTurnConflict
+		}
+		if err := tx.Create(&userTurn).Error; err != nil {
+			return err
+		}
+		return tx.Create(&replyTurn).Error
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &userTurn, &replyTurn, nil
+}
+
+// Finish 学习者主动结束对话
+func Finish(db *gorm.DB, session *models.DialogueSession, now time.Time) error {
+	if session.Status != models.DialogueStatusActive {
+		return ErrSessionFinished
+	}
+	session.Status, session.CompletedAt = models.DialogueStatusCompleted, &now
+	return db.Model(session).Select("status", "completed_at").Updates(session).Error
+}
+
+// assistantTurn 由引擎回复生成 AI 的一轮发言
+func assistantTurn(sessionID uint, seq int, reply *Reply) models.DialogueTurn {
+	return models.DialogueTurn{
+		SessionID:   sessionID,
+		Seq:         seq,
+		Role:        models.DialogueRoleAssistant,
+		Text:        reply.Text,
+		Translation: reply.Translation,
+		Engine:      reply.Engine,
+	}
+}
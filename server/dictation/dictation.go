@@ -0,0 +1,244 @@
+package dictation
+
+import (
+	"math"
+	"slices"
+	"strings"
+
+	"server/cloze"
+)
+
+// 差异类型
+const (
+	TypeCorrect    = "correct"    // 听写正确
+	TypeMissing    = "missing"    // 漏写
+	TypeExtra      = "extra"      // 多写
+	TypeMisspelled = "misspelled" // 拼写错误
+)
+
+// misspelledCredit 拼写错误按半个词计分：听出来了但没写对
+const misspelledCredit = 0.5
+
+// Diff 一处差异，Expected 为原文，Actual 为用户输入
+type Diff struct {
+	Type     string `json:"type"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// Result 听写批改结果，计数以展开缩写后的单词为单位
+type Result struct {
+	Diffs      []Diff  `json:"diffs"`
+	Correct    int     `json:"correct"`
+	Missing    int     `json:"missing"`
+	Extra      int     `json:"extra"`
+	Misspelled int     `json:"misspelled"`
+	Accuracy   float64 `json:"accuracy"` // 0-1
+}
+
+// word 展开后的单词，src 为所属原始单词的下标
+type word struct {
+	text string
+	src  int
+}
+
+// text 切分后的文本
+type text struct {
+	originals []string // 原始单词
+	parts     []int    // 每个原始单词展开后的单词数
+	words     []word
+}
+
+// Grade 比较原文与用户听写的内容
+// 忽略大小写和标点，缩写与弱读形式（wanna/want to、gonna、kinda、'cause 等）视为相同
+func Grade(original, input string) *Result {
+	ref, hyp := split(original), split(input)
+	ops := align(ref.words, hyp.words)
+
+	result := &Result{Diffs: []Diff{}}
+	for _, g := range group(ops, ref, hyp) {
+		d := Diff{Type: g.typ}
+		if len(g.ref) > 0 {
+			d.Expected = ref.display(g.ref)
+		}
+		if len(g.hyp) > 0 {
+			d.Actual = hyp.display(g.hyp)
+		}
+		result.Diffs = append(result.Diffs, d)
+	}
+	for _, op := range ops {
+		switch op.typ {
+		case TypeCorrect:
+			result.Correct++
+		case TypeMissing:
+			result.Missing++
+		case TypeExtra:
+			result.Extra++
+		case TypeMisspelled:
+			result.Misspelled++
+		}
+	}
+
+	if total := len(ref.words) + result.Extra; total > 0 {
+		score := (float64(result.Correct) + misspelledCredit*float64(result.Misspelled)) / float64(total)
+		result.Accuracy = math.Round(score*1000) / 1000
+	}
+	return result
+}
+
+// split 切分文本并展开缩写和弱读形式
+func split(s string) *text {
+	t := &text{}
+	for _, tok := range cloze.Tokenize(s) {
+		if !tok.Word {
+			continue
+		}
+		src := len(t.originals)
+		t.originals = append(t.originals, tok.Text)
+		expanded := expand(tok.Text)
+		t.parts = append(t.parts, len(expanded))
+		for _, w := range expanded {
+			t.words = append(t.words, word{text: w, src: src})
+		}
+	}
+	return t
+}
+
+// display 生成一组单词的显示文本
+// 覆盖完整的原始单词时显示原文写法，否则显示展开后的形式（如 don't 只写了 do 时缺少的 not）
+func (t *text) display(idx []int) string {
+	count := make(map[int]int)
+	for _, i := range idx {
+		count[t.words[i].src]++
+	}
+	whole := true
+	for src, n := range count {
+		if n != t.parts[src] {
+			whole = false
+			break
+		}
+	}
+
+	var out []string
+	if whole {
+		for _, i := range idx {
+			src := t.words[i].src
+			if len(out) == 0 || i == 0 || t.words[i-1].src != src {
+				out = append(out, t.originals[src])
+			}
+		}
+	} else {
+		for _, i := range idx {
+			out = append(out, t.words[i].text)
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+// op 对齐后的一步操作，ref/hyp 为单词下标，-1 表示无
+type op struct {
+	typ      string
+	ref, hyp int
+}
+
+// align 以单词级编辑距离对齐原文与输入
+// 相同单词代价 0，拼写相近的替换代价 1，漏写和多写代价各 1；拼写不相近的替换拆为漏写加多写
+func align(ref, hyp []word) []op {
+	n, m := len(ref), len(hyp)
+	cost := make([][]int, n+1)
+	for i := range cost {
+		cost[i] = make([]int, m+1)
+		cost[i][0] = i
+	}
+	for j := 0; j <= m; j++ {
+		cost[0][j] = j
+	}
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= m; j++ {
+			best := min(cost[i-1][j], cost[i][j-1]) + 1
+			if sub := substituteCost(ref[i-1].text, hyp[j-1].text); sub >= 0 {
+				best = min(best, cost[i-1][j-1]+sub)
+			}
+			cost[i][j] = best
+		}
+	}
+
+	var ops []op
+	i, j := n, m
+	for i > 0 || j > 0 {
+		if i > 0 && j > 0 {
+			sub := substituteCost(ref[i-1].text, hyp[j-1].text)
+			if sub == 0 && cost[i][j] == cost[i-1][j-1] {
+				ops = append(ops, op{TypeCorrect, i - 1, j - 1})
+				i, j = i-1, j-1
+				continue
+			}
+			if sub == 1 && cost[i][j] == cost[i-1][j-1]+1 {
+				ops = append(ops, op{TypeMisspelled, i - 1, j - 1})
+				i, j = i-1, j-1
+				continue
+			}
+		}
+		if i > 0 && cost[i][j] == cost[i-1][j]+1 {
+			ops = append(ops, op{TypeMissing, i - 1, -1})
+			i--
+			continue
+		}
+		ops = append(ops, op{TypeExtra, -1, j - 1})
+		j--
+	}
+	slices.Reverse(ops)
+	return ops
+}
+
+// substituteCost 替换代价：相同为 0，拼写相近为 1，否则返回 -1 表示不可替换
+func substituteCost(a, b string) int {
+	if equivalent(a, b) {
+		return 0
+	}
+	if similar(a, b) {
+		return 1
+	}
+	return -1
+}
+
+// diffGroup 合并后的差异，ref/hyp 为单词下标
+type diffGroup struct {
+	typ      string
+	ref, hyp []int
+}
+
+// group 合并属于同一原始单词的连续操作
+// 如 wanna 展开为 want to 后与原文的 want、to 各匹配一次，合并后显示为一处 want to → wanna
+func group(ops []op, ref, hyp *text) []diffGroup {
+	var groups []diffGroup
+	var last op
+	for k, o := range ops {
+		if k > 0 && o.typ == last.typ && o.typ != TypeMisspelled && shareSource(last, o, ref, hyp) {
+			g := &groups[len(groups)-1]
+			if o.ref >= 0 {
+				g.ref = append(g.ref, o.ref)
+			}
+			if o.hyp >= 0 {
+				g.hyp = append(g.hyp, o.hyp)
+			}
+		} else {
+			g := diffGroup{typ: o.typ}
+			if o.ref >= 0 {
+				g.ref = []int{o.ref}
+			}
+			if o.hyp >= 0 {
+				g.hyp = []int{o.hyp}
+			}
+			groups = append(groups, g)
+		}
+		last = o
+	}
+	return groups
+}
+
+// shareSource 判断两步操作是否来自同一个原始单词
+func shareSource(a, b op, ref, hyp *text) bool {
+	return a.ref >= 0 && b.ref >= 0 && ref.words[a.ref].src == ref.words[b.ref].src ||
+		a.hyp >= 0 && b.hyp >= 0 && hyp.words[a.hyp].src == hyp.words[b.hyp].src
+}
@@ -0,0 +1,160 @@
+package dictation
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGrade(t *testing.T) {
+	tests := []struct {
+		name     string
+		original string
+		input    string
+		diffs    []Diff
+		accuracy float64
+	}{
+		{
+			name:     "reduction matches full form",
+			original: "I want to go home.",
+			input:    "I wanna go home",
+			diffs: []Diff{
+				{TypeCorrect, "I", "I"},
+				{TypeCorrect, "want to", "wanna"},
+				{TypeCorrect, "go", "go"},
+				{TypeCorrect, "home", "home"},
+			},
+			accuracy: 1,
+		},
+		{
+			name:     "contractions and aliases",
+			original: "I'm gonna leave 'cause it's late, y'know.",
+			input:    "i am going to leave because it is late y'know",
+			accuracy: 1,
+		},
+		{
+			name:     "case punctuation and missing apostrophe",
+			original: "Hello, World! Don't worry.",
+			input:    "hello world dont worry",
+			accuracy: 1,
+		},
+		{
+			name:     "dunno",
+			original: "I don't know.",
+			input:    "I dunno",
+			accuracy: 1,
+		},
+		{
+			name:     "missing word",
+			original: "I want to go home.",
+			input:    "I want go home",
+			diffs: []Diff{
+				{TypeCorrect, "I", "I"},
+				{TypeCorrect, "want", "want"},
+				{TypeMissing, "to", ""},
+				{TypeCorrect, "go", "go"},
+				{TypeCorrect, "home", "home"},
+			},
+			accuracy: 0.8,
+		},
+		{
+			name:     "extra word",
+			original: "Go home.",
+			input:    "go to home",
+			diffs: []Diff{
+				{TypeCorrect, "Go", "go"},
+				{TypeExtra, "", "to"},
+				{TypeCorrect, "home", "home"},
+			},
+			accuracy: 0.667,
+		},
+		{
+			name:     "misspelled word gets half credit",
+			original: "What a beautiful day.",
+			input:    "what a beatiful day",
+			diffs: []Diff{
+				{TypeCorrect, "What", "what"},
+				{TypeCorrect, "a", "a"},
+				{TypeMisspelled, "beautiful", "beatiful"},
+				{TypeCorrect, "day", "day"},
+			},
+			accuracy: 0.875,
+		},
+		{
+			name:     "partial contraction shows expanded word",
+			original: "I don't know.",
+			input:    "I do know",
+			diffs: []Diff{
+				{TypeCorrect, "I", "I"},
+				{TypeCorrect, "do", "do"},
+				{TypeMissing, "not", ""},
+				{TypeCorrect, "know", "know"},
+			},
+			accuracy: 0.75,
+		},
+		{
+			name:     "unrelated word is missing plus extra",
+			original: "cat",
+			input:    "dog",
+			diffs: []Diff{
+				{TypeExtra, "", "dog"},
+				{TypeMissing, "cat", ""},
+			},
+			accuracy: 0,
+		},
+		{
+			name:     "empty input",
+			original: "Hello world",
+			input:    "",
+			diffs: []Diff{
+				{TypeMissing, "Hello", ""},
+				{TypeMissing, "world", ""},
+			},
+			accuracy: 0,
+		},
+		{
+			name:     "both empty",
+			diffs:    []Diff{},
+			accuracy: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Grade(tt.original, tt.input)
+			if r.Accuracy != tt.accuracy {
+				t.Errorf("accuracy = %v, want %v (%+v)", r.Accuracy, tt.accuracy, r.Diffs)
+			}
+			if tt.diffs != nil && !reflect.DeepEqual(r.Diffs, tt.diffs) {
+				t.Errorf("diffs = %+v, want %+v", r.Diffs, tt.diffs)
+			}
+		})
+	}
+}
+
+func TestGradeCounts(t *testing.T) {
+	r := Grade("She's gonna call you tomorrow", "she has going to cal you")
+	want := Result{Correct: 5, Missing: 1, Misspelled: 1}
+	if r.Correct != want.Correct || r.Missing != want.Missing || r.Extra != want.Extra || r.Misspelled != want.Misspelled {
+		t.Errorf("counts = %d/%d/%d/%d, want %d/%d/%d/%d", r.Correct, r.Missing, r.Extra, r.Misspelled,
+			want.Correct, want.Missing, want.Extra, want.Misspelled)
+	}
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		word string
+		want []string
+	}{
+		{"Wanna", []string{"want", "to"}},
+		{"can't", []string{"can", "not"}},
+		{"they’re", []string{"they", "are"}},
+		{"isnt", []string{"is", "not"}},
+		{"he's", []string{"he", "'s"}},
+		{"well-known", []string{"well", "known"}},
+		{"its", []string{"its"}},
+	}
+	for _, tt := range tests {
+		if got := expand(tt.word); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expand(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
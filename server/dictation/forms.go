@@ -0,0 +1,166 @@
+package dictation
+
+import (
+	"slices"
+	"strings"
+)
+
+// reductions 口语弱读和连读的书面形式，展开为标准写法
+var reductions = map[string][]string{
+	"wanna":   {"want", "to"},
+	"gonna":   {"going", "to"},
+	"gotta":   {"got", "to"},
+	"hafta":   {"have", "to"},
+	"hasta":   {"has", "to"},
+	"oughta":  {"ought", "to"},
+	"kinda":   {"kind", "of"},
+	"sorta":   {"sort", "of"},
+	"outta":   {"out", "of"},
+	"lotta":   {"lot", "of"},
+	"lotsa":   {"lots", "of"},
+	"gimme":   {"give", "me"},
+	"lemme":   {"let", "me"},
+	"gotcha":  {"got", "you"},
+	"dunno":   {"do", "not", "know"},
+	"whaddya": {"what", "do", "you"},
+	"didja":   {"did", "you"},
+	"y'all":   {"you", "all"},
+	"c'mon":   {"come", "on"},
+	"let's":   {"let", "us"},
+	"cannot":  {"can", "not"},
+	"can't":   {"can", "not"},
+	"won't":   {"will", "not"},
+	"shan't":  {"shall", "not"},
+}
+
+// unapostrophized 漏写撇号的缩写，只登记不会与其他单词混淆的形式（不含 its、ill、wont 等）
+var unapostrophized = toMap(`im dont doesnt didnt isnt arent wasnt werent cant couldnt shouldnt wouldnt
+havent hasnt hadnt youre theyre youve theyve weve youll theyll shes hes thats whats theres`)
+
+// toMap 将空白分隔的缩写登记为 去撇号形式 → 缩写 的映射
+func toMap(words string) map[string]string {
+	m := make(map[string]string)
+	for _, w := range strings.Fields(words) {
+		for _, c := range contractions {
+			bare := strings.ReplaceAll(c.suffix, "'", "")
+			if stem, ok := strings.CutSuffix(w, bare); ok && stem != "" {
+				m[w] = stem + c.suffix
+				break
+			}
+		}
+	}
+	return m
+}
+
+// contractions 缩写词尾及其完整形式
+// 's 和 'd 有歧义（is/has、would/had），保留原形，由 equivalent 判断
+var contractions = []struct {
+	suffix string
+	full   string
+}{
+	{"n't", "not"},
+	{"'m", "am"},
+	{"'re", "are"},
+	{"'ve", "have"},
+	{"'ll", "will"},
+	{"'d", "'d"},
+	{"'s", "'s"},
+}
+
+// aliases 单个单词的等价写法，统一为同一形式后比较
+// 分词会去掉 'cause、'em 开头的撇号，因此这里按去掉撇号后的形式登记
+var aliases = map[string]string{
+	"cause": "because",
+	"cos":   "because",
+	"coz":   "because",
+	"cuz":   "because",
+	"ya":    "you",
+	"em":    "them",
+	"okay":  "ok",
+	"till":  "until",
+	"til":   "until",
+}
+
+// ambiguous 有歧义的缩写词尾可匹配的完整形式
+var ambiguous = map[string][]string{
+	"'s": {"is", "has"},
+	"'d": {"would", "had"},
+}
+
+// expand 将单词统一为小写并展开缩写、弱读和连字符
+func expand(w string) []string {
+	w = strings.ToLower(strings.ReplaceAll(w, "’", "'"))
+	var out []string
+	for _, piece := range strings.Split(w, "-") {
+		if piece == "" {
+			continue
+		}
+		if c, ok := unapostrophized[piece]; ok {
+			piece = c
+		}
+		if full, ok := reductions[piece]; ok {
+			out = append(out, full...)
+			continue
+		}
+		out = append(out, expandContraction(piece)...)
+	}
+	return out
+}
+
+// expandContraction 拆分缩写，如 don't → do not、they're → they are
+func expandContraction(w string) []string {
+	for _, c := range contractions {
+		if stem, ok := strings.CutSuffix(w, c.suffix); ok && stem != "" {
+			return []string{stem, c.full}
+		}
+	}
+	return []string{w}
+}
+
+// equivalent 判断两个展开后的单词是否视为相同
+func equivalent(a, b string) bool {
+	a, b = alias(a), alias(b)
+	if a == b {
+		return true
+	}
+	return slices.Contains(ambiguous[a], b) || slices.Contains(ambiguous[b], a)
+}
+
+// alias 返回单词的统一写法
+func alias(w string) string {
+	if v, ok := aliases[w]; ok {
+		return v
+	}
+	return w
+}
+
+// similar 判断两个单词是否拼写相近，即编辑距离不超过较长单词长度的三分之一（至少为 1）
+func similar(a, b string) bool {
+	ra, rb := []rune(a), []rune(b)
+	limit := max(1, max(len(ra), len(rb))/3)
+	if d := len(ra) - len(rb); d > limit || -d > limit {
+		return false
+	}
+	return levenshtein(ra, rb) <= limit
+}
+
+// levenshtein 字符级编辑距离
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
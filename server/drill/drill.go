@@ -0,0 +1,243 @@
+package drill
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"server/models"
+)
+
+const (
+	// DefaultItems 每次训练的默认题数
+	DefaultItems = 10
+	// MaxItems 每次训练的题数上限
+	MaxItems = 30
+	// minWeight 错误率权重下限，掌握得再好的现象也偶尔安排复习
+	minWeight = 0.1
+	// weightPerItem 每道题对听力分项水平的影响，单次训练最多 maxWeight
+	weightPerItem = 0.03
+	maxWeight     = 0.3
+)
+
+// 训练错误
+var (
+	ErrBankTooSmall    = errors.New("训练题库不足")
+	ErrSessionFinished = errors.New("训练已结束")
+	ErrItemMismatch    = errors.New("题目不属于当前训练或已作答")
+)
+
+// ErrorRate 现象的平滑错误率
+// 按 (错误+1)/(作答+2) 计算，没练过的现象为 0.5，练得越多越接近真实错误率
+func ErrorRate(stat models.UserPhenomenonStat) float64 {
+	return float64(stat.Errors+1) / float64(stat.Attempts+2)
+}
+
+// LoadStats 获取用户在各现象上的练习统计，按现象代码索引
+func LoadStats(db *gorm.DB, userID uint) (map[string]models.UserPhenomenonStat, error) {
+	var rows []models.UserPhenomenonStat
+	if err := db.Where("user_id = ?", userID).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	stats := make(map[string]models.UserPhenomenonStat, len(rows))
+	for _, r := range rows {
+		stats[r.Phenomenon] = r
+	}
+	return stats, nil
+}
+
+// availableItems 仍可使用的题目：题目本身、所属句子和材料都未被删除
+// 材料重新导入句子时，文本或时间变化的句子上的题目由 listening.ReplaceSentences 删除
+func availableItems(db *gorm.DB) *gorm.DB {
+	return db.Model(&models.DrillItem{}).
+		Joins("JOIN listening_sentences ON listening_sentences.id = drill_items.sentence_id AND listening_sentences.deleted_at IS NULL").
+		Joins("JOIN listening_materials ON listening_materials.id = listening_sentences.material_id AND listening_materials.deleted_at IS NULL")
+}
+
+// CountItems 统计各现象可用的题数
+func CountItems(db *gorm.DB) (map[string]int, error) {
+	var rows []struct {
+		Phenomenon string
+		Count      int
+	}
+	if err := availableItems(db).Select("drill_items.phenomenon, COUNT(*) AS count").
+		Group("drill_items.phenomenon").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(rows))
+	for _, r := range rows {
+		counts[r.Phenomenon] = r.Count
+	}
+	return counts, nil
+}
+
+// Schedule 为训练会话抽题
+// 按用户在各现象上的错误率分配题数，错误率越高的现象题越多；
+// 同一现象内优先抽取用户没答对过的题目，最后整体打乱顺序
+func Schedule(db *gorm.DB, userID uint, category string, n int) ([]models.DrillSessionItem, error) {
+	var phenomena []string
+	for _, p := range models.SpeechPhenomena {
+		if category == "" || p.Category == category {
+			phenomena = append(phenomena, p.Code)
+		}
+	}
+
+	counts, err := CountItems(db)
+	if err != nil {
+		return nil, err
+	}
+	available := make(map[string]int, len(phenomena))
+	total := 0
+	for _, p := range phenomena {
+		available[p] = counts[p]
+		total += counts[p]
+	}
+	if total == 0 {
+		return nil, ErrBankTooSmall
+	}
+
+	stats, err := LoadStats(db, userID)
+	if err != nil {
+		return nil, err
+	}
+	weights := make(map[string]float64, len(phenomena))
+	for _, p := range phenomena {
+		weights[p] = max(ErrorRate(stats[p]), minWeight)
+	}
+	alloc := allocate(phenomena, weights, available, min(n, total))
+
+	answered := db.Table("drill_session_items").Select("drill_session_items.item_id").
+		Joins("JOIN drill_sessions ON drill_sessions.id = drill_session_items.session_id").
+		Where("drill_sessions.user_id = ? AND drill_session_items.correct = ?", userID, true)
+
+	var items []models.DrillSessionItem
+	for _, p := range phenomena {
+		if alloc[p] == 0 {
+			continue
+		}
+		var rows []struct {
+			models.DrillItem
+			AudioURL string
+		}
+		err := availableItems(db).Select("drill_items.*, listening_materials.audio_url").
+			Where("drill_items.phenomenon = ?", p).
+			Order(clause.OrderBy{Expression: clause.Expr{
+				SQL: "CASE WHEN drill_items.id IN (?) THEN 1 ELSE 0 END, RANDOM()", Vars: []any{answered}, WithoutParentheses: true,
+			}}).
+			Limit(alloc[p]).Scan(&rows).Error
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			items = append(items, models.DrillSessionItem{
+				ItemID:       r.ID,
+				Phenomenon:   r.Phenomenon,
+				Category:     r.Category,
+				AudioURL:     r.AudioURL,
+				StartTime:    r.StartTime,
+				EndTime:      r.EndTime,
+				Options:      r.Options,
+				CorrectIndex: r.CorrectIndex,
+			})
+		}
+	}
+
+	rand.Shuffle(len(items), func(i, j int) { items[i], items[j] = items[j], items[i] })
+	for i := range items {
+		items[i].Seq = i + 1
+	}
+	return items, nil
+}
+
+// allocate 按权重把 n 道题分配给各现象，每次把下一道题分给 权重/(已分配+1) 最大的现象，
+// 已分配数不超过该现象的可用题数
+func allocate(phenomena []string, weights map[string]float64, available map[string]int, n int) map[string]int {
+	alloc := make(map[string]int, len(phenomena))
+	for range n {
+		best, bestScore := "", -1.0
+		for _, p := range phenomena {
+			if alloc[p] >= available[p] {
+				continue
+			}
+			if score := weights[p] / float64(alloc[p]+1); score > bestScore {
+				best, bestScore = p, score
+			}
+		}
+		if best == "" {
+			break
+		}
+		alloc[best]++
+	}
+	return alloc
+}
+
+// RecordAnswer 更新用户在该现象上的练习统计
+func RecordAnswer(tx *gorm.DB, userID uint, phenomenon string, correct bool, now time.Time) error {
+	errs := 0
+	if !correct {
+		errs = 1
+	}
+	stat := models.UserPhenomenonStat{
+		UserID:         userID,
+		Phenomenon:     phenomenon,
+		Attempts:       1,
+		Errors:         errs,
+		LastPracticeAt: now,
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "phenomenon"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"attempts":         gorm.Expr("attempts + 1"),
+			"errors":           gorm.Expr("errors + ?", errs),
+			"last_practice_at": now,
+			"updated_at":       now,
+		}),
+	}).Create(&stat).Error
+}
+
+// CategoryScore 一次训练在某个听力分项上的表现
+type CategoryScore struct {
+	Items   int `json:"items"`
+	Correct int `json:"correct"`
+	Score   int `json:"score"` // 本次训练得分 (0-100)
+}
+
+// Score 按听力分项统计训练结果
+func Score(items []models.DrillSessionItem) map[string]CategoryScore {
+	scores := make(map[string]CategoryScore)
+	for _, it := range items {
+		s := scores[it.Category]
+		s.Items++
+		if it.Correct != nil && *it.Correct {
+			s.Correct++
+		}
+		scores[it.Category] = s
+	}
+	for k, s := range scores {
+		s.Score = int(math.Round(100 * float64(s.Correct) / float64(s.Items)))
+		scores[k] = s
+	}
+	return scores
+}
+
+// ApplyToProfile 将训练结果计入听力分项水平
+// 按题数加权平滑更新，每题权重 weightPerItem，单次最多 maxWeight，避免一次训练大幅改变测评结果。
+// 综合分数和等级仍以听力测试为准，不在这里更新
+func ApplyToProfile(p *models.ListeningProfile, scores map[string]CategoryScore) {
+	blend := func(old int, s CategoryScore) int {
+		w := min(weightPerItem*float64(s.Items), maxWeight)
+		return int(math.Round((1-w)*float64(old) + w*float64(s.Score)))
+	}
+	if s, ok := scores[models.PhenomenonLiaison]; ok {
+		p.LiaisonScore = blend(p.LiaisonScore, s)
+	}
+	if s, ok := scores[models.PhenomenonWeakForm]; ok {
+		p.WeakFormScore = blend(p.WeakFormScore, s)
+	}
+	if s, ok := scores[models.PhenomenonElision]; ok {
+		p.ElisionScore = blend(p.ElisionScore, s)
+	}
+}
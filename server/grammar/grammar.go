@@ -0,0 +1,430 @@
+package grammar
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// 检查规则
+const (
+	RuleArticle   = "article"   // 冠词
+	RuleTense     = "tense"     // 时态与动词形式
+	RuleAgreement = "agreement" // 主谓一致
+)
+
+// Issue 一处语法问题
+// Start、End 为问题片段在原文中的字符位置（按 Unicode 字符计，左闭右开）
+type Issue struct {
+	Rule       string `json:"rule"`
+	Start      int    `json:"start"`
+	End        int    `json:"end"`
+	Text       string `json:"text"`       // 原文片段
+	Suggestion string `json:"suggestion"` // 建议改为，为空表示删除
+	Message    string `json:"message"`    // 说明
+}
+
+// token 切分出的单词
+type token struct {
+	text     string // 原文
+	lower    string // 小写，弯引号统一为直引号
+	start    int    // 字符位置
+	end      int
+	sentence int  // 所在句子序号
+	boundary bool // 与前一个单词之间有标点，即位于句子或分句开头
+}
+
+// Check 检查口语发言中的常见语法问题
+// 基于规则，只覆盖冠词 a/an、情态动词和助动词后的动词形式、过去时间状语与动词时态、代词主语的主谓一致，
+// 宁可漏报也不误报
+func Check(text string) []Issue {
+	tokens := tokenize(text)
+	c := &checker{tokens: tokens, flagged: map[int]bool{}}
+	c.checkArticles()
+	c.checkVerbForms()
+	c.checkPastTime()
+	c.checkAgreement()
+
+	sort.Slice(c.issues, func(i, j int) bool { return c.issues[i].Start < c.issues[j].Start })
+	return c.issues
+}
+
+// Apply 按建议改正原文
+func Apply(text string, issues []Issue) string {
+	runes := []rune(text)
+	var b strings.Builder
+	pos := 0
+	for _, is := range issues {
+		if is.Start < pos || is.End > len(runes) {
+			continue
+		}
+		b.WriteString(string(runes[pos:is.Start]))
+		b.WriteString(is.Suggestion)
+		pos = is.End
+		if is.Suggestion == "" {
+			// 删除单词时一并删除其后的空格
+			for pos < len(runes) && runes[pos] == ' ' {
+				pos++
+			}
+		}
+	}
+	b.WriteString(string(runes[pos:]))
+	return b.String()
+}
+
+// tokenize 按字母、数字和撇号切分单词，记录句子和分句边界
+func tokenize(text string) []token {
+	var tokens []token
+	runes := []rune(text)
+	sentence := 0
+	boundary := true
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if !isWordRune(r) {
+			switch r {
+			case '.', '!', '?':
+				sentence++
+				boundary = true
+			case ',', ';', ':', '"', '(', ')', '-':
+				boundary = true
+			}
+			i++
+			continue
+		}
+		j := i
+		for j < len(runes) && isWordRune(runes[j]) {
+			j++
+		}
+		word := string(runes[i:j])
+		lower := strings.ToLower(strings.ReplaceAll(word, "’", "'"))
+		tokens = append(tokens, token{text: word, lower: lower, start: i, end: j, sentence: sentence, boundary: boundary})
+		boundary = false
+		i = j
+	}
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '\'' || r == '’'
+}
+
+// checker 一次检查的状态，每个单词最多报告一处问题
+type checker struct {
+	tokens  []token
+	issues  []Issue
+	flagged map[int]bool
+}
+
+// report 记录第 i 个单词的问题，suggestion 按原文的首字母大小写调整
+func (c *checker) report(i int, rule, suggestion, message string) {
+	if c.flagged[i] {
+		return
+	}
+	c.flagged[i] = true
+	t := c.tokens[i]
+	if suggestion != "" && unicode.IsUpper([]rune(t.text)[0]) {
+		r := []rune(suggestion)
+		r[0] = unicode.ToUpper(r[0])
+		suggestion = string(r)
+	}
+	c.issues = append(c.issues, Issue{Rule: rule, Start: t.start, End: t.end, Text: t.text, Suggestion: suggestion, Message: message})
+}
+
+// next 第 i 个单词之后、同一分句内的下一个单词，跳过 not、also 等副词
+func (c *checker) next(i int) (int, bool) {
+	for j := i + 1; j < len(c.tokens); j++ {
+		if c.tokens[j].boundary {
+			return 0, false
+		}
+		if !skippedAdverbs[c.tokens[j].lower] {
+			return j, true
+		}
+	}
+	return 0, false
+}
+
+// checkArticles a/an 与后一个单词的读音是否匹配，不可数名词前不用 a/an
+func (c *checker) checkArticles() {
+	for i, t := range c.tokens {
+		if t.lower != "a" && t.lower != "an" {
+			continue
+		}
+		if t.text == "A" && !t.boundary {
+			continue // 句中的大写 A 多为字母，如 plan A
+		}
+		if i+1 >= len(c.tokens) || c.tokens[i+1].boundary {
+			continue
+		}
+		next := c.tokens[i+1]
+		if functionWords[next.lower] || isAcronym(next.text) || unicode.IsDigit([]rune(next.text)[0]) {
+			continue
+		}
+		if uncountableNouns[next.lower] && (i+2 >= len(c.tokens) || c.tokens[i+2].boundary || afterNoun[c.tokens[i+2].lower]) {
+			c.report(i, RuleArticle, "", next.lower+" 是不可数名词，前面不用 a/an")
+			continue
+		}
+		vowel := vowelSound(next.lower)
+		if t.lower == "a" && vowel {
+			c.report(i, RuleArticle, "an", next.text+" 以元音音素开头，前面用 an")
+		} else if t.lower == "an" && !vowel {
+			c.report(i, RuleArticle, "a", next.text+" 以辅音音素开头，前面用 a")
+		}
+	}
+}
+
+// checkVerbForms 情态动词、助动词 do 和不定式 to 后应使用动词原形
+func (c *checker) checkVerbForms() {
+	for i, t := range c.tokens {
+		isModal := modals[t.lower] || strings.HasSuffix(t.lower, "'ll")
+		isDo := doAux[t.lower]
+		if !isModal && !isDo && t.lower != "to" {
+			continue
+		}
+		j, ok := c.next(i)
+		if !ok {
+			continue
+		}
+		v := c.tokens[j].lower
+		var base string
+		switch {
+		case v == "is" || v == "are" || v == "am" || v == "was" || v == "were":
+			if isDo {
+				continue // do 不与 be 连用，多为识别或断句问题，不作判断
+			}
+			base = "be"
+		case verbsByPast[v] != nil && !ambiguousPast[v] && verbsByBase[v] == nil:
+			base = verbsByPast[v].base
+		case verbsByParticiple[v] != nil && !ambiguousPast[v] && verbsByBase[v] == nil && v != "been":
+			base = verbsByParticiple[v].base
+		case isModal && verbsByThird[v] != nil:
+			base = verbsByThird[v].base
+		case isDo && (v == "goes" || v == "has" || v == "does"):
+			base = verbsByThird[v].base
+		default:
+			continue
+		}
+		// 不定式 to 后的过去分词若接名词，多为形容词用法，如 access to paid parking
+		if !isModal && !isDo && base != "be" && c.nounFollows(j) {
+			continue
+		}
+		var message string
+		switch {
+		case isModal:
+			message = "情态动词 " + t.lower + " 后用动词原形 " + base
+		case isDo:
+			message = "助动词 " + t.lower + " 后用动词原形 " + base
+		default:
+			message = "不定式 to 后用动词原形 " + base
+		}
+		c.report(j, RuleTense, base, message)
+	}
+}
+
+// nounFollows 第 j 个单词之后、同一分句内是否紧跟名词性的词
+// 动词后常见的宾语、状语开头词（冠词、代词、介词、副词等）不算
+func (c *checker) nounFollows(j int) bool {
+	if j+1 >= len(c.tokens) || c.tokens[j+1].boundary {
+		return false
+	}
+	next := c.tokens[j+1].lower
+	return !objectStarters[next] && !skippedAdverbs[next]
+}
+
+// checkAgreement 代词主语与一般现在时动词的主谓一致
+func (c *checker) checkAgreement() {
+	for i, t := range c.tokens {
+		third := thirdSingular[t.lower]
+		if !third && !otherSubjects[t.lower] {
+			continue
+		}
+		if !c.isSubject(i) {
+			continue
+		}
+		if i+1 >= len(c.tokens) || c.tokens[i+1].boundary {
+			continue
+		}
+		v := c.tokens[i+1].lower
+		if suggestion, ok := agreementFix(t.lower, v, i > 0 && (c.tokens[i-1].lower == "if" || c.tokens[i-1].lower == "wish")); ok {
+			c.report(i+1, RuleAgreement, suggestion, agreementMessage(t.lower, suggestion))
+		}
+	}
+}
+
+// isSubject 第 i 个代词是否为主语
+// he/she/we/they/I 只作主语，但在 does he go、let it go 这类结构中后面接动词原形；it/you 还可作宾语，只在分句开头判断
+func (c *checker) isSubject(i int) bool {
+	t := c.tokens[i]
+	if t.boundary || i == 0 {
+		return true
+	}
+	prev := c.tokens[i-1].lower
+	if questionAux[prev] || modals[prev] || doAux[prev] {
+		return false
+	}
+	if t.lower == "it" || t.lower == "you" {
+		return clauseStarters[prev]
+	}
+	return true
+}
+
+// agreementFix 主语 subject 后的动词 v 不一致时返回改正后的形式
+func agreementFix(subject, v string, subjunctive bool) (string, bool) {
+	if thirdSingular[subject] {
+		switch v {
+		case "am", "are":
+			return "is", true
+		case "were":
+			return "was", !subjunctive
+		case "don't":
+			return "doesn't", true
+		case "do", "have", "be":
+			return verbsByBase[v].third, true
+		}
+		if f := verbsByBase[v]; f != nil && f.past != f.base {
+			return f.third, true
+		}
+		return "", false
+	}
+
+	switch v {
+	case "is", "am", "are":
+		want := "are"
+		if subject == "i" {
+			want = "am"
+		}
+		return want, v != want
+	case "was":
+		return "were", subject != "i"
+	case "were":
+		return "was", subject == "i" && !subjunctive
+	case "doesn't":
+		return "don't", true
+	}
+	if f := verbsByThird[v]; f != nil {
+		return f.base, true
+	}
+	return "", false
+}
+
+func agreementMessage(subject, fixed string) string {
+	if subject != "i" {
+		return "主语 " + subject + " 后应使用 " + fixed
+	}
+	return "主语 I 后应使用 " + fixed
+}
+
+// checkPastTime 分句中有 yesterday、ago、last week 等过去时间时，代词主语后的一般现在时动词应改为过去式
+// 时间状语与动词之间隔着标点或连词、关系词时多属另一个分句（如 I go there every day but yesterday I stayed home），不作判断
+func (c *checker) checkPastTime() {
+	var markers []int
+	for i, t := range c.tokens {
+		if pastMarkers[t.lower] || (t.lower == "last" && i+1 < len(c.tokens) && lastPeriods[c.tokens[i+1].lower]) {
+			markers = append(markers, i)
+		}
+	}
+	if len(markers) == 0 {
+		return
+	}
+
+	for i, t := range c.tokens {
+		if (!thirdSingular[t.lower] && !otherSubjects[t.lower]) || !c.isSubject(i) {
+			continue
+		}
+		if i+1 >= len(c.tokens) || c.tokens[i+1].boundary {
+			continue
+		}
+		j := i + 1
+		if !c.sameClause(i, j, markers) {
+			continue
+		}
+		v := c.tokens[j].lower
+		var fixed string
+		switch v {
+		case "am", "is":
+			fixed = "was"
+		case "are":
+			fixed = "were"
+		case "don't", "doesn't":
+			fixed = "didn't"
+		default:
+			f := verbsByBase[v]
+			if f == nil {
+				f = verbsByThird[v]
+			}
+			// think、need、have 等动词常接从句或不定式，过去时间多修饰后面的动作
+			if f == nil || f.past == f.base || clauseVerbs[f.base] {
+				continue
+			}
+			fixed = f.past
+		}
+		c.report(j, RuleTense, fixed, "句中有表示过去的时间，"+v+" 应改为过去式 "+fixed)
+	}
+}
+
+// sameClause 主语 i、动词 j 是否与某个过去时间状语位于同一分句
+// 两者之间不能有标点，也不能有连词、关系词等引出新分句的词；
+// 句首的时间状语后可以有逗号，如 Yesterday, I go
+func (c *checker) sameClause(i, j int, markers []int) bool {
+	for _, m := range markers {
+		if c.tokens[m].sentence != c.tokens[i].sentence {
+			continue
+		}
+		from, to := m, i
+		if m > j {
+			from, to = j, m
+		}
+		separated := false
+		for k := from + 1; k <= to; k++ {
+			if c.tokens[k].boundary && !(k == i && c.fronted(m)) || (k < to && clauseStarters[c.tokens[k].lower]) {
+				separated = true
+				break
+			}
+		}
+		if !separated {
+			return true
+		}
+	}
+	return false
+}
+
+// fronted 第 m 个单词所在的时间状语是否单独位于分句开头，即同一分句中它前面没有主语
+func (c *checker) fronted(m int) bool {
+	for k := m; k >= 0; k-- {
+		t := c.tokens[k]
+		if thirdSingular[t.lower] || otherSubjects[t.lower] {
+			return false
+		}
+		if t.boundary {
+			return true
+		}
+	}
+	return true
+}
+
+// vowelSound 单词是否以元音音素开头
+func vowelSound(word string) bool {
+	for _, p := range consonantSoundPrefixes {
+		if strings.HasPrefix(word, p) {
+			return false
+		}
+	}
+	for _, p := range silentHPrefixes {
+		if strings.HasPrefix(word, p) {
+			return true
+		}
+	}
+	return word != "" && isVowel(word[0])
+}
+
+// isAcronym 全大写的缩写词，读音无法从拼写判断
+func isAcronym(word string) bool {
+	if len([]rune(word)) < 2 {
+		return false
+	}
+	for _, r := range word {
+		if unicode.IsLower(r) {
+			return false
+		}
+	}
+	return true
+}
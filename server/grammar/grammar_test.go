@@ -0,0 +1,71 @@
+package grammar
+
+import "testing"
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string // 按建议改正后的句子，与原文相同表示没有问题
+	}{
+		// 冠词
+		{"a before vowel", "I want a apple.", "I want an apple."},
+		{"an before consonant", "It was an big room.", "It was a big room."},
+		{"a before yoo sound", "She goes to a university in Boston.", "She goes to a university in Boston."},
+		{"an before un- prefix", "It is an unimportant detail.", "It is an unimportant detail."},
+		{"a before un- prefix", "It is a uninteresting film.", "It is an uninteresting film."},
+		{"an before silent h", "I waited a hour.", "I waited an hour."},
+		{"uncountable noun", "Can you give me an advice?", "Can you give me advice?"},
+		{"uncountable noun as modifier", "I read a news report.", "I read a news report."},
+		{"letter A", "We can try plan A instead.", "We can try plan A instead."},
+
+		// 情态动词、助动词和不定式后的动词形式
+		{"modal past", "I can went there tomorrow.", "I can go there tomorrow."},
+		{"modal third person", "She will goes home.", "She will go home."},
+		{"do auxiliary", "I didn't bought the ticket.", "I didn't buy the ticket."},
+		{"to past", "I want to bought a ticket.", "I want to buy a ticket."},
+		{"to past at end", "That is where I wanted to went.", "That is where I wanted to go."},
+		{"to participle adjective", "Do you have access to paid parking?", "Do you have access to paid parking?"},
+		{"to participle before noun", "We moved to used books.", "We moved to used books."},
+
+		// 主谓一致
+		{"third person", "He like coffee.", "He likes coffee."},
+		{"plural subject", "They is late.", "They are late."},
+		{"question word order", "Does he like tea?", "Does he like tea?"},
+		{"subjunctive", "If I were you, I would go.", "If I were you, I would go."},
+
+		// 过去时间与时态
+		{"yesterday", "I go to the park yesterday.", "I went to the park yesterday."},
+		{"fronted yesterday", "Yesterday, I go to the park.", "Yesterday, I went to the park."},
+		{"last week", "Last week she is sick.", "Last week she was sick."},
+		{"ago", "We visit them two days ago.", "We visited them two days ago."},
+		{"past negative", "I don't sleep well last night.", "I didn't sleep well last night."},
+		{"already past", "I went there yesterday.", "I went there yesterday."},
+		{"clause object verb", "I think I lost my wallet yesterday.", "I think I lost my wallet yesterday."},
+		{"infinitive object", "I need to change the booking I made yesterday.", "I need to change the booking I made yesterday."},
+		{"relative clause", "I have a reservation that I booked last week.", "I have a reservation that I booked last week."},
+		{"separate clause", "I go there every day but yesterday I stayed home.", "I go there every day but yesterday I stayed home."},
+		{"separated by comma", "I stayed home yesterday, I work tomorrow.", "I stayed home yesterday, I work tomorrow."},
+		{"other sentence", "I lost it yesterday. Now I need a new one.", "I lost it yesterday. Now I need a new one."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := Check(tt.text)
+			if got := Apply(tt.text, issues); got != tt.want {
+				t.Errorf("Check(%q) corrected to %q, want %q (issues: %+v)", tt.text, got, tt.want, issues)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	text := "Can you give me an advice?"
+	issues := Check(text)
+	if len(issues) != 1 {
+		t.Fatalf("Check(%q) = %+v, want 1 issue", text, issues)
+	}
+	is := issues[0]
+	if is.Rule != RuleArticle || is.Text != "an" || is.Start != 16 || is.End != 18 {
+		t.Errorf("issue = %+v, want article issue at [16,18)", is)
+	}
+}
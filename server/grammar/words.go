@@ -0,0 +1,222 @@
+package grammar
+
+import "strings"
+
+// verbForms 动词的各种形式
+type verbForms struct {
+	base, third, past, participle string
+}
+
+// irregularVerbs 常用不规则动词：原形 过去式 过去分词
+var irregularVerbs = `
+be was been
+have had had
+do did done
+go went gone
+get got got
+make made made
+take took taken
+come came come
+see saw seen
+know knew known
+think thought thought
+say said said
+tell told told
+give gave given
+find found found
+buy bought bought
+bring brought brought
+eat ate eaten
+drink drank drunk
+leave left left
+pay paid paid
+meet met met
+send sent sent
+spend spent spent
+sit sat sat
+stand stood stood
+speak spoke spoken
+write wrote written
+read read read
+run ran run
+feel felt felt
+keep kept kept
+sleep slept slept
+begin began begun
+choose chose chosen
+forget forgot forgotten
+lose lost lost
+put put put
+cut cut cut
+let let let
+set set set
+cost cost cost
+hit hit hit
+hurt hurt hurt
+shut shut shut
+hear heard heard
+hold held held
+sell sold sold
+teach taught taught
+catch caught caught
+understand understood understood
+win won won
+wear wore worn
+fly flew flown
+drive drove driven
+ride rode ridden
+swim swam swum
+sing sang sung
+break broke broken
+wake woke woken
+become became become
+build built built
+grow grew grown
+draw drew drawn
+throw threw thrown
+fall fell fallen
+lend lent lent
+mean meant meant
+show showed shown
+`
+
+// regularVerbs 常用规则动词原形
+var regularVerbs = `
+want need like love work live play watch call ask help start finish visit stay order use try
+study walk talk look move open close wait travel plan stop enjoy prefer arrive book cook clean
+change check learn miss hope believe decide remember agree answer carry worry happen listen
+join pick return reserve rent share follow improve manage offer prepare receive suggest apply
+deliver explain include seem hate add allow recommend complete attend confirm cancel count
+expect fix guess hurry invite jump kiss laugh mention notice pass prefer print pull push
+rain relax repeat reply save serve smile smell sound support taste thank touch train turn
+type visit wash wish
+`
+
+// doubledVerbs 变过去式时双写末尾辅音的动词
+var doubledVerbs = map[string]bool{"stop": true, "plan": true, "prefer": true, "shop": true, "drop": true, "travel": true}
+
+// ambiguousPast 过去式同时是常用名词、形容词或其他动词原形，不单独据此判断时态
+var ambiguousPast = map[string]bool{
+	"left": true, "found": true, "saw": true, "fell": true, "felt": true, "lay": true, "meant": true,
+	"read": true, "put": true, "cut": true, "let": true, "set": true, "cost": true, "hit": true, "hurt": true, "shut": true,
+	"come": true, "become": true, "run": true,
+}
+
+var (
+	verbsByBase       = map[string]*verbForms{}
+	verbsByThird      = map[string]*verbForms{}
+	verbsByPast       = map[string]*verbForms{}
+	verbsByParticiple = map[string]*verbForms{}
+)
+
+func init() {
+	for _, line := range strings.Split(strings.TrimSpace(irregularVerbs), "\n") {
+		f := strings.Fields(line)
+		addVerb(&verbForms{base: f[0], third: thirdPerson(f[0]), past: f[1], participle: f[2]})
+	}
+	for _, base := range strings.Fields(regularVerbs) {
+		if _, ok := verbsByBase[base]; ok {
+			continue
+		}
+		past := regularPast(base)
+		addVerb(&verbForms{base: base, third: thirdPerson(base), past: past, participle: past})
+	}
+	// be 和 have 的第三人称单数不规则
+	verbsByBase["be"].third = "is"
+	verbsByBase["have"].third = "has"
+	delete(verbsByThird, "bes")
+	delete(verbsByThird, "haves")
+	verbsByThird["is"] = verbsByBase["be"]
+	verbsByThird["has"] = verbsByBase["have"]
+}
+
+func addVerb(v *verbForms) {
+	verbsByBase[v.base] = v
+	verbsByThird[v.third] = v
+	if v.past != v.base {
+		verbsByPast[v.past] = v
+	}
+	if v.participle != v.base {
+		verbsByParticiple[v.participle] = v
+	}
+}
+
+// thirdPerson 第三人称单数形式
+func thirdPerson(base string) string {
+	switch {
+	case base == "go" || base == "do":
+		return base + "es"
+	case strings.HasSuffix(base, "s"), strings.HasSuffix(base, "sh"), strings.HasSuffix(base, "ch"),
+		strings.HasSuffix(base, "x"), strings.HasSuffix(base, "z"):
+		return base + "es"
+	case strings.HasSuffix(base, "y") && len(base) > 1 && !isVowel(base[len(base)-2]):
+		return base[:len(base)-1] + "ies"
+	}
+	return base + "s"
+}
+
+// regularPast 规则动词的过去式
+func regularPast(base string) string {
+	switch {
+	case doubledVerbs[base]:
+		if base == "travel" {
+			return "traveled"
+		}
+		return base + base[len(base)-1:] + "ed"
+	case strings.HasSuffix(base, "e"):
+		return base + "d"
+	case strings.HasSuffix(base, "y") && len(base) > 1 && !isVowel(base[len(base)-2]):
+		return base[:len(base)-1] + "ied"
+	}
+	return base + "ed"
+}
+
+func isVowel(b byte) bool {
+	return strings.IndexByte("aeiou", b) >= 0
+}
+
+// 冠词判断用词表
+var (
+	// consonantSoundPrefixes 以元音字母开头但读辅音的单词前缀
+	// uni 开头的单词只列出读 /ju:/ 的（unit、union、university 等），unimportant、uninteresting 等 un- 前缀词读元音
+	consonantSoundPrefixes = []string{
+		"unif", "unio", "uniq", "unit", "univ", "unic", "unis", "unil",
+		"use", "usu", "uti", "eu", "one", "once", "ufo", "ukr",
+	}
+	// silentHPrefixes h 不发音的单词前缀
+	silentHPrefixes = []string{"hour", "honest", "honor", "honour", "heir"}
+	// uncountableNouns 常见不可数名词
+	uncountableNouns = wordSet("advice information furniture homework luggage baggage news equipment knowledge traffic weather money bread rice music feedback")
+	// afterNoun 不可数名词独立使用时常见的后续词，出现在其他词前时多为名词修饰语（如 a news report）
+	afterNoun = wordSet("for about on in from of to and is was please")
+)
+
+// 主谓一致和时态判断用词表
+var (
+	thirdSingular  = wordSet("he she it")
+	otherSubjects  = wordSet("i you we they")
+	modals         = wordSet("can could will would shall should may might must can't cannot couldn't won't wouldn't shouldn't mustn't")
+	doAux          = wordSet("do does did don't doesn't didn't")
+	questionAux    = wordSet("do does did can could will would shall should may might must let make made help watch see saw hear heard")
+	skippedAdverbs = wordSet("not also never really just still always even ever already")
+	// clauseStarters 可引出从句的词，其后的 it / you 多为主语
+	clauseStarters = wordSet("and but so because when if that then or maybe think know hope guess believe sure before after while until since where why how what")
+	// clauseVerbs 常接从句或不定式作宾语的动词，过去时间状语多修饰宾语中的动作
+	clauseVerbs = wordSet("think know hope guess believe need want have say tell remember forget mean feel wish expect decide")
+	// objectStarters 动词后常见的宾语、状语开头词，其后紧跟的不是名词修饰语
+	objectStarters = wordSet("a an the my your his her its our their this that these those some any me you him us them it there here home to at in on for with from by up down out back over into about")
+	// pastMarkers 表示过去时间的词
+	pastMarkers = wordSet("yesterday ago")
+	// lastPeriods last 后表示过去时间的词
+	lastPeriods = wordSet("night week weekend month year time summer winter spring autumn monday tuesday wednesday thursday friday saturday sunday")
+	// 功能词，a 后出现这些词时 a 多为字母（如 plan A is）
+	functionWords = wordSet("is are was were am and or of in on at to for")
+)
+
+func wordSet(s string) map[string]bool {
+	m := map[string]bool{}
+	for _, w := range strings.Fields(s) {
+		m[w] = true
+	}
+	return m
+}
@@ -0,0 +1,828 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"server/audio"
+	"server/database"
+	"server/dialogue"
+	"server/importer"
+	"server/listening"
+	"server/models"
+	"server/subtitle"
+	"server/utils"
+)
+
+// maxImportFileSize 词书导入文件大小上限
+const maxImportFileSize = 50 << 20
+
+// ImportWordbook 批量导入词书
+// POST /api/admin/wordbooks/import
+// multipart表单: file 导入文件, name/description/category/level 词书信息,
+// dry_run 是否试运行（默认 true，需显式传 false 才会写入）, overwrite 是否覆盖已有单词
+func ImportWordbook(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportFileSize+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.Warn("ImportWordbook - Missing file: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请上传导入文件"})
+		return
+	}
+	if fileHeader.Size > maxImportFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "导入文件过大"})
+		return
+	}
+
+	format := c.PostForm("format")
+	if format == "" {
+		format, err = importer.DetectFormat(fileHeader.Filename)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	dryRun := true
+	if s := c.PostForm("dry_run"); s != "" {
+		if dryRun, err = strconv.ParseBool(s); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run 参数无效"})
+			return
+		}
+	}
+	overwrite, _ := strconv.ParseBool(c.PostForm("overwrite"))
+
+	f, err := fileHeader.Open()
+	if err != nil {
+		utils.Error("ImportWordbook - Open upload failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取文件失败"})
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		utils.Error("ImportWordbook - Read upload failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取文件失败"})
+		return
+	}
+
+	ds, err := importer.Parse(format, data)
+	if err != nil {
+		utils.Warn("ImportWordbook - Parse failed: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	utils.Info("ImportWordbook - Admin: %s, File: %s, Format: %s, Entries: %d, DryRun: %v",
+		c.GetString("username"), fileHeader.Filename, format, len(ds.Entries), dryRun)
+
+	report, err := importer.Import(database.GetDB(), ds, importer.Options{
+		Wordbook: importer.WordbookMeta{
+			Name:        c.PostForm("name"),
+			Description: c.PostForm("description"),
+			Category:    c.PostForm("category"),
+			Level:       c.PostForm("level"),
+		},
+		DryRun:    dryRun,
+		Overwrite: overwrite,
+	})
+	if errors.Is(err, importer.ErrNoWordbookName) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		utils.Error("ImportWordbook - Import failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "导入失败"})
+		return
+	}
+
+	utils.Info("ImportWordbook - Done: Wordbook=%s, Valid=%d, Invalid=%d, Created=%d, Linked=%d, DryRun=%v",
+		report.Wordbook, report.Valid, report.Invalid, report.WordsCreated, report.WordsLinked, report.DryRun)
+	c.JSON(http.StatusOK, report)
+}
+
+// errAudioNotFound 引用的托管音频不存在
+var errAudioNotFound = errors.New("audio asset not found")
+
+// maxListeningItemsPerRequest 单次添加的听力题目上限
+const maxListeningItemsPerRequest = 200
+
+// ListeningItemInput 听力题目
+type ListeningItemInput struct {
+	Phenomenon   string   `json:"phenomenon" binding:"required"` // slow / normal / liaison / weak_form / elision
+	AudioURL     string   `json:"audio_url" binding:"required"`
+	Transcript   string   `json:"transcript"`
+	Focus        string   `json:"focus"`
+	Options      []string `json:"options" binding:"required,min=2"`
+	CorrectIndex int      `json:"correct_index"`
+	Level        string   `json:"level"`
+}
+
+// AddListeningItemsRequest 批量添加听力题目请求
+type AddListeningItemsRequest struct {
+	Items []ListeningItemInput `json:"items" binding:"required,min=1,dive"`
+}
+
+// AddListeningItems 批量添加听力测试题目
+// POST /api/admin/listening/items
+// 题目音频须先上传为托管音频，添加后设为受限音频，只能通过测评播放接口下发的签名地址访问
+func AddListeningItems(c *gin.Context) {
+	var req AddListeningItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Warn("AddListeningItems - Invalid request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Items) > maxListeningItemsPerRequest {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "单次添加的题目过多"})
+		return
+	}
+
+	items := make([]models.ListeningItem, 0, len(req.Items))
+	var assetIDs []uint
+	seen := map[uint]bool{}
+	for i, in := range req.Items {
+		if !models.IsValidPhenomenon(in.Phenomenon) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("第%d题听力现象无效: %s", i+1, in.Phenomenon)})
+			return
+		}
+		assetID, ok := audio.AssetID(in.AudioURL)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("第%d题音频须为托管音频地址", i+1)})
+			return
+		}
+		if !seen[assetID] {
+			seen[assetID] = true
+			assetIDs = append(assetIDs, assetID)
+		}
+		if in.CorrectIndex < 0 || in.CorrectIndex >= len(in.Options) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("第%d题正确选项下标越界", i+1)})
+			return
+		}
+		items = append(items, models.ListeningItem{
+			Phenomenon:   in.Phenomenon,
+			AudioURL:     in.AudioURL,
+			Transcript:   in.Transcript,
+			Focus:        in.Focus,
+			Options:      in.Options,
+			CorrectIndex: in.CorrectIndex,
+			Level:        in.Level,
+		})
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.AudioAsset{}).Where("id IN ?", assetIDs).Update("restricted", true)
+		if res.Error != nil {
+			return res.Error
+		}
+		if int(res.RowsAffected) != len(assetIDs) {
+			return errAudioNotFound
+		}
+		return tx.Create(&items).Error
+	})
+	if errors.Is(err, errAudioNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "题目引用的托管音频不存在"})
+		return
+	}
+	if err != nil {
+		utils.Error("AddListeningItems - Create failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "添加题目失败"})
+		return
+	}
+
+	utils.Info("AddListeningItems - Admin: %s, Count: %d", c.GetString("username"), len(items))
+	c.JSON(http.StatusCreated, gin.H{"created": len(items)})
+}
+
+// maxDrillItemsPerRequest 单次添加的语流音变训练题上限
+const maxDrillItemsPerRequest = 200
+
+// drillSpanEpsilon 训练题音频片段与句子时间轴比较的容差（秒）
+const drillSpanEpsilon = 0.05
+
+// DrillItemInput 语流音变训练题
+type DrillItemInput struct {
+	Phenomenon   string   `json:"phenomenon" binding:"required"` // 语流音变现象代码，如 cv_linking / t_flapping / weak_form / elision
+	SentenceID   uint     `json:"sentence_id" binding:"required"`
+	StartTime    float64  `json:"start_time"` // 秒，需落在句子时间范围内
+	EndTime      float64  `json:"end_time"`
+	Text         string   `json:"text" binding:"required"`
+	Focus        string   `json:"focus"`
+	Options      []string `json:"options" binding:"required,min=2"`
+	CorrectIndex int      `json:"correct_index"`
+	Level        string   `json:"level"`
+}
+
+// AddDrillItemsRequest 批量添加训练题请求
+type AddDrillItemsRequest struct {
+	Items []DrillItemInput `json:"items" binding:"required,min=1,dive"`
+}
+
+// AddDrillItems 批量添加语流音变训练题
+// POST /api/admin/listening/drills/items
+// 每道题关联听力材料中的一句，音频片段取该句时间范围内的一段
+func AddDrillItems(c *gin.Context) {
+	var req AddDrillItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Warn("AddDrillItems - Invalid request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Items) > maxDrillItemsPerRequest {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "单次添加的题目过多"})
+		return
+	}
+
+	db := database.GetDB()
+	ids := make([]uint, 0, len(req.Items))
+	for _, in := range req.Items {
+		ids = append(ids, in.SentenceID)
+	}
+	var sentences []models.ListeningSentence
+	if err := db.Where("id IN ?", ids).Find(&sentences).Error; err != nil {
+		utils.Error("AddDrillItems - Query sentences failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	byID := make(map[uint]models.ListeningSentence, len(sentences))
+	for _, s := range sentences {
+		byID[s.ID] = s
+	}
+
+	items := make([]models.DrillItem, 0, len(req.Items))
+	for i, in := range req.Items {
+		p, ok := models.FindSpeechPhenomenon(in.Phenomenon)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("第%d题语流音变现象无效: %s", i+1, in.Phenomenon)})
+			return
+		}
+		sentence, ok := byID[in.SentenceID]
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("第%d题句子不存在: %d", i+1, in.SentenceID)})
+			return
+		}
+		if in.EndTime <= in.StartTime || in.StartTime < sentence.StartTime-drillSpanEpsilon ||
+			in.EndTime > sentence.EndTime+drillSpanEpsilon {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("第%d题音频片段不在句子时间范围内", i+1)})
+			return
+		}
+		if in.CorrectIndex < 0 || in.CorrectIndex >= len(in.Options) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("第%d题正确选项下标越界", i+1)})
+			return
+		}
+		items = append(items, models.DrillItem{
+			Phenomenon:   p.Code,
+			Category:     p.Category,
+			SentenceID:   sentence.ID,
+			StartTime:    in.StartTime,
+			EndTime:      in.EndTime,
+			Text:         in.Text,
+			Focus:        in.Focus,
+			Options:      in.Options,
+			CorrectIndex: in.CorrectIndex,
+			Level:        in.Level,
+		})
+	}
+
+	if err := db.Create(&items).Error; err != nil {
+		utils.Error("AddDrillItems - Create failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "添加题目失败"})
+		return
+	}
+
+	utils.Info("AddDrillItems - Admin: %s, Count: %d", c.GetString("username"), len(items))
+	c.JSON(http.StatusCreated, gin.H{"created": len(items)})
+}
+
+// maxSentencesPerMaterial 单个听力材料的句子上限
+const maxSentencesPerMaterial = 2000
+
+// ListeningSentenceInput 听力材料句子
+type ListeningSentenceInput struct {
+	Text          string   `json:"text"`
+	Translation   string   `json:"translation"`
+	StartTime     float64  `json:"start_time"` // 秒
+	EndTime       float64  `json:"end_time"`   // 秒
+	Keywords      []string `json:"keywords"`
+	Pronunciation string   `json:"pronunciation"`
+}
+
+// ListeningMaterialRequest 创建或更新听力材料请求
+type ListeningMaterialRequest struct {
+	Kind       string                   `json:"kind"` // listening（默认）/ shadowing
+	Title      string                   `json:"title" binding:"required"`
+	Subtitle   string                   `json:"subtitle"`
+	AudioURL   string                   `json:"audio_url" binding:"required"`
+	VideoURL   string                   `json:"video_url"`
+	ImageURL   string                   `json:"image_url"`
+	Difficulty string                   `json:"difficulty" binding:"required"` // A1-C2
+	Duration   float64                  `json:"duration"`                      // 秒，0 表示不校验句子是否超出时长
+	Tags       []string                 `json:"tags"`                          // 更新时省略则保留原有标签
+	Sentences  []ListeningSentenceInput `json:"sentences"`                     // 按时间顺序排列；更新时省略则保留原有句子
+}
+
+// CreateListeningMaterial 创建听力材料
+// POST /api/admin/listening/materials
+func CreateListeningMaterial(c *gin.Context) {
+	req, tags, sentences, ok := bindListeningMaterial(c, "CreateListeningMaterial")
+	if !ok {
+		return
+	}
+	if sentences == nil {
+		sentences = []models.ListeningSentence{}
+	}
+	if err := listening.ValidateSentences(sentences, req.Duration); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	material := models.ListeningMaterial{}
+	applyListeningMaterial(&material, req)
+	db := database.GetDB()
+	if err := listening.CreateMaterial(db, &material, sentences, tags); err != nil {
+		utils.Error("CreateListeningMaterial - Create failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建听力材料失败"})
+		return
+	}
+
+	created, err := loadListeningMaterial(db, material.ID)
+	if err != nil {
+		utils.Error("CreateListeningMaterial - Reload failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	utils.Info("CreateListeningMaterial - Admin: %s, MaterialID: %d, Sentences: %d",
+		c.GetString("username"), material.ID, len(sentences))
+	c.JSON(http.StatusCreated, created)
+}
+
+// UpdateListeningMaterial 更新听力材料
+// PUT /api/admin/listening/materials/:id
+func UpdateListeningMaterial(c *gin.Context) {
+	materialID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	req, tags, sentences, ok := bindListeningMaterial(c, "UpdateListeningMaterial")
+	if !ok {
+		return
+	}
+
+	db := database.GetDB()
+	var material models.ListeningMaterial
+	err := db.First(&material, materialID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "听力材料不存在"})
+		return
+	}
+	if err != nil {
+		utils.Error("UpdateListeningMaterial - Query failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	// 未提供句子时，原有句子也要符合新的音频时长
+	check := sentences
+	if check == nil {
+		if err := db.Where("material_id = ?", materialID).Order("seq ASC").Find(&check).Error; err != nil {
+			utils.Error("UpdateListeningMaterial - Query sentences failed: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+			return
+		}
+	}
+	if err := listening.ValidateSentences(check, req.Duration); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	applyListeningMaterial(&material, req)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("kind", "title", "subtitle", "audio_url", "video_url", "image_url", "difficulty", "duration").
+			Updates(&material).Error; err != nil {
+			return err
+		}
+		if sentences != nil {
+			if err := listening.ReplaceSentences(tx, material.ID, sentences); err != nil {
+				return err
+			}
+		}
+		if err := listening.SyncShadowing(tx, &material); err != nil {
+			return err
+		}
+		if req.Tags == nil {
+			return nil
+		}
+		return listening.SetTags(tx, material.ID, tags)
+	})
+	if err != nil {
+		utils.Error("UpdateListeningMaterial - Update failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新听力材料失败"})
+		return
+	}
+
+	updated, err := loadListeningMaterial(db, material.ID)
+	if err != nil {
+		utils.Error("UpdateListeningMaterial - Reload failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	utils.Info("UpdateListeningMaterial - Admin: %s, MaterialID: %d, ReplacedSentences: %v",
+		c.GetString("username"), material.ID, sentences != nil)
+	c.JSON(http.StatusOK, updated)
+}
+
+// DeleteListeningMaterial 删除听力材料
+// DELETE /api/admin/listening/materials/:id
+func DeleteListeningMaterial(c *gin.Context) {
+	materialID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var deleted int64
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		res := tx.Delete(&models.ListeningMaterial{}, materialID)
+		if res.Error != nil {
+			return res.Error
+		}
+		deleted = res.RowsAffected
+		if err := tx.Where("material_id = ?", materialID).Delete(&models.ListeningSentence{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("listening_material_id = ?", materialID).Delete(&models.ShadowingMaterial{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("material_id = ?", materialID).Delete(&models.ListeningMaterialTag{}).Error
+	})
+	if err != nil {
+		utils.Error("DeleteListeningMaterial - Delete failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除听力材料失败"})
+		return
+	}
+	if deleted == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "听力材料不存在"})
+		return
+	}
+
+	utils.Info("DeleteListeningMaterial - Admin: %s, MaterialID: %d", c.GetString("username"), materialID)
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
+
+// ShadowingMaterialRequest 更新影子跟读材料请求
+type ShadowingMaterialRequest struct {
+	Source    string `json:"source"`                             // 来源，如影视剧名
+	PassScore int    `json:"pass_score" binding:"min=0,max=100"` // 单句达标分数，0 表示使用默认值
+}
+
+// UpdateShadowingMaterial 更新影子跟读材料的来源和达标分数
+// PUT /api/admin/shadowing/materials/:id
+// 音频、句子等内容通过听力材料接口维护
+func UpdateShadowingMaterial(c *gin.Context) {
+	materialID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req ShadowingMaterialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Warn("UpdateShadowingMaterial - Invalid request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Source = strings.TrimSpace(req.Source)
+	if len([]rune(req.Source)) > 200 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "来源过长"})
+		return
+	}
+
+	db := database.GetDB()
+	res := db.Model(&models.ShadowingMaterial{}).Where("id = ?", materialID).
+		Updates(map[string]interface{}{"source": req.Source, "pass_score": req.PassScore})
+	if res.Error != nil {
+		utils.Error("UpdateShadowingMaterial - Update failed: %v", res.Error)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新跟读材料失败"})
+		return
+	}
+	if res.RowsAffected == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "跟读材料不存在"})
+		return
+	}
+
+	var material models.ShadowingMaterial
+	if err := db.Preload("Material").First(&material, materialID).Error; err != nil {
+		utils.Error("UpdateShadowingMaterial - Reload failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	utils.Info("UpdateShadowingMaterial - Admin: %s, MaterialID: %d", c.GetString("username"), materialID)
+	c.JSON(http.StatusOK, material)
+}
+
+// maxSubtitleFileSize 字幕文件大小上限
+const maxSubtitleFileSize = 5 << 20
+
+// SubtitleImportResponse 字幕导入响应
+type SubtitleImportResponse struct {
+	*subtitle.Result
+	Format   string                    `json:"format"`
+	DryRun   bool                      `json:"dry_run"`
+	Material *models.ListeningMaterial `json:"material,omitempty"` // 试运行时不返回，句子预览见 sentences
+}
+
+// ImportSubtitles 通过字幕文件创建听力或跟读材料
+// POST /api/admin/listening/materials/import
+// multipart表单: file 字幕文件（SRT/WebVTT/ASS，可为中英双语）, translation 单独的中文字幕（可选）,
+// format/translation_format 字幕格式（默认按扩展名识别）, kind/title/subtitle/audio_url/video_url/image_url/difficulty 材料信息,
+// duration 音频时长（秒，默认取最后一句的结束时间）, tags 逗号分隔的标签, dry_run 是否只预览不写入
+func ImportSubtitles(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 2*maxSubtitleFileSize+1<<20)
+
+	track, ok := parseSubtitleFile(c, "file", "format", true)
+	if !ok {
+		return
+	}
+	translation, ok := parseSubtitleFile(c, "translation", "translation_format", false)
+	if !ok {
+		return
+	}
+
+	req := ListeningMaterialRequest{
+		Kind:       c.PostForm("kind"),
+		Title:      c.PostForm("title"),
+		Subtitle:   c.PostForm("subtitle"),
+		AudioURL:   c.PostForm("audio_url"),
+		VideoURL:   c.PostForm("video_url"),
+		ImageURL:   c.PostForm("image_url"),
+		Difficulty: c.PostForm("difficulty"),
+	}
+	if s := c.PostForm("duration"); s != "" {
+		d, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "duration 参数无效"})
+			return
+		}
+		req.Duration = d
+	}
+	if s := c.PostForm("tags"); s != "" {
+		req.Tags = strings.Split(s, ",")
+	}
+	tags, ok := checkListeningMaterial(c, &req)
+	if !ok {
+		return
+	}
+	dryRun := false
+	if s := c.PostForm("dry_run"); s != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(s); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dry_run 参数无效"})
+			return
+		}
+	}
+
+	result := subtitle.Build(track, translation, subtitle.DefaultOptions)
+	resp := SubtitleImportResponse{Result: result, Format: track.Format, DryRun: dryRun}
+	if len(result.Sentences) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "字幕中没有可导入的英文句子", "issues": result.Issues})
+		return
+	}
+	if len(result.Sentences) > maxSentencesPerMaterial {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "句子数量过多"})
+		return
+	}
+
+	sentences := make([]models.ListeningSentence, 0, len(result.Sentences))
+	for _, st := range result.Sentences {
+		sentences = append(sentences, models.ListeningSentence{
+			Text:        st.Text,
+			Translation: st.Translation,
+			StartTime:   st.Start,
+			EndTime:     st.End,
+			Keywords:    []string{},
+		})
+	}
+	if req.Duration == 0 {
+		req.Duration = sentences[len(sentences)-1].EndTime
+	}
+	if err := listening.ValidateSentences(sentences, req.Duration); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "issues": result.Issues})
+		return
+	}
+
+	utils.Info("ImportSubtitles - Admin: %s, Format: %s, Cues: %d, Sentences: %d, Untranslated: %d, Issues: %d, DryRun: %v",
+		c.GetString("username"), track.Format, result.Cues, len(sentences), result.Untranslated, len(result.Issues), dryRun)
+	if dryRun {
+		c.JSON(http.StatusOK, resp)
+		return
+	}
+
+	material := models.ListeningMaterial{}
+	applyListeningMaterial(&material, &req)
+	db := database.GetDB()
+	if err := listening.CreateMaterial(db, &material, sentences, tags); err != nil {
+		utils.Error("ImportSubtitles - Create failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建听力材料失败"})
+		return
+	}
+	created, err := loadListeningMaterial(db, material.ID)
+	if err != nil {
+		utils.Error("ImportSubtitles - Reload failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	resp.Material = created
+	resp.Sentences = nil
+	c.JSON(http.StatusCreated, resp)
+}
+
+// parseSubtitleFile 读取并解析上传的字幕文件，失败时直接写入错误响应
+// 非必需的字段未上传时返回 nil
+func parseSubtitleFile(c *gin.Context, field, formatField string, required bool) (*subtitle.Track, bool) {
+	fileHeader, err := c.FormFile(field)
+	if err != nil {
+		if !required {
+			return nil, true
+		}
+		utils.Warn("ImportSubtitles - Missing %s: %v", field, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请上传字幕文件"})
+		return nil, false
+	}
+	if fileHeader.Size > maxSubtitleFileSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "字幕文件过大"})
+		return nil, false
+	}
+
+	format := c.PostForm(formatField)
+	if format == "" {
+		if format, err = subtitle.DetectFormat(fileHeader.Filename); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return nil, false
+		}
+	}
+
+	f, err := fileHeader.Open()
+	if err != nil {
+		utils.Error("ImportSubtitles - Open upload failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取文件失败"})
+		return nil, false
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		utils.Error("ImportSubtitles - Read upload failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取文件失败"})
+		return nil, false
+	}
+
+	track, err := subtitle.Parse(format, data)
+	if err != nil {
+		utils.Warn("ImportSubtitles - Parse %s failed: %v", field, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return track, true
+}
+
+// bindListeningMaterial 解析并校验听力材料请求，失败时直接写入错误响应
+// 请求未包含 sentences 字段时返回的句子为 nil
+func bindListeningMaterial(c *gin.Context, caller string) (*ListeningMaterialRequest, []string, []models.ListeningSentence, bool) {
+	var req ListeningMaterialRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Warn("%s - Invalid request: %v", caller, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, nil, nil, false
+	}
+	tags, ok := checkListeningMaterial(c, &req)
+	if !ok {
+		return nil, nil, nil, false
+	}
+	if len(req.Sentences) > maxSentencesPerMaterial {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "句子数量过多"})
+		return nil, nil, nil, false
+	}
+
+	var sentences []models.ListeningSentence
+	if req.Sentences != nil {
+		sentences = make([]models.ListeningSentence, 0, len(req.Sentences))
+		for _, in := range req.Sentences {
+			keywords := in.Keywords
+			if keywords == nil {
+				keywords = []string{}
+			}
+			sentences = append(sentences, models.ListeningSentence{
+				Text:          strings.TrimSpace(in.Text),
+				Translation:   strings.TrimSpace(in.Translation),
+				StartTime:     in.StartTime,
+				EndTime:       in.EndTime,
+				Keywords:      keywords,
+				Pronunciation: in.Pronunciation,
+			})
+		}
+	}
+	return &req, tags, sentences, true
+}
+
+// checkListeningMaterial 规范化并校验材料元数据，返回规范化后的标签
+// 校验失败时直接写入错误响应
+func checkListeningMaterial(c *gin.Context, req *ListeningMaterialRequest) ([]string, bool) {
+	req.Title = strings.TrimSpace(req.Title)
+	req.AudioURL = strings.TrimSpace(req.AudioURL)
+	if req.Title == "" || req.AudioURL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "标题和音频地址不能为空"})
+		return nil, false
+	}
+	if req.Kind == "" {
+		req.Kind = models.MaterialKindListening
+	}
+	if !models.IsValidMaterialKind(req.Kind) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "材料类型无效"})
+		return nil, false
+	}
+	req.Difficulty = strings.ToUpper(strings.TrimSpace(req.Difficulty))
+	if !models.IsValidCEFRLevel(req.Difficulty) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "难度等级无效，应为 A1-C2"})
+		return nil, false
+	}
+	if req.Duration < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "音频时长无效"})
+		return nil, false
+	}
+	tags, err := listening.NormalizeTags(req.Tags)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return tags, true
+}
+
+// applyListeningMaterial 将请求中的元数据写入材料
+func applyListeningMaterial(m *models.ListeningMaterial, req *ListeningMaterialRequest) {
+	m.Kind = req.Kind
+	m.Title = strings.TrimSpace(req.Title)
+	m.Subtitle = req.Subtitle
+	m.AudioURL = req.AudioURL
+	m.VideoURL = req.VideoURL
+	m.ImageURL = req.ImageURL
+	m.Difficulty = req.Difficulty
+	m.Duration = req.Duration
+}
+
+// DialogueScenarioRequest 创建或更新实战对话场景请求
+type DialogueScenarioRequest struct {
+	Slug        string                `json:"slug" binding:"required"`
+	Title       string                `json:"title" binding:"required"`
+	Description string                `json:"description"`
+	Difficulty  string                `json:"difficulty"`
+	AIRole      string                `json:"ai_role"`
+	UserRole    string                `json:"user_role"`
+	Goal        string                `json:"goal"`
+	Prompt      string                `json:"prompt"` // 给大模型的补充说明
+	Lines       []models.DialogueLine `json:"lines" binding:"required,max=200"`
+}
+
+// SaveDialogueScenario 按场景标识创建或更新实战对话场景
+// POST /api/admin/dialogues/scenarios
+func SaveDialogueScenario(c *gin.Context) {
+	var req DialogueScenarioRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Warn("SaveDialogueScenario - Invalid request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	scenario := models.DialogueScenario{
+		Slug:        req.Slug,
+		Title:       req.Title,
+		Description: req.Description,
+		Difficulty:  req.Difficulty,
+		AIRole:      strings.TrimSpace(req.AIRole),
+		UserRole:    strings.TrimSpace(req.UserRole),
+		Goal:        strings.TrimSpace(req.Goal),
+		Prompt:      req.Prompt,
+		Lines:       req.Lines,
+	}
+	if err := dialogue.ValidateScenario(&scenario); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDB()
+	if err := dialogue.SaveScenario(db, &scenario); err != nil {
+		utils.Error("SaveDialogueScenario - Save failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存对话场景失败"})
+		return
+	}
+	var saved models.DialogueScenario
+	if err := db.Where("slug = ?", scenario.Slug).First(&saved).Error; err != nil {
+		utils.Error("SaveDialogueScenario - Reload failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	utils.Info("SaveDialogueScenario - Admin: %s, Slug: %s, Lines: %d", c.GetString("username"), saved.Slug, len(saved.Lines))
+	c.JSON(http.StatusOK, saved)
+}
@@ -0,0 +1,653 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"server/assessment"
+	"server/audio"
+	"server/database"
+	"server/models"
+	"server/utils"
+)
+
+var errAssessmentNotFound = errors.New("assessment not found")
+
+// VocabAnswerRequest 词汇测试作答请求
+type VocabAnswerRequest struct {
+	ItemID      uint `json:"item_id" binding:"required"`
+	AnswerIndex *int `json:"answer_index" binding:"required"` // 选项下标，-1 表示不认识
+}
+
+// VocabAnswerResponse 词汇测试作答响应
+type VocabAnswerResponse struct {
+	Correct      bool                     `json:"correct"`
+	CorrectIndex int                      `json:"correct_index"`
+	TimedOut     bool                     `json:"timed_out"`
+	Session      models.AssessmentSession `json:"session"`
+	Result       *assessment.VocabResult  `json:"result,omitempty"` // 测评结束时返回
+}
+
+// VocabNextResponse 获取下一题响应
+type VocabNextResponse struct {
+	Item      *models.VocabTestItem    `json:"item,omitempty"` // 测评结束时为空
+	TimeLimit int                      `json:"time_limit"`     // 作答时限（秒）
+	Session   models.AssessmentSession `json:"session"`
+}
+
+// StartVocabAssessment 开始词汇测试
+// POST /api/assessment/vocab
+// 进行中的旧测试会被放弃
+func StartVocabAssessment(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("StartVocabAssessment - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	db := database.GetDB()
+	if err := assessment.CheckVocabItemBank(db); err != nil {
+		utils.Error("StartVocabAssessment - Item bank check failed: %v", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "词汇题库尚未准备好"})
+		return
+	}
+
+	session := models.AssessmentSession{
+		UserID:    userID.(uint),
+		Kind:      models.AssessmentKindVocab,
+		Status:    models.AssessmentStatusActive,
+		ItemCount: assessment.VocabTestItems,
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.AssessmentSession{}).
+			Where("user_id = ? AND kind = ? AND status = ?", userID, models.AssessmentKindVocab, models.AssessmentStatusActive).
+			Update("status", models.AssessmentStatusAbandoned).Error; err != nil {
+			return err
+		}
+		return tx.Create(&session).Error
+	})
+	if err != nil {
+		utils.Error("StartVocabAssessment - Create session failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建测评失败"})
+		return
+	}
+
+	utils.Info("StartVocabAssessment - UserID: %v, SessionID: %d", userID, session.ID)
+	c.JSON(http.StatusCreated, session)
+}
+
+// GetVocabAssessment 获取词汇测试状态
+// GET /api/assessment/vocab/:id
+func GetVocabAssessment(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("GetVocabAssessment - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	sessionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	session, err := loadAssessmentSession(database.GetDB(), userID.(uint), sessionID, models.AssessmentKindVocab)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "测评不存在"})
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
+
+// NextVocabItem 获取下一题
+// GET /api/assessment/vocab/:id/next
+// 已出题但未作答时返回同一题，重复请求不会跳题
+func NextVocabItem(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("NextVocabItem - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	sessionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var resp VocabNextResponse
+	resp.TimeLimit = int(assessment.ItemTimeLimit / time.Second)
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		session, err := loadAssessmentSession(tx, userID.(uint), sessionID, models.AssessmentKindVocab)
+		if err != nil {
+			return err
+		}
+		resp.Session = *session
+		if session.Status != models.AssessmentStatusActive {
+			return nil
+		}
+
+		var items []models.VocabTestItem
+		if err := tx.Where("session_id = ?", session.ID).Order("seq ASC").Find(&items).Error; err != nil {
+			return err
+		}
+		used := make([]uint, 0, len(items))
+		for i := range items {
+			if items[i].AnswerIndex == nil {
+				resp.Item = &items[i]
+				return nil
+			}
+			used = append(used, items[i].WordID)
+		}
+
+		item, err := assessment.SelectVocabItem(tx, session, used)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(item).Error; err != nil {
+			return err
+		}
+		resp.Item = item
+		return nil
+	})
+	if errors.Is(err, errAssessmentNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "测评不存在"})
+		return
+	}
+	if errors.Is(err, assessment.ErrItemBankTooSmall) {
+		utils.Error("NextVocabItem - Item bank exhausted: SessionID=%d", sessionID)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "词汇题库尚未准备好"})
+		return
+	}
+	if err != nil {
+		utils.Error("NextVocabItem - Select item failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "出题失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// AnswerVocabItem 提交词汇测试答案
+// POST /api/assessment/vocab/:id/answer
+// 超过作答时限的答案按答错处理；完成全部题目后写入用户词汇等级
+func AnswerVocabItem(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("AnswerVocabItem - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	sessionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req VocabAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Warn("AnswerVocabItem - Invalid request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	var resp VocabAnswerResponse
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		session, err := loadAssessmentSession(tx, userID.(uint), sessionID, models.AssessmentKindVocab)
+		if err != nil {
+			return err
+		}
+		if session.Status != models.AssessmentStatusActive {
+			return assessment.ErrSessionFinished
+		}
+
+		var item models.VocabTestItem
+		if err := tx.Where("id = ? AND session_id = ? AND answer_index IS NULL", req.ItemID, session.ID).
+			First(&item).Error; err != nil {
+			return assessment.ErrItemMismatch
+		}
+
+		answer := *req.AnswerIndex
+		if answer < -1 || answer >= len(item.Options) {
+			answer = -1
+		}
+		resp.TimedOut = assessment.AnswerExpired(&item, now)
+		if resp.TimedOut {
+			answer = -1
+		}
+		correct := answer == item.CorrectIndex
+		item.AnswerIndex = &answer
+		item.Correct = &correct
+		item.AnsweredAt = &now
+		if err := tx.Save(&item).Error; err != nil {
+			return err
+		}
+		resp.Correct = correct
+		resp.CorrectIndex = item.CorrectIndex
+
+		var answered []models.VocabTestItem
+		if err := tx.Where("session_id = ? AND answer_index IS NOT NULL", session.ID).Find(&answered).Error; err != nil {
+			return err
+		}
+		result := assessment.ScoreVocab(answered)
+		session.Answered = len(answered)
+		session.Theta = result.Theta
+		session.StdErr = result.StdErr
+
+		if session.Answered >= session.ItemCount {
+			session.Status = models.AssessmentStatusCompleted
+			session.CompletedAt = &now
+			session.Result = result.Level
+			session.Score = result.Score
+			session.VocabSize = result.VocabSize
+			resp.Result = &result
+
+			if err := tx.Model(&models.User{}).Where("id = ?", session.UserID).Updates(map[string]interface{}{
+				"level_vocabulary_level": result.Level,
+				"level_vocabulary_score": result.Score,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(session).Error; err != nil {
+			return err
+		}
+		resp.Session = *session
+		return nil
+	})
+	switch {
+	case errors.Is(err, errAssessmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "测评不存在"})
+		return
+	case errors.Is(err, assessment.ErrSessionFinished), errors.Is(err, assessment.ErrItemMismatch):
+		utils.Warn("AnswerVocabItem - %v: SessionID=%d, ItemID=%d", err, sessionID, req.ItemID)
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		utils.Error("AnswerVocabItem - Save answer failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "提交答案失败"})
+		return
+	}
+
+	if resp.Result != nil {
+		utils.Info("AnswerVocabItem - Completed: UserID=%v, SessionID=%d, VocabSize=%d, Level=%s",
+			userID, sessionID, resp.Result.VocabSize, resp.Result.Level)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// loadAssessmentSession 加载属于当前用户的测评会话
+func loadAssessmentSession(tx *gorm.DB, userID uint, sessionID uint, kind string) (*models.AssessmentSession, error) {
+	var session models.AssessmentSession
+	err := tx.Where("id = ? AND user_id = ? AND kind = ?", sessionID, userID, kind).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errAssessmentNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ListeningSessionResponse 听力测试会话响应
+type ListeningSessionResponse struct {
+	Session  models.AssessmentSession   `json:"session"`
+	Items    []models.ListeningTestItem `json:"items"`
+	MaxPlays int                        `json:"max_plays"` // 每题最多播放次数
+}
+
+// ListeningPlayResponse 播放听力题目响应
+type ListeningPlayResponse struct {
+	AudioURL  string    `json:"audio_url"`  // 短期签名地址，过期后需重新播放
+	ExpiresAt time.Time `json:"expires_at"` // 签名地址过期时间
+	Plays     int       `json:"plays"`      // 已播放次数
+	PlaysLeft int       `json:"plays_left"` // 剩余播放次数
+}
+
+// ListeningAnswerRequest 听力测试作答请求
+type ListeningAnswerRequest struct {
+	ItemID      uint `json:"item_id" binding:"required"`
+	AnswerIndex *int `json:"answer_index" binding:"required"` // 选项下标，-1 表示没听清
+}
+
+// ListeningAnswerResponse 听力测试作答响应
+type ListeningAnswerResponse struct {
+	Correct      bool                        `json:"correct"`
+	CorrectIndex int                         `json:"correct_index"`
+	Transcript   string                      `json:"transcript"` // 作答后公布原文
+	Session      models.AssessmentSession    `json:"session"`
+	Result       *assessment.ListeningResult `json:"result,omitempty"`  // 测评结束时返回
+	Profile      *models.ListeningProfile    `json:"profile,omitempty"` // 测评结束时返回
+}
+
+// StartListeningAssessment 开始听力测试
+// POST /api/assessment/listening
+// 一次性抽取全部题目，进行中的旧测试会被放弃
+func StartListeningAssessment(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("StartListeningAssessment - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	db := database.GetDB()
+	if err := assessment.CheckListeningItemBank(db); err != nil {
+		utils.Error("StartListeningAssessment - Item bank check failed: %v", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "听力题库尚未准备好"})
+		return
+	}
+
+	resp := ListeningSessionResponse{MaxPlays: assessment.MaxPlays}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.AssessmentSession{}).
+			Where("user_id = ? AND kind = ? AND status = ?", userID, models.AssessmentKindListening, models.AssessmentStatusActive).
+			Update("status", models.AssessmentStatusAbandoned).Error; err != nil {
+			return err
+		}
+
+		resp.Session = models.AssessmentSession{
+			UserID: userID.(uint),
+			Kind:   models.AssessmentKindListening,
+			Status: models.AssessmentStatusActive,
+		}
+		if err := tx.Create(&resp.Session).Error; err != nil {
+			return err
+		}
+
+		items, err := assessment.SelectListeningItems(tx, resp.Session.ID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Create(&items).Error; err != nil {
+			return err
+		}
+		resp.Items = items
+		resp.Session.ItemCount = len(items)
+		return tx.Save(&resp.Session).Error
+	})
+	if err != nil {
+		utils.Error("StartListeningAssessment - Create session failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建测评失败"})
+		return
+	}
+
+	utils.Info("StartListeningAssessment - UserID: %v, SessionID: %d", userID, resp.Session.ID)
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetListeningAssessment 获取听力测试状态和题目
+// GET /api/assessment/listening/:id
+func GetListeningAssessment(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("GetListeningAssessment - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	sessionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	db := database.GetDB()
+	session, err := loadAssessmentSession(db, userID.(uint), sessionID, models.AssessmentKindListening)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "测评不存在"})
+		return
+	}
+
+	resp := ListeningSessionResponse{Session: *session, MaxPlays: assessment.MaxPlays}
+	if err := db.Where("session_id = ?", session.ID).Order("seq ASC").Find(&resp.Items).Error; err != nil {
+		utils.Error("GetListeningAssessment - Query items failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// PlayListeningItem 播放听力题目
+// POST /api/assessment/listening/:id/items/:itemId/play
+// 每次调用计一次播放并下发短期签名地址，超过上限后不再下发
+func PlayListeningItem(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("PlayListeningItem - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	sessionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	itemID, ok := parseIDParam(c, "itemId")
+	if !ok {
+		return
+	}
+
+	var resp ListeningPlayResponse
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		session, err := loadAssessmentSession(tx, userID.(uint), sessionID, models.AssessmentKindListening)
+		if err != nil {
+			return err
+		}
+		if session.Status != models.AssessmentStatusActive {
+			return assessment.ErrSessionFinished
+		}
+
+		var item models.ListeningTestItem
+		if err := tx.Where("id = ? AND session_id = ? AND answer_index IS NULL", itemID, session.ID).
+			First(&item).Error; err != nil {
+			return assessment.ErrItemMismatch
+		}
+
+		// 条件更新保证并发请求也不会突破播放上限
+		result := tx.Model(&models.ListeningTestItem{}).
+			Where("id = ? AND plays < ?", item.ID, assessment.MaxPlays).
+			Update("plays", gorm.Expr("plays + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return assessment.ErrPlayLimitReached
+		}
+
+		var source models.ListeningItem
+		if err := tx.Unscoped().First(&source, item.ItemID).Error; err != nil {
+			return err
+		}
+		// 只下发短期签名地址，原始地址可无限次播放
+		assetID, ok := audio.AssetID(source.AudioURL)
+		if !ok {
+			return assessment.ErrAudioNotHosted
+		}
+		var asset models.AudioAsset
+		if err := tx.First(&asset, assetID).Error; err != nil {
+			return err
+		}
+		resp.ExpiresAt = time.Now().Add(assessment.PlayURLTTL).Truncate(time.Second)
+		resp.AudioURL = audio.SignedURL(&asset, 1, resp.ExpiresAt)
+		resp.Plays = item.Plays + 1
+		resp.PlaysLeft = assessment.MaxPlays - resp.Plays
+		return nil
+	})
+	switch {
+	case errors.Is(err, errAssessmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "测评不存在"})
+		return
+	case errors.Is(err, assessment.ErrSessionFinished), errors.Is(err, assessment.ErrItemMismatch),
+		errors.Is(err, assessment.ErrPlayLimitReached):
+		utils.Warn("PlayListeningItem - %v: SessionID=%d, ItemID=%d", err, sessionID, itemID)
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		utils.Error("PlayListeningItem - Play failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "播放失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// AnswerListeningItem 提交听力测试答案
+// POST /api/assessment/listening/:id/answer
+// 完成全部题目后更新听力分项水平和用户听力等级
+func AnswerListeningItem(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("AnswerListeningItem - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	sessionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req ListeningAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Warn("AnswerListeningItem - Invalid request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	var resp ListeningAnswerResponse
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		session, err := loadAssessmentSession(tx, userID.(uint), sessionID, models.AssessmentKindListening)
+		if err != nil {
+			return err
+		}
+		if session.Status != models.AssessmentStatusActive {
+			return assessment.ErrSessionFinished
+		}
+
+		var item models.ListeningTestItem
+		if err := tx.Where("id = ? AND session_id = ? AND answer_index IS NULL", req.ItemID, session.ID).
+			First(&item).Error; err != nil {
+			return assessment.ErrItemMismatch
+		}
+		if item.Plays == 0 {
+			return assessment.ErrNotPlayed
+		}
+
+		answer := *req.AnswerIndex
+		if answer < -1 || answer >= len(item.Options) {
+			answer = -1
+		}
+		correct := answer == item.CorrectIndex
+		item.AnswerIndex = &answer
+		item.Correct = &correct
+		item.AnsweredAt = &now
+		if err := tx.Save(&item).Error; err != nil {
+			return err
+		}
+		resp.Correct = correct
+		resp.CorrectIndex = item.CorrectIndex
+
+		var source models.ListeningItem
+		if err := tx.Unscoped().First(&source, item.ItemID).Error; err == nil {
+			resp.Transcript = source.Transcript
+		}
+
+		var items []models.ListeningTestItem
+		if err := tx.Where("session_id = ?", session.ID).Find(&items).Error; err != nil {
+			return err
+		}
+		session.Answered = 0
+		for _, it := range items {
+			if it.AnswerIndex != nil {
+				session.Answered++
+			}
+		}
+
+		if session.Answered >= session.ItemCount {
+			result := assessment.ScoreListening(items)
+			session.Status = models.AssessmentStatusCompleted
+			session.CompletedAt = &now
+			session.Result = result.Level
+			session.Score = result.Score
+			resp.Result = &result
+
+			var profile models.ListeningProfile
+			if err := tx.Where("user_id = ?", session.UserID).
+				FirstOrInit(&profile, models.ListeningProfile{UserID: session.UserID}).Error; err != nil {
+				return err
+			}
+			result.ApplyToProfile(&profile)
+			profile.SessionID = session.ID
+			profile.AssessedAt = now
+			if err := tx.Save(&profile).Error; err != nil {
+				return err
+			}
+			resp.Profile = &profile
+
+			if err := tx.Model(&models.User{}).Where("id = ?", session.UserID).Updates(map[string]interface{}{
+				"level_listening_level": result.Level,
+				"level_listening_score": result.Score,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Save(session).Error; err != nil {
+			return err
+		}
+		resp.Session = *session
+		return nil
+	})
+	switch {
+	case errors.Is(err, errAssessmentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "测评不存在"})
+		return
+	case errors.Is(err, assessment.ErrSessionFinished), errors.Is(err, assessment.ErrItemMismatch),
+		errors.Is(err, assessment.ErrNotPlayed):
+		utils.Warn("AnswerListeningItem - %v: SessionID=%d, ItemID=%d", err, sessionID, req.ItemID)
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		utils.Error("AnswerListeningItem - Save answer failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "提交答案失败"})
+		return
+	}
+
+	if resp.Result != nil {
+		utils.Info("AnswerListeningItem - Completed: UserID=%v, SessionID=%d, Score=%d, Level=%s",
+			userID, sessionID, resp.Result.Score, resp.Result.Level)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetListeningProfile 获取听力分项水平
+// GET /api/assessment/listening/profile
+func GetListeningProfile(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("GetListeningProfile - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	var profile models.ListeningProfile
+	err := database.GetDB().Where("user_id = ?", userID).First(&profile).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "尚未完成听力测试"})
+		return
+	}
+	if err != nil {
+		utils.Error("GetListeningProfile - Query failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
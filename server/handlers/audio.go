@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"server/audio"
+	"server/config"
+	"server/database"
+	"server/models"
+	"server/storage"
+	"server/utils"
+)
+
+// AudioAssetResponse 托管音频信息
+type AudioAssetResponse struct {
+	models.AudioAsset
+	URL string `json:"url"` // 原速访问地址，可直接填入听力材料的 audio_url
+}
+
+// UploadAudio 上传托管音频
+// POST /api/admin/audio
+// multipart表单: file 音频文件（WAV）, title 名称
+func UploadAudio(c *gin.Context) {
+	maxSize := config.Get().Audio.MaxSizeMB << 20
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(maxSize)+1<<20)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.Warn("UploadAudio - Missing file: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请上传音频文件"})
+		return
+	}
+	if maxSize > 0 && fileHeader.Size > int64(maxSize) {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": audio.ErrTooLarge.Error()})
+		return
+	}
+	f, err := fileHeader.Open()
+	if err != nil {
+		utils.Error("UploadAudio - Open upload failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取文件失败"})
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		utils.Error("UploadAudio - Read upload failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取文件失败"})
+		return
+	}
+
+	title := c.PostForm("title")
+	if title == "" {
+		title = fileHeader.Filename
+	}
+	asset, err := audio.Save(c.Request.Context(), database.GetDB(), storage.Get(), title, data, maxSize)
+	switch {
+	case errors.Is(err, audio.ErrUnsupportedType):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": audio.ErrUnsupportedType.Error()})
+		return
+	case errors.Is(err, audio.ErrTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, audio.ErrEmpty):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		utils.Error("UploadAudio - Save failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存音频失败"})
+		return
+	}
+
+	utils.Info("UploadAudio - Admin: %s, AudioID: %d, Duration: %.1fs", c.GetString("username"), asset.ID, asset.Duration)
+	c.JSON(http.StatusCreated, AudioAssetResponse{AudioAsset: *asset, URL: audio.URL(asset.ID, 1)})
+}
+
+// DeleteAudio 删除托管音频及其变速缓存
+// DELETE /api/admin/audio/:id
+func DeleteAudio(c *gin.Context) {
+	asset, ok := loadAudioAsset(c, "DeleteAudio")
+	if !ok {
+		return
+	}
+
+	if err := audio.Delete(c.Request.Context(), database.GetDB(), storage.Get(), config.Get().Audio.CacheDir, asset); err != nil {
+		utils.Error("DeleteAudio - Delete failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除音频失败"})
+		return
+	}
+
+	utils.Info("DeleteAudio - Admin: %s, AudioID: %d", c.GetString("username"), asset.ID)
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
+
+// GetAudio 播放托管音频
+// GET /api/audio/:id?speed=0.75&v=版本号
+// speed 取 0.5-2.0，按 0.05 取整；非原速时服务端变速不变调，渲染结果缓存在磁盘上。
+// 支持 Range 断点续传与拖动、ETag/Last-Modified 条件请求；带正确版本号 v 的地址内容不会改变，允许长期缓存。
+// 除 Bearer Token 外也可使用 GetAudioURL 签发的签名地址访问；受限音频只接受签名地址
+func GetAudio(c *gin.Context) {
+	_, authed := c.Get("userID")
+	expires, signed := c.Get("signedURLExpires")
+	if !authed && !signed {
+		utils.Warn("GetAudio - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	speed, err := audio.ParseSpeed(c.Query("speed"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	asset, ok := loadAudioAsset(c, "GetAudio")
+	if !ok {
+		return
+	}
+	if asset.Restricted && !signed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "该音频只能通过签名地址播放"})
+		return
+	}
+	version := c.Query("v")
+	if version != "" && version != audio.Version(asset) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "音频版本不存在"})
+		return
+	}
+
+	etag := audio.ETag(asset, speed)
+	switch {
+	case signed:
+		// 签名地址过期后不应再从缓存播放
+		maxAge := int(time.Until(expires.(time.Time)).Seconds())
+		c.Header("Cache-Control", fmt.Sprintf("private, max-age=%d", max(maxAge, 0)))
+	case version != "":
+		c.Header("Cache-Control", "private, max-age=31536000, immutable")
+	default:
+		c.Header("Cache-Control", "private, no-cache")
+	}
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	f, err := audio.Open(c.Request.Context(), storage.Get(), config.Get().Audio.CacheDir, asset, speed)
+	if errors.Is(err, storage.ErrNotFound) {
+		utils.Warn("GetAudio - Object missing: AudioID=%d, Key=%s", asset.ID, asset.StorageKey)
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusNotFound, gin.H{"error": "音频文件不存在"})
+		return
+	}
+	if err != nil {
+		utils.Error("GetAudio - Open failed: AudioID=%d, Speed=%v: %v", asset.ID, speed, err)
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取音频失败"})
+		return
+	}
+	defer f.Close()
+
+	// ServeContent 处理 Range/If-Range/If-Modified-Since，并按已设置的 ETag 校验 If-None-Match 列表
+	c.Header("Content-Type", asset.ContentType)
+	http.ServeContent(c.Writer, c.Request, "", asset.CreatedAt, f)
+}
+
+// AudioURLResponse 签名音频地址
+type AudioURLResponse struct {
+	URL       string    `json:"url"`        // 带版本号的签名地址，无需 Authorization 头即可播放
+	ExpiresAt time.Time `json:"expires_at"` // 过期时间
+}
+
+// GetAudioURL 签发托管音频的播放地址
+// GET /api/audio/:id/url?speed=0.75
+func GetAudioURL(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("GetAudioURL - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	speed, err := audio.ParseSpeed(c.Query("speed"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	asset, ok := loadAudioAsset(c, "GetAudioURL")
+	if !ok {
+		return
+	}
+	// 受限音频的签名地址只由对应的业务接口（如测评播放）下发
+	if asset.Restricted {
+		c.JSON(http.StatusForbidden, gin.H{"error": "该音频只能通过签名地址播放"})
+		return
+	}
+
+	ttl := time.Duration(config.Get().Audio.SignedURLTTLMinutes) * time.Minute
+	if ttl <= 0 {
+		ttl = 6 * time.Hour
+	}
+	expires := time.Now().Add(ttl).Truncate(time.Second)
+	utils.Debug("GetAudioURL - UserID: %d, AudioID: %d, Speed: %v", userID, asset.ID, speed)
+	c.JSON(http.StatusOK, AudioURLResponse{URL: audio.SignedURL(asset, speed, expires), ExpiresAt: expires})
+}
+
+// loadAudioAsset 按路径参数加载托管音频，失败时直接写入错误响应
+func loadAudioAsset(c *gin.Context, caller string) (*models.AudioAsset, bool) {
+	audioID, ok := parseIDParam(c, "id")
+	if !ok {
+		return nil, false
+	}
+
+	var asset models.AudioAsset
+	err := database.GetDB().First(&asset, audioID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "音频不存在"})
+		return nil, false
+	}
+	if err != nil {
+		utils.Error("%s - Query failed: %v", caller, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return nil, false
+	}
+	return &asset, true
+}
@@ -2,11 +2,14 @@ package handlers
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 	"server/database"
 	"server/models"
+	"server/study"
 	"server/utils"
 )
 
@@ -25,8 +28,10 @@ type LoginRequest struct {
 
 // AuthResponse 认证响应结构
 type AuthResponse struct {
-	Token string      `json:"token"`
-	User  models.User `json:"user"`
+	Token        string      `json:"token"`         // 访问令牌
+	RefreshToken string      `json:"refresh_token"` // 刷新令牌
+	ExpiresIn    int64       `json:"expires_in"`    // 访问令牌有效期（秒）
+	User         models.User `json:"user"`
 }
 
 // Register 处理用户注册
@@ -72,8 +77,8 @@ func Register(c *gin.Context) {
 		return
 	}
 
-	// 生成JWT令牌
-	token, err := utils.GenerateToken(user.ID, user.Username)
+	// 生成访问令牌和刷新令牌
+	pair, err := issueTokenPair(database.GetDB(), c, &user, "")
 	if err != nil {
 		utils.Error("Register - Generate token failed: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成令牌失败"})
@@ -81,10 +86,7 @@ func Register(c *gin.Context) {
 	}
 
 	utils.Info("Register - User created successfully: ID=%d, Username=%s", user.ID, user.Username)
-	c.JSON(http.StatusCreated, AuthResponse{
-		Token: token,
-		User:  user,
-	})
+	c.JSON(http.StatusCreated, newAuthResponse(pair, user))
 }
 
 // Login 处理用户登录
@@ -114,8 +116,8 @@ func Login(c *gin.Context) {
 		return
 	}
 
-	// 生成JWT令牌
-	token, err := utils.GenerateToken(user.ID, user.Username)
+	// 生成访问令牌和刷新令牌
+	pair, err := issueTokenPair(database.GetDB(), c, &user, "")
 	if err != nil {
 		utils.Error("Login - Generate token failed: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成令牌失败"})
@@ -123,10 +125,7 @@ func Login(c *gin.Context) {
 	}
 
 	utils.Info("Login - Success: ID=%d, Username=%s", user.ID, user.Username)
-	c.JSON(http.StatusOK, AuthResponse{
-		Token: token,
-		User:  user,
-	})
+	c.JSON(http.StatusOK, newAuthResponse(pair, user))
 }
 
 // GetProfile 获取用户信息
@@ -152,9 +151,12 @@ func GetProfile(c *gin.Context) {
 }
 
 // UpdateProfileRequest 更新用户信息请求
+// 头像通过 POST /api/user/avatar 上传，不接受客户端提供的URL
 type UpdateProfileRequest struct {
-	Nickname string `json:"nickname"`
-	Avatar   string `json:"avatar"`
+	Nickname        string `json:"nickname"`
+	Timezone        string `json:"timezone"`          // IANA时区，如 Asia/Shanghai
+	DayRolloverHour *int   `json:"day_rollover_hour"` // 学习日切换时刻（0-23点），-1 恢复默认
+	StreakFreeze    *bool  `json:"streak_freeze"`     // 是否开启连续学习保护
 }
 
 // UpdateProfile 更新用户信息
@@ -174,7 +176,7 @@ func UpdateProfile(c *gin.Context) {
 		return
 	}
 
-	utils.Info("UpdateProfile - UserID: %v, Nickname: %s, Avatar: %s", userID, req.Nickname, req.Avatar)
+	utils.Info("UpdateProfile - UserID: %v, Nickname: %s", userID, req.Nickname)
 
 	var user models.User
 	if err := database.GetDB().First(&user, userID).Error; err != nil {
@@ -186,11 +188,42 @@ func UpdateProfile(c *gin.Context) {
 	if req.Nickname != "" {
 		user.Nickname = req.Nickname
 	}
-	if req.Avatar != "" {
-		user.Avatar = req.Avatar
+	if req.Timezone != "" {
+		if _, err := study.LoadTimezone(req.Timezone); err != nil {
+			utils.Warn("UpdateProfile - Invalid timezone: %s", req.Timezone)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "时区无效"})
+			return
+		}
+		user.Timezone = req.Timezone
+	}
+	if req.DayRolloverHour != nil {
+		switch hour := *req.DayRolloverHour; {
+		case hour == -1:
+			user.DayRolloverHour = nil
+		case hour >= 0 && hour <= 23:
+			user.DayRolloverHour = &hour
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "学习日切换时刻应在0-23之间"})
+			return
+		}
+	}
+	if req.StreakFreeze != nil {
+		user.StreakFreeze = *req.StreakFreeze
 	}
 
-	if err := database.GetDB().Save(&user).Error; err != nil {
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&user).Error; err != nil {
+			return err
+		}
+		// 日历设置变化后今天的学习日可能改变，重新汇总连续学习天数
+		stats, err := study.RecomputeStats(tx, user.ID, time.Now())
+		if err != nil {
+			return err
+		}
+		user.Stats = stats
+		return nil
+	})
+	if err != nil {
 		utils.Error("UpdateProfile - Update failed: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新失败"})
 		return
@@ -202,6 +235,7 @@ func UpdateProfile(c *gin.Context) {
 
 // UpdateLevel 更新用户等级
 // PUT /api/user/level
+// 口语分数由服务端根据跟读成绩计算，请求中的 speaking_score 会被忽略
 func UpdateLevel(c *gin.Context) {
 	userID, exists := c.Get("userID")
 	if !exists {
@@ -226,8 +260,16 @@ func UpdateLevel(c *gin.Context) {
 		return
 	}
 
-	user.Level = level
-	if err := database.GetDB().Save(&user).Error; err != nil {
+	// 口语分数只由跟读成绩汇总（shadowing.ApplyToUser）更新，忽略客户端提交的值
+	err := database.GetDB().Model(&user).Updates(map[string]interface{}{
+		"level_vocabulary_level": level.VocabularyLevel,
+		"level_vocabulary_score": level.VocabularyScore,
+		"level_listening_level":  level.ListeningLevel,
+		"level_listening_score":  level.ListeningScore,
+		"level_speaking_level":   level.SpeakingLevel,
+		"level_overall_level":    level.OverallLevel,
+	}).Error
+	if err != nil {
 		utils.Error("UpdateLevel - Update failed: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新失败"})
 		return
@@ -236,40 +278,3 @@ func UpdateLevel(c *gin.Context) {
 	utils.Info("UpdateLevel - Success: UserID=%v", userID)
 	c.JSON(http.StatusOK, user)
 }
-
-// UpdateStats 更新学习统计
-// PUT /api/user/stats
-func UpdateStats(c *gin.Context) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		utils.Warn("UpdateStats - User not authenticated")
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
-		return
-	}
-
-	var stats models.UserStats
-	if err := c.ShouldBindJSON(&stats); err != nil {
-		utils.Warn("UpdateStats - Invalid request: %v", err)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	utils.Info("UpdateStats - UserID: %v, Stats: %+v", userID, stats)
-
-	var user models.User
-	if err := database.GetDB().First(&user, userID).Error; err != nil {
-		utils.Error("UpdateStats - User not found: %v", userID)
-		c.JSON(http.StatusNotFound, gin.H{"error": "用户不存在"})
-		return
-	}
-
-	user.Stats = stats
-	if err := database.GetDB().Save(&user).Error; err != nil {
-		utils.Error("UpdateStats - Update failed: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新失败"})
-		return
-	}
-
-	utils.Info("UpdateStats - Success: UserID=%v", userID)
-	c.JSON(http.StatusOK, user)
-}
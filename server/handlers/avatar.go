@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"server/avatar"
+	"server/database"
+	"server/models"
+	"server/storage"
+	"server/utils"
+)
+
+// AvatarResponse 头像上传响应
+type AvatarResponse struct {
+	User       models.User    `json:"user"`
+	Thumbnails map[int]string `json:"thumbnails"` // 边长 -> 访问地址
+}
+
+// UploadAvatar 上传头像
+// POST /api/user/avatar
+// multipart表单: file 图片文件（JPEG/PNG/WebP）
+func UploadAvatar(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("UploadAvatar - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, avatar.MaxUploadSize+1<<20)
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.Warn("UploadAvatar - Missing file: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请上传图片文件"})
+		return
+	}
+	if fileHeader.Size > avatar.MaxUploadSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "图片文件过大"})
+		return
+	}
+	f, err := fileHeader.Open()
+	if err != nil {
+		utils.Error("UploadAvatar - Open upload failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取文件失败"})
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		utils.Error("UploadAvatar - Read upload failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取文件失败"})
+		return
+	}
+
+	thumbs, err := avatar.Process(data)
+	switch {
+	case errors.Is(err, avatar.ErrUnsupportedType):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, avatar.ErrInvalidImage), errors.Is(err, avatar.ErrTooSmall):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, avatar.ErrTooManyPixels):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		utils.Error("UploadAvatar - Process failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "处理图片失败"})
+		return
+	}
+
+	var user models.User
+	if err := database.GetDB().First(&user, userID).Error; err != nil {
+		utils.Error("UploadAvatar - User not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "用户不存在"})
+		return
+	}
+
+	version, err := avatar.NewVersion()
+	if err != nil {
+		utils.Error("UploadAvatar - Generate version failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存头像失败"})
+		return
+	}
+	ctx := c.Request.Context()
+	store := storage.Get()
+	dir := avatar.Dir(user.ID, version)
+	if err := avatar.Save(ctx, store, dir, thumbs); err != nil {
+		utils.Error("UploadAvatar - Store failed: %v", err)
+		if err := avatar.Remove(ctx, store, dir); err != nil {
+			utils.Warn("UploadAvatar - Cleanup failed: %v", err)
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存头像失败"})
+		return
+	}
+
+	oldDir := user.AvatarKey
+	user.Avatar = avatar.URL(user.ID, version, avatar.Sizes[len(avatar.Sizes)-1])
+	user.AvatarKey = dir
+	if err := database.GetDB().Model(&user).Select("avatar", "avatar_key").Updates(&user).Error; err != nil {
+		utils.Error("UploadAvatar - Update user failed: %v", err)
+		if err := avatar.Remove(ctx, store, dir); err != nil {
+			utils.Warn("UploadAvatar - Cleanup failed: %v", err)
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存头像失败"})
+		return
+	}
+	// 旧版本头像删除失败不影响本次上传
+	if oldDir != "" {
+		if err := avatar.Remove(ctx, store, oldDir); err != nil {
+			utils.Warn("UploadAvatar - Remove old avatar failed: %v", err)
+		}
+	}
+
+	resp := AvatarResponse{User: user, Thumbnails: make(map[int]string, len(avatar.Sizes))}
+	for _, size := range avatar.Sizes {
+		resp.Thumbnails[size] = avatar.URL(user.ID, version, size)
+	}
+	utils.Info("UploadAvatar - UserID: %d, Version: %s, Size: %d", user.ID, version, len(data))
+	c.JSON(http.StatusOK, resp)
+}
+
+// DeleteAvatar 删除头像
+// DELETE /api/user/avatar
+func DeleteAvatar(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("DeleteAvatar - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	var user models.User
+	if err := database.GetDB().First(&user, userID).Error; err != nil {
+		utils.Error("DeleteAvatar - User not found: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "用户不存在"})
+		return
+	}
+
+	oldDir := user.AvatarKey
+	user.Avatar = ""
+	user.AvatarKey = ""
+	if err := database.GetDB().Model(&user).Select("avatar", "avatar_key").Updates(&user).Error; err != nil {
+		utils.Error("DeleteAvatar - Update user failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除头像失败"})
+		return
+	}
+	if oldDir != "" {
+		if err := avatar.Remove(c.Request.Context(), storage.Get(), oldDir); err != nil {
+			utils.Warn("DeleteAvatar - Remove avatar failed: %v", err)
+		}
+	}
+
+	utils.Info("DeleteAvatar - UserID: %d", user.ID)
+	c.JSON(http.StatusOK, user)
+}
+
+// GetAvatar 获取头像缩略图
+// GET /api/avatars/:userId/:version/:file
+// 每次上传生成新版本号，同一URL的内容不会改变，因此允许长期缓存
+func GetAvatar(c *gin.Context) {
+	uid, err := strconv.ParseUint(c.Param("userId"), 10, 64)
+	version := c.Param("version")
+	if err != nil || !avatar.ValidVersion(version) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "头像不存在"})
+		return
+	}
+	size, err := strconv.Atoi(strings.TrimSuffix(c.Param("file"), ".jpg"))
+	if err != nil || !strings.HasSuffix(c.Param("file"), ".jpg") || !isAvatarSize(size) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "头像不存在"})
+		return
+	}
+
+	etag := fmt.Sprintf(`"%s-%d"`, version, size)
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	obj, err := storage.Get().Open(c.Request.Context(), avatar.Key(avatar.Dir(uint(uid), version), size))
+	if errors.Is(err, storage.ErrNotFound) {
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusNotFound, gin.H{"error": "头像不存在"})
+		return
+	}
+	if err != nil {
+		utils.Error("GetAvatar - Open failed: %v", err)
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取头像失败"})
+		return
+	}
+	defer obj.Body.Close()
+
+	c.DataFromReader(http.StatusOK, obj.Size, "image/jpeg", obj.Body, nil)
+}
+
+// isAvatarSize 检查是否为生成的缩略图尺寸
+func isAvatarSize(size int) bool {
+	for _, s := range avatar.Sizes {
+		if s == size {
+			return true
+		}
+	}
+	return false
+}
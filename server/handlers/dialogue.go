@@ -0,0 +1,402 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"server/config"
+	"server/database"
+	"server/dialogue"
+	"server/models"
+	"server/utils"
+)
+
+// 对话列表分页
+const (
+	defaultDialogueLimit = 20
+	maxDialogueLimit     = 100
+	// dialogueTimeout 单次生成回复的超时时间
+	dialogueTimeout = 60 * time.Second
+)
+
+// DialogueTurnRequest 学习者发言请求
+type DialogueTurnRequest struct {
+	Text        string `json:"text" binding:"required,max=1000"` // 发言内容，语音发言时为识别结果
+	RecordingID *uint  `json:"recording_id"`                     // 语音发言的录音（可选，需为本人上传）
+}
+
+// DialogueTurnResponse 一轮对话的结果
+type DialogueTurnResponse struct {
+	UserTurn  *models.DialogueTurn    `json:"user_turn"`
+	Reply     *models.DialogueTurn    `json:"reply"`
+	Session   *models.DialogueSession `json:"session"`   // 不含对话记录
+	Completed bool                    `json:"completed"` // 对话是否已结束
+}
+
+// ListDialogueScenarios 获取实战对话场景
+// GET /api/dialogues?difficulty=B1
+func ListDialogueScenarios(c *gin.Context) {
+	if _, exists := c.Get("userID"); !exists {
+		utils.Warn("ListDialogueScenarios - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	query := database.GetDB().Model(&models.DialogueScenario{}).Omit("lines")
+	if level := strings.ToUpper(c.Query("difficulty")); level != "" {
+		if !models.IsValidCEFRLevel(level) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "difficulty 参数无效"})
+			return
+		}
+		query = query.Where("difficulty = ?", level)
+	}
+	items := []models.DialogueScenario{}
+	if err := query.Order("id ASC").Find(&items).Error; err != nil {
+		utils.Error("ListDialogueScenarios - Query failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// GetDialogueScenario 获取场景详情（含剧本）
+// GET /api/dialogues/:scenario
+func GetDialogueScenario(c *gin.Context) {
+	if _, exists := c.Get("userID"); !exists {
+		utils.Warn("GetDialogueScenario - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+	scenario, ok := loadDialogueScenario(c, "GetDialogueScenario")
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, scenario)
+}
+
+// StartDialogue 开始一次实战对话，返回 AI 的开场白
+// POST /api/dialogues/:scenario/sessions
+func StartDialogue(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("StartDialogue - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+	scenario, ok := loadDialogueScenario(c, "StartDialogue")
+	if !ok {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), dialogueTimeout)
+	defer cancel()
+	session, err := dialogue.Start(ctx, database.GetDB(), dialogue.Get(), userID.(uint), scenario, time.Now())
+	if !handleDialogueError(c, "StartDialogue", err) {
+		return
+	}
+
+	utils.Info("StartDialogue - UserID: %v, Scenario: %s, SessionID: %d, Engine: %s",
+		userID, scenario.Slug, session.ID, session.Engine)
+	c.JSON(http.StatusCreated, session)
+}
+
+// ListDialogueSessions 获取在该场景下的对话记录
+// GET /api/dialogues/:scenario/sessions?limit=20&offset=0
+func ListDialogueSessions(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("ListDialogueSessions - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+	scenario, ok := loadDialogueScenario(c, "ListDialogueSessions")
+	if !ok {
+		return
+	}
+	limit, offset, ok := parsePagination(c, defaultDialogueLimit, maxDialogueLimit)
+	if !ok {
+		return
+	}
+
+	query := database.GetDB().Model(&models.DialogueSession{}).Where("user_id = ? AND scenario_id = ?", userID, scenario.ID)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		utils.Error("ListDialogueSessions - Count failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	items := []models.DialogueSession{}
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&items).Error; err != nil {
+		utils.Error("ListDialogueSessions - Query failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"total": total, "items": items})
+}
+
+// GetDialogueSession 获取对话及完整记录
+// GET /api/dialogues/:scenario/sessions/:id
+func GetDialogueSession(c *gin.Context) {
+	_, session, ok := loadDialogueSession(c, "GetDialogueSession")
+	if !ok {
+		return
+	}
+	if err := loadDialogueTurns(database.GetDB(), session); err != nil {
+		utils.Error("GetDialogueSession - Query turns failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
+
+// PostDialogueTurn 学习者发言，返回 AI 的回复
+// POST /api/dialogues/:scenario/sessions/:id/turns
+// 剧本完成、大模型判断目标达成或发言次数达到上限时对话自动结束
+func PostDialogueTurn(c *gin.Context) {
+	scenario, session, ok := loadDialogueSession(c, "PostDialogueTurn")
+	if !ok {
+		return
+	}
+
+	var req DialogueTurnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Warn("PostDialogueTurn - Invalid request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "发言内容不能为空"})
+		return
+	}
+
+	db := database.GetDB()
+	if req.RecordingID != nil {
+		var count int64
+		err := db.Model(&models.Recording{}).Where("id = ? AND user_id = ?", *req.RecordingID, session.UserID).Count(&count).Error
+		if err != nil {
+			utils.Error("PostDialogueTurn - Query recording failed: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+			return
+		}
+		if count == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "录音不存在"})
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), dialogueTimeout)
+	defer cancel()
+	userTurn, reply, err := dialogue.Say(ctx, db, dialogue.Get(), session, scenario, text, req.RecordingID,
+		config.Get().Dialogue.MaxTurns, time.Now())
+	if !handleDialogueError(c, "PostDialogueTurn", err) {
+		return
+	}
+
+	if err := db.First(session, session.ID).Error; err != nil {
+		utils.Error("PostDialogueTurn - Reload session failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	if userTurn.RecordingID != nil {
+		dialogue.NotifyScoring()
+	}
+	completed := session.Status == models.DialogueStatusCompleted
+	utils.Debug("PostDialogueTurn - SessionID: %d, Seq: %d, Engine: %s, Completed: %v", session.ID, userTurn.Seq, reply.Engine, completed)
+	c.JSON(http.StatusOK, DialogueTurnResponse{UserTurn: userTurn, Reply: reply, Session: session, Completed: completed})
+}
+
+// FinishDialogue 结束对话
+// POST /api/dialogues/:scenario/sessions/:id/finish
+func FinishDialogue(c *gin.Context) {
+	_, session, ok := loadDialogueSession(c, "FinishDialogue")
+	if !ok {
+		return
+	}
+
+	if !handleDialogueError(c, "FinishDialogue", dialogue.Finish(database.GetDB(), session, time.Now())) {
+		return
+	}
+
+	utils.Info("FinishDialogue - UserID: %d, SessionID: %d, UserTurns: %d", session.UserID, session.ID, session.UserTurns)
+	c.JSON(http.StatusOK, session)
+}
+
+// DialogueHintResponse 救命按钮的建议回答
+type DialogueHintResponse struct {
+	*models.DialogueHint
+	HintsUsed int `json:"hints_used"` // 本次对话已求助的轮数
+}
+
+// GetDialogueHint 救命按钮：为当前这一轮给出简单、自然、进阶三种建议回答
+// POST /api/dialogues/sessions/:id/hint
+// 建议参考场景剧本，并按学习者的词汇等级推荐难度；同一轮重复求助返回相同建议，只计一次
+func GetDialogueHint(c *gin.Context) {
+	scenario, session, ok := loadDialogueSessionByID(c, "GetDialogueHint")
+	if !ok {
+		return
+	}
+
+	db := database.GetDB()
+	var user models.User
+	if err := db.Select("id", "level_vocabulary_level").First(&user, session.UserID).Error; err != nil {
+		utils.Error("GetDialogueHint - Query user failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), dialogueTimeout)
+	defer cancel()
+	hint, err := dialogue.Hint(ctx, db, dialogue.Get(), session, scenario, user.Level.VocabularyLevel)
+	if !handleDialogueError(c, "GetDialogueHint", err) {
+		return
+	}
+
+	utils.Debug("GetDialogueHint - SessionID: %d, Seq: %d, Engine: %s, HintsUsed: %d", session.ID, hint.Seq, hint.Engine, session.HintsUsed)
+	c.JSON(http.StatusOK, DialogueHintResponse{DialogueHint: hint, HintsUsed: session.HintsUsed})
+}
+
+// GetDialogueReport 对话复盘报告
+// GET /api/dialogues/sessions/:id/report
+// 包含做得好的地方、语法问题及改正、剧本中更地道的说法，以及各轮语音发言评分的汇总；对话结束后才能查看
+// 语音发言由后台任务评分，pending_scores 不为 0 时稍后再查看可得到完整评分
+func GetDialogueReport(c *gin.Context) {
+	scenario, session, ok := loadDialogueSessionByID(c, "GetDialogueReport")
+	if !ok {
+		return
+	}
+	if session.Status != models.DialogueStatusCompleted {
+		c.JSON(http.StatusConflict, gin.H{"error": "对话尚未结束"})
+		return
+	}
+
+	db := database.GetDB()
+	if err := loadDialogueTurns(db, session); err != nil {
+		utils.Error("GetDialogueReport - Query turns failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	var hints []models.DialogueHint
+	if err := db.Where("session_id = ?", session.ID).Find(&hints).Error; err != nil {
+		utils.Error("GetDialogueReport - Query hints failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dialogue.BuildReport(session, scenario, session.Turns, hints))
+}
+
+// handleDialogueError 将对话错误写入响应，无错误时返回 true
+func handleDialogueError(c *gin.Context, caller string, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, dialogue.ErrSessionFinished), errors.Is(err, dialogue.ErrTurnConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, dialogue.ErrNoScript), errors.Is(err, dialogue.ErrEmptyReply):
+		utils.Warn("%s - %v", caller, err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	case errors.Is(err, context.DeadlineExceeded):
+		utils.Warn("%s - Reply timed out", caller)
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": "对话服务响应超时"})
+	default:
+		utils.Error("%s - Failed: %v", caller, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "对话服务不可用"})
+	}
+	return false
+}
+
+// loadDialogueScenario 按路径参数中的场景标识加载场景，失败时直接写入错误响应
+func loadDialogueScenario(c *gin.Context, caller string) (*models.DialogueScenario, bool) {
+	var scenario models.DialogueScenario
+	err := database.GetDB().Where("slug = ?", c.Param("scenario")).First(&scenario).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "对话场景不存在"})
+		return nil, false
+	}
+	if err != nil {
+		utils.Error("%s - Query scenario failed: %v", caller, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return nil, false
+	}
+	return &scenario, true
+}
+
+// loadDialogueSession 校验登录并加载本人在该场景下的对话，失败时直接写入错误响应
+func loadDialogueSession(c *gin.Context, caller string) (*models.DialogueScenario, *models.DialogueSession, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("%s - User not authenticated", caller)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return nil, nil, false
+	}
+	scenario, ok := loadDialogueScenario(c, caller)
+	if !ok {
+		return nil, nil, false
+	}
+	sessionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return nil, nil, false
+	}
+
+	var session models.DialogueSession
+	err := database.GetDB().Where("user_id = ? AND scenario_id = ?", userID, scenario.ID).First(&session, sessionID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "对话不存在"})
+		return nil, nil, false
+	}
+	if err != nil {
+		utils.Error("%s - Query session failed: %v", caller, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return nil, nil, false
+	}
+	return scenario, &session, true
+}
+
+// loadDialogueSessionByID 校验登录并按ID加载本人的对话及其场景，失败时直接写入错误响应
+func loadDialogueSessionByID(c *gin.Context, caller string) (*models.DialogueScenario, *models.DialogueSession, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("%s - User not authenticated", caller)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return nil, nil, false
+	}
+	sessionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return nil, nil, false
+	}
+
+	db := database.GetDB()
+	var session models.DialogueSession
+	err := db.Where("user_id = ?", userID).First(&session, sessionID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "对话不存在"})
+		return nil, nil, false
+	}
+	if err != nil {
+		utils.Error("%s - Query session failed: %v", caller, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return nil, nil, false
+	}
+	// 场景被删除后历史对话仍可查看
+	var scenario models.DialogueScenario
+	if err := db.Unscoped().First(&scenario, session.ScenarioID).Error; err != nil {
+		utils.Error("%s - Query scenario failed: %v", caller, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return nil, nil, false
+	}
+	return &scenario, &session, true
+}
+
+// loadDialogueTurns 加载对话记录，按发言顺序排列
+func loadDialogueTurns(db *gorm.DB, session *models.DialogueSession) error {
+	session.Turns = []models.DialogueTurn{}
+	return db.Where("session_id = ?", session.ID).Order("seq ASC").Find(&session.Turns).Error
+}
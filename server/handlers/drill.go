@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"server/database"
+	"server/drill"
+	"server/models"
+	"server/utils"
+)
+
+var errDrillNotFound = errors.New("drill session not found")
+
+// PhenomenonStat 语流音变现象及用户的练习情况
+type PhenomenonStat struct {
+	models.SpeechPhenomenon
+	Attempts       int        `json:"attempts"`
+	Errors         int        `json:"errors"`
+	ErrorRate      float64    `json:"error_rate"`       // 平滑错误率，决定训练时的出题比例
+	LastPracticeAt *time.Time `json:"last_practice_at"` // 没练过时为空
+	Items          int        `json:"items"`            // 题库中可用的题数
+}
+
+// StartDrillRequest 开始训练请求
+type StartDrillRequest struct {
+	Category string `json:"category"` // liaison / weak_form / elision，为空表示综合训练
+	Count    int    `json:"count"`    // 题数，默认 10
+}
+
+// DrillSessionResponse 训练会话响应
+type DrillSessionResponse struct {
+	Session models.DrillSession       `json:"session"`
+	Items   []models.DrillSessionItem `json:"items"`
+}
+
+// DrillAnswerRequest 训练作答请求
+type DrillAnswerRequest struct {
+	ItemID      uint `json:"item_id" binding:"required"`
+	AnswerIndex *int `json:"answer_index" binding:"required"` // 选项下标，-1 表示没听清
+}
+
+// DrillAnswerResponse 训练作答响应
+type DrillAnswerResponse struct {
+	Correct      bool                           `json:"correct"`
+	CorrectIndex int                            `json:"correct_index"`
+	Text         string                         `json:"text"`  // 作答后公布片段原文
+	Focus        string                         `json:"focus"` // 作答后公布考查点
+	Session      models.DrillSession            `json:"session"`
+	Result       map[string]drill.CategoryScore `json:"result,omitempty"`  // 训练结束时返回各分项得分
+	Profile      *models.ListeningProfile       `json:"profile,omitempty"` // 训练结束且已有听力分项水平时返回更新后的结果
+}
+
+// ListSpeechPhenomena 获取语流音变现象分类及用户在各现象上的练习情况
+// GET /api/listening/drills/phenomena?category=liaison
+func ListSpeechPhenomena(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("ListSpeechPhenomena - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	category := c.Query("category")
+	if category != "" && !models.IsValidDrillCategory(category) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "训练分项无效"})
+		return
+	}
+
+	db := database.GetDB()
+	stats, err := drill.LoadStats(db, userID.(uint))
+	if err != nil {
+		utils.Error("ListSpeechPhenomena - Query stats failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	items, err := drill.CountItems(db)
+	if err != nil {
+		utils.Error("ListSpeechPhenomena - Count items failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	result := make([]PhenomenonStat, 0, len(models.SpeechPhenomena))
+	for _, p := range models.SpeechPhenomena {
+		if category != "" && p.Category != category {
+			continue
+		}
+		s := stats[p.Code]
+		entry := PhenomenonStat{
+			SpeechPhenomenon: p,
+			Attempts:         s.Attempts,
+			Errors:           s.Errors,
+			ErrorRate:        drill.ErrorRate(s),
+			Items:            items[p.Code],
+		}
+		if s.Attempts > 0 {
+			entry.LastPracticeAt = &s.LastPracticeAt
+		}
+		result = append(result, entry)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"phenomena": result})
+}
+
+// StartDrill 开始语流音变训练
+// POST /api/listening/drills
+// 按用户在各现象上的错误率抽题，进行中的旧训练会被放弃
+func StartDrill(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("StartDrill - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	var req StartDrillRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.Warn("StartDrill - Invalid request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Category != "" && !models.IsValidDrillCategory(req.Category) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "训练分项无效"})
+		return
+	}
+	if req.Count == 0 {
+		req.Count = drill.DefaultItems
+	}
+	if req.Count < 1 || req.Count > drill.MaxItems {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "题数无效"})
+		return
+	}
+
+	var resp DrillSessionResponse
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.DrillSession{}).
+			Where("user_id = ? AND status = ?", userID, models.DrillStatusActive).
+			Update("status", models.DrillStatusAbandoned).Error; err != nil {
+			return err
+		}
+
+		items, err := drill.Schedule(tx, userID.(uint), req.Category, req.Count)
+		if err != nil {
+			return err
+		}
+		resp.Session = models.DrillSession{
+			UserID:    userID.(uint),
+			Category:  req.Category,
+			Status:    models.DrillStatusActive,
+			ItemCount: len(items),
+		}
+		if err := tx.Create(&resp.Session).Error; err != nil {
+			return err
+		}
+		for i := range items {
+			items[i].SessionID = resp.Session.ID
+		}
+		if err := tx.Create(&items).Error; err != nil {
+			return err
+		}
+		resp.Items = items
+		return nil
+	})
+	if errors.Is(err, drill.ErrBankTooSmall) {
+		utils.Warn("StartDrill - %v: Category=%s", err, req.Category)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "训练题库尚未准备好"})
+		return
+	}
+	if err != nil {
+		utils.Error("StartDrill - Create session failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "创建训练失败"})
+		return
+	}
+
+	utils.Info("StartDrill - UserID: %v, SessionID: %d, Items: %d", userID, resp.Session.ID, len(resp.Items))
+	c.JSON(http.StatusCreated, resp)
+}
+
+// GetDrill 获取训练状态和题目
+// GET /api/listening/drills/:id
+func GetDrill(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("GetDrill - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	sessionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	db := database.GetDB()
+	session, err := loadDrillSession(db, userID.(uint), sessionID)
+	if errors.Is(err, errDrillNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "训练不存在"})
+		return
+	}
+	if err != nil {
+		utils.Error("GetDrill - Query session failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	resp := DrillSessionResponse{Session: *session}
+	if err := db.Where("session_id = ?", session.ID).Order("seq ASC").Find(&resp.Items).Error; err != nil {
+		utils.Error("GetDrill - Query items failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// AnswerDrillItem 提交训练答案
+// POST /api/listening/drills/:id/answer
+// 每题更新对应现象的错误率；完成全部题目后更新听力分项水平（连读、弱读、吞音），未做过听力测试时不更新
+func AnswerDrillItem(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("AnswerDrillItem - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	sessionID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req DrillAnswerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Warn("AnswerDrillItem - Invalid request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now()
+	var resp DrillAnswerResponse
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		session, err := loadDrillSession(tx, userID.(uint), sessionID)
+		if err != nil {
+			return err
+		}
+		if session.Status != models.DrillStatusActive {
+			return drill.ErrSessionFinished
+		}
+
+		var item models.DrillSessionItem
+		if err := tx.Where("id = ? AND session_id = ? AND answer_index IS NULL", req.ItemID, session.ID).
+			First(&item).Error; err != nil {
+			return drill.ErrItemMismatch
+		}
+
+		answer := *req.AnswerIndex
+		if answer < -1 || answer >= len(item.Options) {
+			answer = -1
+		}
+		correct := answer == item.CorrectIndex
+		item.AnswerIndex = &answer
+		item.Correct = &correct
+		item.AnsweredAt = &now
+		if err := tx.Save(&item).Error; err != nil {
+			return err
+		}
+		if err := drill.RecordAnswer(tx, session.UserID, item.Phenomenon, correct, now); err != nil {
+			return err
+		}
+		resp.Correct = correct
+		resp.CorrectIndex = item.CorrectIndex
+
+		var source models.DrillItem
+		if err := tx.Unscoped().First(&source, item.ItemID).Error; err == nil {
+			resp.Text = source.Text
+			resp.Focus = source.Focus
+		}
+
+		var items []models.DrillSessionItem
+		if err := tx.Where("session_id = ?", session.ID).Find(&items).Error; err != nil {
+			return err
+		}
+		session.Answered, session.Correct = 0, 0
+		for _, it := range items {
+			if it.AnswerIndex != nil {
+				session.Answered++
+			}
+			if it.Correct != nil && *it.Correct {
+				session.Correct++
+			}
+		}
+
+		if session.Answered >= session.ItemCount {
+			session.Status = models.DrillStatusCompleted
+			session.CompletedAt = &now
+			resp.Result = drill.Score(items)
+
+			// 听力分项水平由听力测试建立，没做过测试时只记录训练结果
+			var profile models.ListeningProfile
+			err := tx.Where("user_id = ?", session.UserID).First(&profile).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if err == nil {
+				drill.ApplyToProfile(&profile, resp.Result)
+				if err := tx.Save(&profile).Error; err != nil {
+					return err
+				}
+				resp.Profile = &profile
+			}
+		}
+		if err := tx.Save(session).Error; err != nil {
+			return err
+		}
+		resp.Session = *session
+		return nil
+	})
+	switch {
+	case errors.Is(err, errDrillNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "训练不存在"})
+		return
+	case errors.Is(err, drill.ErrSessionFinished), errors.Is(err, drill.ErrItemMismatch):
+		utils.Warn("AnswerDrillItem - %v: SessionID=%d, ItemID=%d", err, sessionID, req.ItemID)
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		utils.Error("AnswerDrillItem - Save answer failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "提交答案失败"})
+		return
+	}
+
+	if resp.Result != nil {
+		utils.Info("AnswerDrillItem - Completed: UserID=%v, SessionID=%d, Correct=%d/%d",
+			userID, sessionID, resp.Session.Correct, resp.Session.ItemCount)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// loadDrillSession 获取属于用户的训练会话
+func loadDrillSession(tx *gorm.DB, userID uint, sessionID uint) (*models.DrillSession, error) {
+	var session models.DrillSession
+	err := tx.Where("id = ? AND user_id = ?", sessionID, userID).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errDrillNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
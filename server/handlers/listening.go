@@ -0,0 +1,554 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"server/cloze"
+	"server/config"
+	"server/database"
+	"server/dictation"
+	"server/listening"
+	"server/models"
+	"server/utils"
+)
+
+// 听力材料列表分页
+const (
+	defaultMaterialLimit = 20
+	maxMaterialLimit     = 100
+)
+
+// ListeningMaterialListResponse 听力材料列表响应
+type ListeningMaterialListResponse struct {
+	Total int64                      `json:"total"`
+	Items []models.ListeningMaterial `json:"items"` // 不含句子
+}
+
+// ListListeningMaterials 获取听力材料目录
+// GET /api/listening/materials?kind=listening&difficulty=A2,B1&tag=news&tag=daily&limit=20&offset=0
+// kind 为 listening 或 shadowing，不传返回全部；difficulty 可传多个等级（逗号分隔）；tag 可重复，需同时具备全部标签
+func ListListeningMaterials(c *gin.Context) {
+	if _, exists := c.Get("userID"); !exists {
+		utils.Warn("ListListeningMaterials - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	limit, offset, ok := parsePagination(c, defaultMaterialLimit, maxMaterialLimit)
+	if !ok {
+		return
+	}
+
+	db := database.GetDB()
+	query := db.Model(&models.ListeningMaterial{})
+	if kind := c.Query("kind"); kind != "" {
+		if !models.IsValidMaterialKind(kind) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "kind 参数无效"})
+			return
+		}
+		query = query.Where("kind = ?", kind)
+	}
+	if s := c.Query("difficulty"); s != "" {
+		levels := strings.Split(s, ",")
+		for i, l := range levels {
+			levels[i] = strings.ToUpper(strings.TrimSpace(l))
+			if !models.IsValidCEFRLevel(levels[i]) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "difficulty 参数无效"})
+				return
+			}
+		}
+		query = query.Where("difficulty IN ?", levels)
+	}
+	if raw := c.QueryArray("tag"); len(raw) > 0 {
+		tags, err := listening.NormalizeTags(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if len(tags) > 0 {
+			sub := db.Model(&models.ListeningMaterialTag{}).Select("material_id").
+				Where("tag IN ?", tags).Group("material_id").Having("COUNT(*) = ?", len(tags))
+			query = query.Where("id IN (?)", sub)
+		}
+	}
+
+	var resp ListeningMaterialListResponse
+	if err := query.Count(&resp.Total).Error; err != nil {
+		utils.Error("ListListeningMaterials - Count failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&resp.Items).Error; err != nil {
+		utils.Error("ListListeningMaterials - Query failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	if err := listening.LoadTags(db, resp.Items); err != nil {
+		utils.Error("ListListeningMaterials - Load tags failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetListeningMaterial 获取听力材料详情（含句子时间轴）
+// GET /api/listening/materials/:id
+func GetListeningMaterial(c *gin.Context) {
+	if _, exists := c.Get("userID"); !exists {
+		utils.Warn("GetListeningMaterial - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	materialID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	material, err := loadListeningMaterial(database.GetDB(), materialID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "听力材料不存在"})
+		return
+	}
+	if err != nil {
+		utils.Error("GetListeningMaterial - Query failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, material)
+}
+
+// loadListeningMaterial 加载材料及其句子和标签
+func loadListeningMaterial(db *gorm.DB, materialID uint) (*models.ListeningMaterial, error) {
+	var material models.ListeningMaterial
+	err := db.Preload("Sentences", func(tx *gorm.DB) *gorm.DB {
+		return tx.Order("seq ASC")
+	}).First(&material, materialID).Error
+	if err != nil {
+		return nil, err
+	}
+	if material.Sentences == nil {
+		material.Sentences = []models.ListeningSentence{}
+	}
+	materials := []models.ListeningMaterial{material}
+	if err := listening.LoadTags(db, materials); err != nil {
+		return nil, err
+	}
+	return &materials[0], nil
+}
+
+// RecordListeningProgressRequest 提交练习结果请求
+type RecordListeningProgressRequest struct {
+	Layer   int                        `json:"layer" binding:"required,min=1,max=3"` // 1: 字幕全开 2: 关键词保留 3: 无字幕
+	Results []listening.SentenceResult `json:"results" binding:"required,min=1,max=500,dive"`
+}
+
+// ListListeningProgress 获取练习过的听力材料及进度，供听力首页续练
+// GET /api/listening/progress?status=in_progress|completed&limit=20&offset=0
+func ListListeningProgress(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("ListListeningProgress - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	limit, offset, ok := parsePagination(c, defaultMaterialLimit, maxMaterialLimit)
+	if !ok {
+		return
+	}
+
+	query := database.GetDB().Model(&models.ListeningProgress{}).Where("user_id = ?", userID)
+	switch c.Query("status") {
+	case "":
+	case "in_progress":
+		query = query.Where("completed_at IS NULL")
+	case "completed":
+		query = query.Where("completed_at IS NOT NULL")
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status 参数无效"})
+		return
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		utils.Error("ListListeningProgress - Count failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	var items []models.ListeningProgress
+	if err := query.Preload("Material").Order("last_practice_at DESC").Limit(limit).Offset(offset).Find(&items).Error; err != nil {
+		utils.Error("ListListeningProgress - Query failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"total": total, "items": items})
+}
+
+// GetListeningProgress 获取材料的三层剥离进度及续练位置
+// GET /api/listening/materials/:id/progress
+func GetListeningProgress(c *gin.Context) {
+	userID, material, ok := loadMaterialForProgress(c, "GetListeningProgress")
+	if !ok {
+		return
+	}
+
+	state, err := listening.LoadState(database.GetDB(), userID, material, config.Get().Listening.L3UnlockAccuracy)
+	if err != nil {
+		utils.Error("GetListeningProgress - Load failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+// RecordListeningProgress 提交某一层级的逐句练习结果
+// POST /api/listening/materials/:id/progress
+// L1 仅记录完成；L2 提交填空补全后的整句，L3 提交听写内容，由服务端批改是否听对；L2 全部完成且正确率达标后解锁 L3
+func RecordListeningProgress(c *gin.Context) {
+	userID, material, ok := loadMaterialForProgress(c, "RecordListeningProgress")
+	if !ok {
+		return
+	}
+
+	var req RecordListeningProgressRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Warn("RecordListeningProgress - Invalid request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	threshold := config.Get().Listening.L3UnlockAccuracy
+	db := database.GetDB()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		_, err := listening.Record(tx, userID, material, req.Layer, req.Results, threshold, time.Now())
+		return err
+	})
+	switch {
+	case errors.Is(err, listening.ErrLayerLocked):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, listening.ErrInvalidResult):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		utils.Error("RecordListeningProgress - Record failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存进度失败"})
+		return
+	}
+
+	state, err := listening.LoadState(db, userID, material, threshold)
+	if err != nil {
+		utils.Error("RecordListeningProgress - Load failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	utils.Info("RecordListeningProgress - UserID: %d, MaterialID: %d, Layer: %d, Results: %d, Unlocked: L%d",
+		userID, material.ID, req.Layer, len(req.Results), state.UnlockedLayer)
+	c.JSON(http.StatusOK, state)
+}
+
+// RecordSpeedStageRequest 提交四阶训练阶段得分请求
+type RecordSpeedStageRequest struct {
+	Score *int `json:"score" binding:"required,min=0,max=100"` // 本次练习得分
+}
+
+// GetSpeedStages 获取材料的四阶训练进度
+// GET /api/listening/materials/:id/stages
+// 依次为慢速分解 0.75x、常速关键词 1.0x、常速全句 1.0x、快速挑战 1.25x，通过前一阶段后解锁下一阶段
+func GetSpeedStages(c *gin.Context) {
+	userID, material, ok := loadMaterialForProgress(c, "GetSpeedStages")
+	if !ok {
+		return
+	}
+
+	stages, err := listening.LoadStages(database.GetDB(), userID, material)
+	if err != nil {
+		utils.Error("GetSpeedStages - Load failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stages": stages, "pass_score": config.Get().Listening.StagePassScore})
+}
+
+// RecordSpeedStage 提交四阶训练某一阶段的得分
+// POST /api/listening/materials/:id/stages/:stage
+// 得分达到通过分数即通过该阶段；前一阶段未通过时返回 403
+func RecordSpeedStage(c *gin.Context) {
+	userID, material, ok := loadMaterialForProgress(c, "RecordSpeedStage")
+	if !ok {
+		return
+	}
+
+	var req RecordSpeedStageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Warn("RecordSpeedStage - Invalid request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stage := c.Param("stage")
+	passScore := config.Get().Listening.StagePassScore
+	db := database.GetDB()
+	err := db.Transaction(func(tx *gorm.DB) error {
+		return listening.RecordStage(tx, userID, material, stage, *req.Score, passScore, time.Now())
+	})
+	switch {
+	case errors.Is(err, listening.ErrUnknownStage):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, listening.ErrStageLocked):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		utils.Error("RecordSpeedStage - Record failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存进度失败"})
+		return
+	}
+
+	stages, err := listening.LoadStages(db, userID, material)
+	if err != nil {
+		utils.Error("RecordSpeedStage - Load failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	utils.Info("RecordSpeedStage - UserID: %d, MaterialID: %d, Stage: %s, Score: %d", userID, material.ID, stage, *req.Score)
+	c.JSON(http.StatusOK, gin.H{"stages": stages, "pass_score": passScore})
+}
+
+// loadMaterialForProgress 校验登录并加载材料，失败时直接写入错误响应
+func loadMaterialForProgress(c *gin.Context, caller string) (uint, *models.ListeningMaterial, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("%s - User not authenticated", caller)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return 0, nil, false
+	}
+
+	materialID, ok := parseIDParam(c, "id")
+	if !ok {
+		return 0, nil, false
+	}
+
+	var material models.ListeningMaterial
+	err := database.GetDB().First(&material, materialID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "听力材料不存在"})
+		return 0, nil, false
+	}
+	if err != nil {
+		utils.Error("%s - Query failed: %v", caller, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return 0, nil, false
+	}
+	return userID.(uint), &material, true
+}
+
+// clozePoolWindow 干扰项取自前后若干句
+const clozePoolWindow = 10
+
+// ClozeResponse 句子挖空练习
+type ClozeResponse struct {
+	SentenceID  uint    `json:"sentence_id"`
+	MaterialID  uint    `json:"material_id"`
+	Seq         int     `json:"seq"`
+	StartTime   float64 `json:"start_time"`
+	EndTime     float64 `json:"end_time"`
+	Translation string  `json:"translation"`
+	Attempt     int     `json:"attempt"`
+	Level       string  `json:"level"`
+	*cloze.Cloze
+}
+
+// GetSentenceCloze 生成 L2 关键词挖空练习
+// GET /api/listening/sentences/:id/cloze?attempt=1&level=B1
+// 同一用户、句子和 attempt 返回相同结果；level 默认取用户的听力等级
+func GetSentenceCloze(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("GetSentenceCloze - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	sentenceID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	attempt := 1
+	if s := c.Query("attempt"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "attempt 参数无效"})
+			return
+		}
+		attempt = n
+	}
+
+	db := database.GetDB()
+	var sentence models.ListeningSentence
+	err := db.First(&sentence, sentenceID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "句子不存在"})
+		return
+	}
+	if err != nil {
+		utils.Error("GetSentenceCloze - Query sentence failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	level := strings.ToUpper(c.Query("level"))
+	if level != "" && !models.IsValidCEFRLevel(level) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "level 参数无效"})
+		return
+	}
+	if level == "" {
+		var user models.User
+		if err := db.Select("id", "level_listening_level").First(&user, userID).Error; err != nil {
+			utils.Error("GetSentenceCloze - Query user failed: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+			return
+		}
+		level = user.Level.ListeningLevel
+	}
+
+	known, err := knownWords(db, userID.(uint), sentence.Text)
+	if err != nil {
+		utils.Error("GetSentenceCloze - Query known words failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	var neighbors []models.ListeningSentence
+	err = db.Select("text").
+		Where("material_id = ? AND seq <> ? AND seq BETWEEN ? AND ?", sentence.MaterialID, sentence.Seq,
+			sentence.Seq-clozePoolWindow, sentence.Seq+clozePoolWindow).
+		Order("seq ASC").Find(&neighbors).Error
+	if err != nil {
+		utils.Error("GetSentenceCloze - Query neighbors failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	var pool []string
+	for _, s := range neighbors {
+		pool = append(pool, cloze.ContentWords(s.Text)...)
+	}
+
+	result := cloze.Generate(sentence.Text, cloze.Options{
+		Level:    level,
+		Keywords: sentence.Keywords,
+		Known:    known,
+		Pool:     pool,
+		Seed:     cloze.Seed(userID.(uint), sentence.ID, attempt),
+	})
+
+	c.JSON(http.StatusOK, ClozeResponse{
+		SentenceID:  sentence.ID,
+		MaterialID:  sentence.MaterialID,
+		Seq:         sentence.Seq,
+		StartTime:   sentence.StartTime,
+		EndTime:     sentence.EndTime,
+		Translation: sentence.Translation,
+		Attempt:     attempt,
+		Level:       level,
+		Cloze:       result,
+	})
+}
+
+// knownWords 查询句子中用户已学过（认识或模糊）的单词，返回小写原形集合
+func knownWords(db *gorm.DB, userID uint, text string) (map[string]bool, error) {
+	var forms []string
+	for _, t := range cloze.Tokenize(text) {
+		if t.Word && cloze.IsContentWord(t.Text) {
+			forms = append(forms, cloze.Lemmas(t.Text)...)
+		}
+	}
+	known := make(map[string]bool)
+	if len(forms) == 0 {
+		return known, nil
+	}
+
+	var headwords []string
+	err := db.Model(&models.UserWordProgress{}).
+		Joins("JOIN words ON words.id = user_word_progress.word_id").
+		Where("user_word_progress.user_id = ? AND user_word_progress.status IN ? AND LOWER(words.headword) IN ?",
+			userID, []string{models.WordStatusKnown, models.WordStatusFuzzy}, forms).
+		Pluck("LOWER(words.headword)", &headwords).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, w := range headwords {
+		known[w] = true
+	}
+	return known, nil
+}
+
+// DictationRequest 提交听写请求
+type DictationRequest struct {
+	Text string `json:"text" binding:"max=2000"` // 用户听写的内容，可为空（一个词都没听出来）
+}
+
+// DictationResponse 听写批改结果
+type DictationResponse struct {
+	SentenceID  uint   `json:"sentence_id"`
+	MaterialID  uint   `json:"material_id"`
+	Seq         int    `json:"seq"`
+	Text        string `json:"text"`
+	Translation string `json:"translation"`
+	*dictation.Result
+}
+
+// GradeDictation 批改 L3 听写
+// POST /api/listening/sentences/:id/dictation
+// 忽略大小写和标点，缩写与弱读形式（如 wanna/want to）视为相同；返回逐词差异和正确率
+func GradeDictation(c *gin.Context) {
+	if _, exists := c.Get("userID"); !exists {
+		utils.Warn("GradeDictation - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	sentenceID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	var req DictationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Warn("GradeDictation - Invalid request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var sentence models.ListeningSentence
+	err := database.GetDB().First(&sentence, sentenceID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "句子不存在"})
+		return
+	}
+	if err != nil {
+		utils.Error("GradeDictation - Query sentence failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, DictationResponse{
+		SentenceID:  sentence.ID,
+		MaterialID:  sentence.MaterialID,
+		Seq:         sentence.Seq,
+		Text:        sentence.Text,
+		Translation: sentence.Translation,
+		Result:      dictation.Grade(sentence.Text, req.Text),
+	})
+}
@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"server/config"
+	"server/database"
+	"server/models"
+	"server/recording"
+	"server/storage"
+	"server/utils"
+)
+
+// 录音列表分页
+const (
+	defaultRecordingLimit = 20
+	maxRecordingLimit     = 100
+)
+
+// RecordingListResponse 录音列表响应
+type RecordingListResponse struct {
+	Total int64              `json:"total"`
+	Items []models.Recording `json:"items"` // 按上传时间倒序
+}
+
+// UploadRecording 上传录音
+// POST /api/recordings
+// multipart表单: file 录音文件, purpose 用途（默认 practice）, ref_id 关联对象ID,
+// duration 客户端测得的时长（秒，仅在服务端无法解析且未限制时长时使用）
+func UploadRecording(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("UploadRecording - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	cfg := config.Get().Recordings
+	// max_size_mb 为 0 表示不限制大小
+	maxSize := int64(cfg.MaxSizeMB) << 20
+	if maxSize > 0 {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+1<<20)
+	}
+
+	purpose := c.DefaultPostForm("purpose", models.RecordingPurposePractice)
+	if !models.IsValidRecordingPurpose(purpose) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "录音用途无效"})
+		return
+	}
+	var declared float64
+	if s := c.PostForm("duration"); s != "" {
+		d, err := strconv.ParseFloat(s, 64)
+		if err != nil || d < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "duration 参数无效"})
+			return
+		}
+		declared = d
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		utils.Warn("UploadRecording - Missing file: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请上传录音文件"})
+		return
+	}
+	if maxSize > 0 && fileHeader.Size > maxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": recording.ErrTooLarge.Error()})
+		return
+	}
+	f, err := fileHeader.Open()
+	if err != nil {
+		utils.Error("UploadRecording - Open upload failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取文件失败"})
+		return
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		utils.Error("UploadRecording - Read upload failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取文件失败"})
+		return
+	}
+
+	rec, err := recording.Save(c.Request.Context(), database.GetDB(), storage.Get(), cfg, recording.Upload{
+		UserID:           userID.(uint),
+		Purpose:          purpose,
+		RefID:            c.PostForm("ref_id"),
+		Data:             data,
+		DeclaredDuration: declared,
+	}, time.Now())
+	switch {
+	case errors.Is(err, recording.ErrUnsupportedType), errors.Is(err, recording.ErrUnknownDuration):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, recording.ErrTooLarge), errors.Is(err, recording.ErrTooLong):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, recording.ErrEmpty):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		utils.Error("UploadRecording - Save failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存录音失败"})
+		return
+	}
+
+	utils.Info("UploadRecording - UserID: %v, RecordingID: %d, Type: %s, Size: %d, Duration: %.1fs",
+		userID, rec.ID, rec.ContentType, rec.Size, rec.Duration)
+	c.JSON(http.StatusCreated, rec)
+}
+
+// ListRecordings 获取录音列表
+// GET /api/recordings?purpose=shadowing&limit=20&offset=0
+func ListRecordings(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("ListRecordings - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	limit, offset, ok := parsePagination(c, defaultRecordingLimit, maxRecordingLimit)
+	if !ok {
+		return
+	}
+
+	query := database.GetDB().Model(&models.Recording{}).Where("user_id = ?", userID)
+	if purpose := c.Query("purpose"); purpose != "" {
+		query = query.Where("purpose = ?", purpose)
+	}
+	if refID := c.Query("ref_id"); refID != "" {
+		query = query.Where("ref_id = ?", refID)
+	}
+
+	var resp RecordingListResponse
+	if err := query.Count(&resp.Total).Error; err != nil {
+		utils.Error("ListRecordings - Count failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	if err := query.Order("created_at DESC").Limit(limit).Offset(offset).Find(&resp.Items).Error; err != nil {
+		utils.Error("ListRecordings - Query failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetRecording 获取录音信息
+// GET /api/recordings/:id
+func GetRecording(c *gin.Context) {
+	rec, ok := loadUserRecording(c, "GetRecording")
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, rec)
+}
+
+// GetRecordingAudio 下载录音音频
+// GET /api/recordings/:id/audio
+func GetRecordingAudio(c *gin.Context) {
+	rec, ok := loadUserRecording(c, "GetRecordingAudio")
+	if !ok {
+		return
+	}
+
+	obj, err := storage.Get().Open(c.Request.Context(), rec.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		utils.Warn("GetRecordingAudio - Object missing: RecordingID=%d, Key=%s", rec.ID, rec.StorageKey)
+		c.JSON(http.StatusNotFound, gin.H{"error": "录音文件不存在"})
+		return
+	}
+	if err != nil {
+		utils.Error("GetRecordingAudio - Open failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "读取录音失败"})
+		return
+	}
+	defer obj.Body.Close()
+
+	c.Header("Cache-Control", "private, max-age=3600")
+	c.DataFromReader(http.StatusOK, obj.Size, rec.ContentType, obj.Body, nil)
+}
+
+// DeleteRecording 删除录音
+// DELETE /api/recordings/:id
+func DeleteRecording(c *gin.Context) {
+	rec, ok := loadUserRecording(c, "DeleteRecording")
+	if !ok {
+		return
+	}
+
+	if err := recording.Delete(c.Request.Context(), database.GetDB(), storage.Get(), rec); err != nil {
+		utils.Error("DeleteRecording - Delete failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "删除录音失败"})
+		return
+	}
+
+	utils.Info("DeleteRecording - UserID: %d, RecordingID: %d", rec.UserID, rec.ID)
+	c.JSON(http.StatusOK, gin.H{"message": "删除成功"})
+}
+
+// loadUserRecording 加载属于当前用户的录音，失败时直接写入错误响应
+func loadUserRecording(c *gin.Context, caller string) (*models.Recording, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("%s - User not authenticated", caller)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return nil, false
+	}
+
+	recordingID, ok := parseIDParam(c, "id")
+	if !ok {
+		return nil, false
+	}
+
+	var rec models.Recording
+	err := database.GetDB().Where("id = ? AND user_id = ?", recordingID, userID).First(&rec).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "录音不存在"})
+		return nil, false
+	}
+	if err != nil {
+		utils.Error("%s - Query failed: %v", caller, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return nil, false
+	}
+	return &rec, true
+}
@@ -0,0 +1,385 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"server/audio"
+	"server/config"
+	"server/database"
+	"server/models"
+	"server/recording"
+	"server/shadowing"
+	"server/speech"
+	"server/storage"
+	"server/utils"
+)
+
+// ShadowingNextResponse 下一句跟读
+type ShadowingNextResponse struct {
+	MaterialID uint                       `json:"material_id"`
+	AudioURL   string                     `json:"audio_url"` // 材料音频，按句子起止时间播放原声
+	NextSeq    int                        `json:"next_seq"`  // 0 表示全部达标
+	Sentence   *models.ListeningSentence  `json:"sentence"`  // 下一句，全部达标时为空
+	Summary    *shadowing.SentenceSummary `json:"summary"`   // 该句已有成绩
+}
+
+// ShadowingAttemptResponse 跟读评分结果
+type ShadowingAttemptResponse struct {
+	Attempt models.ShadowingAttempt `json:"attempt"`
+	Score   *speech.Score           `json:"score"` // 含评分依据的声学指标
+	State   *shadowing.State        `json:"state"` // 提交后的材料汇总
+}
+
+// ListShadowingMaterials 获取影子跟读材料目录
+// GET /api/speaking/shadowing/materials?difficulty=A2,B1&limit=20&offset=0
+// difficulty 可传多个等级（逗号分隔）
+func ListShadowingMaterials(c *gin.Context) {
+	if _, exists := c.Get("userID"); !exists {
+		utils.Warn("ListShadowingMaterials - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	limit, offset, ok := parsePagination(c, defaultMaterialLimit, maxMaterialLimit)
+	if !ok {
+		return
+	}
+
+	db := database.GetDB()
+	sub := db.Model(&models.ListeningMaterial{}).Select("id").Where("kind = ?", models.MaterialKindShadowing)
+	if s := c.Query("difficulty"); s != "" {
+		levels := strings.Split(s, ",")
+		for i, l := range levels {
+			levels[i] = strings.ToUpper(strings.TrimSpace(l))
+			if !models.IsValidCEFRLevel(levels[i]) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "difficulty 参数无效"})
+				return
+			}
+		}
+		sub = sub.Where("difficulty IN ?", levels)
+	}
+	query := db.Model(&models.ShadowingMaterial{}).Where("listening_material_id IN (?)", sub)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		utils.Error("ListShadowingMaterials - Count failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	items := []models.ShadowingMaterial{}
+	if err := query.Preload("Material").Order("id DESC").Limit(limit).Offset(offset).Find(&items).Error; err != nil {
+		utils.Error("ListShadowingMaterials - Query failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"total": total, "items": items})
+}
+
+// ListShadowingProgress 获取练习过的跟读材料及最近得分，供口语首页展示
+// GET /api/speaking/shadowing/progress?limit=20&offset=0
+func ListShadowingProgress(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("ListShadowingProgress - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	limit, offset, ok := parsePagination(c, defaultMaterialLimit, maxMaterialLimit)
+	if !ok {
+		return
+	}
+
+	query := database.GetDB().Model(&models.ShadowingProgress{}).Where("user_id = ?", userID)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		utils.Error("ListShadowingProgress - Count failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	var items []models.ShadowingProgress
+	if err := query.Preload("Material.Material").Order("last_practice_at DESC").Limit(limit).Offset(offset).Find(&items).Error; err != nil {
+		utils.Error("ListShadowingProgress - Query failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"total": total, "items": items})
+}
+
+// GetShadowingState 获取跟读材料的汇总及逐句最高分、最近得分
+// GET /api/speaking/shadowing/materials/:id
+func GetShadowingState(c *gin.Context) {
+	userID, material, ok := loadShadowingMaterial(c, "GetShadowingState")
+	if !ok {
+		return
+	}
+
+	state, err := shadowing.LoadState(database.GetDB(), userID, material, config.Get().Speaking.ShadowingPassScore)
+	if err != nil {
+		utils.Error("GetShadowingState - Load failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, state)
+}
+
+// GetNextShadowingSentence 获取下一句要跟读的句子
+// GET /api/speaking/shadowing/materials/:id/next
+// 优先返回未练习的句子，其次是最高分未达标的句子中得分最低的一句
+func GetNextShadowingSentence(c *gin.Context) {
+	userID, material, ok := loadShadowingMaterial(c, "GetNextShadowingSentence")
+	if !ok {
+		return
+	}
+
+	db := database.GetDB()
+	state, err := shadowing.LoadState(db, userID, material, config.Get().Speaking.ShadowingPassScore)
+	if err != nil {
+		utils.Error("GetNextShadowingSentence - Load failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	resp := ShadowingNextResponse{MaterialID: material.ID, AudioURL: material.Material.AudioURL, NextSeq: state.NextSeq}
+	if state.NextSeq > 0 {
+		var sentence models.ListeningSentence
+		if err := db.Where("material_id = ? AND seq = ?", material.ListeningMaterialID, state.NextSeq).First(&sentence).Error; err != nil {
+			utils.Error("GetNextShadowingSentence - Query sentence failed: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+			return
+		}
+		resp.Sentence = &sentence
+		for i := range state.Sentences {
+			if state.Sentences[i].Seq == state.NextSeq {
+				resp.Summary = &state.Sentences[i]
+				break
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// SubmitShadowingAttempt 提交跟读录音并评分
+// POST /api/speaking/shadowing/sentences/:id/attempts
+// multipart表单: audio 用户录音, format 音频格式（默认取文件扩展名）
+// 材料音频为托管 WAV 时截取该句原声参与语调对比；录音按用途 shadowing 保存，每次评分都保留
+func SubmitShadowingAttempt(c *gin.Context) {
+	userID, sentence, material, ok := loadShadowingSentence(c, "SubmitShadowingAttempt")
+	if !ok {
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxRecordingSize+1<<20)
+
+	audioHeader, err := c.FormFile("audio")
+	if err != nil {
+		utils.Warn("SubmitShadowingAttempt - Missing audio: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请上传录音"})
+		return
+	}
+	data, err := readRecording(audioHeader)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	db := database.GetDB()
+	req := &speech.ScoreRequest{
+		Audio:         data,
+		Format:        formatOf(c.PostForm("format"), audioHeader.Filename),
+		ReferenceText: sentence.Text,
+		Language:      "en-US",
+	}
+	ref, hosted, err := audio.Clip(ctx, db, storage.Get(), material.Material.AudioURL, sentence.StartTime, sentence.EndTime)
+	if err != nil {
+		// 原声只用于语调对比，截取失败时仍按参考文本评分
+		utils.Warn("SubmitShadowingAttempt - Clip reference failed: MaterialID=%d: %v", material.ID, err)
+	} else if hosted {
+		req.ReferenceAudio, req.ReferenceFormat = ref, "wav"
+	}
+
+	score, ok := scoreRecording(c, "SubmitShadowingAttempt", req)
+	if !ok {
+		return
+	}
+
+	rec, err := recording.Save(ctx, db, storage.Get(), config.Get().Recordings, recording.Upload{
+		UserID:  userID,
+		Purpose: models.RecordingPurposeShadowing,
+		RefID:   strconv.FormatUint(uint64(sentence.ID), 10),
+		Data:    data,
+	}, time.Now())
+	switch {
+	case errors.Is(err, recording.ErrUnsupportedType), errors.Is(err, recording.ErrUnknownDuration):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, recording.ErrTooLarge), errors.Is(err, recording.ErrTooLong):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		utils.Error("SubmitShadowingAttempt - Save recording failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存录音失败"})
+		return
+	}
+
+	attempt := models.ShadowingAttempt{
+		UserID:        userID,
+		MaterialID:    material.ID,
+		Seq:           sentence.Seq,
+		SentenceID:    sentence.ID,
+		RecordingID:   rec.ID,
+		Pronunciation: score.Pronunciation,
+		Fluency:       score.Fluency,
+		Intonation:    score.Intonation,
+		Overall:       score.Overall,
+		Feedback:      score.Feedback,
+		Engine:        score.Engine,
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		_, err := shadowing.Record(tx, &attempt, material, time.Now())
+		return err
+	})
+	if err != nil {
+		utils.Error("SubmitShadowingAttempt - Record failed: %v", err)
+		if delErr := recording.Delete(ctx, db, storage.Get(), rec); delErr != nil {
+			utils.Warn("SubmitShadowingAttempt - Cleanup recording %d failed: %v", rec.ID, delErr)
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存评分失败"})
+		return
+	}
+
+	state, err := shadowing.LoadState(db, userID, material, config.Get().Speaking.ShadowingPassScore)
+	if err != nil {
+		utils.Error("SubmitShadowingAttempt - Load failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	utils.Info("SubmitShadowingAttempt - UserID: %d, MaterialID: %d, Seq: %d, Overall: %.1f",
+		userID, material.ID, sentence.Seq, score.Overall)
+	c.JSON(http.StatusCreated, ShadowingAttemptResponse{Attempt: attempt, Score: score, State: state})
+}
+
+// ListShadowingAttempts 获取某句的跟读历史
+// GET /api/speaking/shadowing/sentences/:id/attempts?limit=20&offset=0
+func ListShadowingAttempts(c *gin.Context) {
+	userID, sentence, material, ok := loadShadowingSentence(c, "ListShadowingAttempts")
+	if !ok {
+		return
+	}
+
+	limit, offset, ok := parsePagination(c, defaultRecordingLimit, maxRecordingLimit)
+	if !ok {
+		return
+	}
+
+	query := database.GetDB().Model(&models.ShadowingAttempt{}).
+		Where("user_id = ? AND material_id = ? AND seq = ?", userID, material.ID, sentence.Seq)
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		utils.Error("ListShadowingAttempts - Count failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	items := []models.ShadowingAttempt{}
+	if err := query.Order("id DESC").Limit(limit).Offset(offset).Find(&items).Error; err != nil {
+		utils.Error("ListShadowingAttempts - Query failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"total": total, "items": items})
+}
+
+// loadShadowingMaterial 校验登录并加载跟读材料及其听力材料，失败时直接写入错误响应
+func loadShadowingMaterial(c *gin.Context, caller string) (uint, *models.ShadowingMaterial, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("%s - User not authenticated", caller)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return 0, nil, false
+	}
+
+	materialID, ok := parseIDParam(c, "id")
+	if !ok {
+		return 0, nil, false
+	}
+
+	material, err := findShadowingMaterial(database.GetDB().Where("id = ?", materialID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "跟读材料不存在"})
+		return 0, nil, false
+	}
+	if err != nil {
+		utils.Error("%s - Query failed: %v", caller, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return 0, nil, false
+	}
+	return userID.(uint), material, true
+}
+
+// loadShadowingSentence 校验登录并加载跟读句子及所属材料，失败时直接写入错误响应
+func loadShadowingSentence(c *gin.Context, caller string) (uint, *models.ListeningSentence, *models.ShadowingMaterial, bool) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("%s - User not authenticated", caller)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return 0, nil, nil, false
+	}
+
+	sentenceID, ok := parseIDParam(c, "id")
+	if !ok {
+		return 0, nil, nil, false
+	}
+
+	db := database.GetDB()
+	var sentence models.ListeningSentence
+	err := db.First(&sentence, sentenceID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "句子不存在"})
+		return 0, nil, nil, false
+	}
+	if err != nil {
+		utils.Error("%s - Query sentence failed: %v", caller, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return 0, nil, nil, false
+	}
+	if strings.TrimSpace(sentence.Text) == "" {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "句子没有原文，无法评分"})
+		return 0, nil, nil, false
+	}
+
+	material, err := findShadowingMaterial(db.Where("listening_material_id = ?", sentence.MaterialID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "跟读材料不存在"})
+		return 0, nil, nil, false
+	}
+	if err != nil {
+		utils.Error("%s - Query material failed: %v", caller, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return 0, nil, nil, false
+	}
+	return userID.(uint), &sentence, material, true
+}
+
+// findShadowingMaterial 按条件加载跟读材料及其听力材料
+// 听力材料已删除或不再是跟读类型时视为不存在
+func findShadowingMaterial(query *gorm.DB) (*models.ShadowingMaterial, error) {
+	var material models.ShadowingMaterial
+	if err := query.Preload("Material").First(&material).Error; err != nil {
+		return nil, err
+	}
+	if material.Material == nil || material.Material.Kind != models.MaterialKindShadowing {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &material, nil
+}
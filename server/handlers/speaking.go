@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"server/config"
+	"server/speech"
+	"server/utils"
+)
+
+const (
+	// maxRecordingSize 单个录音文件大小上限
+	maxRecordingSize = 20 << 20
+	// maxReferenceTextLength 参考文本长度上限
+	maxReferenceTextLength = 1000
+	// scoreTimeout 单次评分超时时间
+	scoreTimeout = 30 * time.Second
+)
+
+// ScoreSpeaking 口语发音评分
+// POST /api/speaking/score
+// multipart表单: audio 用户录音, reference_text 参考文本, reference_audio 原声（可选）,
+// format / reference_format 音频格式（默认取文件扩展名）
+func ScoreSpeaking(c *gin.Context) {
+	if _, exists := c.Get("userID"); !exists {
+		utils.Warn("ScoreSpeaking - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, 2*maxRecordingSize+1<<20)
+
+	refText := strings.TrimSpace(c.PostForm("reference_text"))
+	if refText == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请提供参考文本"})
+		return
+	}
+	if len([]rune(refText)) > maxReferenceTextLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "参考文本过长"})
+		return
+	}
+
+	audioHeader, err := c.FormFile("audio")
+	if err != nil {
+		utils.Warn("ScoreSpeaking - Missing audio: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "请上传录音"})
+		return
+	}
+	audio, err := readRecording(audioHeader)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	req := &speech.ScoreRequest{
+		Audio:         audio,
+		Format:        formatOf(c.PostForm("format"), audioHeader.Filename),
+		ReferenceText: refText,
+		Language:      "en-US",
+	}
+	if refHeader, err := c.FormFile("reference_audio"); err == nil {
+		if req.ReferenceAudio, err = readRecording(refHeader); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		req.ReferenceFormat = formatOf(c.PostForm("reference_format"), refHeader.Filename)
+	}
+
+	score, ok := scoreRecording(c, "ScoreSpeaking", req)
+	if !ok {
+		return
+	}
+
+	utils.Debug("ScoreSpeaking - Engine: %s, Overall: %.1f", score.Engine, score.Overall)
+	c.JSON(http.StatusOK, score)
+}
+
+// scoreRecording 使用配置的评分引擎评分，失败时直接写入错误响应
+func scoreRecording(c *gin.Context, caller string, req *speech.ScoreRequest) (*speech.Score, bool) {
+	scorer, err := speech.Get(config.Get().Speaking.Scorer)
+	if err != nil {
+		utils.Error("%s - %v", caller, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "评分服务不可用"})
+		return nil, false
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), scoreTimeout)
+	defer cancel()
+	score, err := scorer.Score(ctx, req)
+	switch {
+	case errors.Is(err, speech.ErrUnsupportedFormat):
+		utils.Warn("%s - %v", caller, err)
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return nil, false
+	case errors.Is(err, speech.ErrNoSpeech):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return nil, false
+	case errors.Is(err, speech.ErrAudioTooLong):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return nil, false
+	case err != nil:
+		utils.Error("%s - Score failed: %v", caller, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "评分失败"})
+		return nil, false
+	}
+	return score, true
+}
+
+// readRecording 读取上传的录音文件
+func readRecording(fh *multipart.FileHeader) ([]byte, error) {
+	if fh.Size > maxRecordingSize {
+		return nil, errors.New("录音文件过大")
+	}
+	f, err := fh.Open()
+	if err != nil {
+		return nil, errors.New("读取录音失败")
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, errors.New("读取录音失败")
+	}
+	return data, nil
+}
+
+// formatOf 音频格式，未显式指定时取文件扩展名
+func formatOf(format, filename string) string {
+	if format != "" {
+		return strings.ToLower(format)
+	}
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), "."))
+}
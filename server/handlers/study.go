@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"server/database"
+	"server/models"
+	"server/study"
+	"server/utils"
+)
+
+// maxStudyEventsPerRequest 单次提交的学习事件上限
+const maxStudyEventsPerRequest = 100
+
+// StudyEventInput 客户端提交的学习事件
+type StudyEventInput struct {
+	EventID    string     `json:"event_id" binding:"required"` // 客户端生成的唯一ID，重复提交会被忽略；vocab: 前缀保留给服务端
+	Type       string     `json:"type" binding:"required"`     // listening_minutes / speaking_minutes / exercise_completed，word_learned 由服务端记录
+	Amount     int        `json:"amount"`                      // 数量，默认1
+	RefID      string     `json:"ref_id"`                      // 关联对象ID
+	OccurredAt *time.Time `json:"occurred_at"`                 // 发生时间，默认服务器时间
+}
+
+// StudyEventsRequest 批量提交学习事件请求
+type StudyEventsRequest struct {
+	Events []StudyEventInput `json:"events" binding:"required,min=1,dive"`
+}
+
+// RejectedEvent 被拒绝的事件
+type RejectedEvent struct {
+	EventID string `json:"event_id"`
+	Error   string `json:"error"`
+}
+
+// StudyEventsResponse 提交学习事件响应
+type StudyEventsResponse struct {
+	Accepted   int              `json:"accepted"`   // 新记录的事件数
+	Duplicates int              `json:"duplicates"` // 已记录过的事件数
+	Rejected   []RejectedEvent  `json:"rejected"`   // 校验失败的事件
+	Stats      models.UserStats `json:"stats"`      // 重新汇总后的统计
+}
+
+// PostStudyEvents 批量提交学习事件
+// POST /api/study/events
+// 统计数据由服务端根据事件汇总，客户端可安全重试
+func PostStudyEvents(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("PostStudyEvents - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	var req StudyEventsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Warn("PostStudyEvents - Invalid request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(req.Events) > maxStudyEventsPerRequest {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "单次提交的事件过多"})
+		return
+	}
+
+	db := database.GetDB()
+	cal, err := study.CalendarForUser(db, userID.(uint))
+	if err != nil {
+		utils.Error("PostStudyEvents - Load user failed: %v", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "用户不存在"})
+		return
+	}
+
+	now := time.Now()
+	resp := StudyEventsResponse{Rejected: []RejectedEvent{}}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		for _, in := range req.Events {
+			e := study.Event{
+				EventID: in.EventID,
+				Type:    in.Type,
+				Amount:  in.Amount,
+				RefID:   in.RefID,
+			}
+			if in.OccurredAt != nil {
+				e.OccurredAt = *in.OccurredAt
+			}
+			if err := study.CheckClientEvent(&e); err != nil {
+				resp.Rejected = append(resp.Rejected, RejectedEvent{EventID: in.EventID, Error: err.Error()})
+				continue
+			}
+			if err := e.Normalize(now); err != nil {
+				resp.Rejected = append(resp.Rejected, RejectedEvent{EventID: in.EventID, Error: err.Error()})
+				continue
+			}
+
+			created, err := study.Record(tx, userID.(uint), cal, e)
+			if err != nil {
+				return err
+			}
+			if created {
+				resp.Accepted++
+			} else {
+				resp.Duplicates++
+			}
+		}
+
+		var err error
+		resp.Stats, err = study.RecomputeStats(tx, userID.(uint), now)
+		return err
+	})
+	if err != nil {
+		utils.Error("PostStudyEvents - Record failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "记录学习事件失败"})
+		return
+	}
+
+	utils.Info("PostStudyEvents - UserID: %v, Accepted: %d, Duplicates: %d, Rejected: %d",
+		userID, resp.Accepted, resp.Duplicates, len(resp.Rejected))
+	c.JSON(http.StatusOK, resp)
+}
+
+// GetStudyStats 获取学习统计
+// GET /api/study/stats
+// 读取时重新汇总，使跨天后的连续学习天数及时归零
+func GetStudyStats(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("GetStudyStats - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	stats, err := study.RecomputeStats(database.GetDB(), userID.(uint), time.Now())
+	if err != nil {
+		utils.Error("GetStudyStats - Recompute failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
+
+// UpdateStats 兼容旧版客户端的统计上报
+// PUT /api/user/stats
+// 统计改由学习事件汇总，请求中的数值不再写入；重新汇总后返回用户信息，与旧接口的响应格式一致
+func UpdateStats(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("UpdateStats - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	db := database.GetDB()
+	if _, err := study.RecomputeStats(db, userID.(uint), time.Now()); err != nil {
+		utils.Error("UpdateStats - Recompute failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新失败"})
+		return
+	}
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		utils.Error("UpdateStats - User not found: %v", userID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "用户不存在"})
+		return
+	}
+
+	utils.Debug("UpdateStats - Ignored client stats: UserID=%v", userID)
+	c.JSON(http.StatusOK, user)
+}
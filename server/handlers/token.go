@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"server/database"
+	"server/models"
+	"server/utils"
+)
+
+// RefreshRequest 刷新令牌请求结构
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutRequest 登出请求结构
+type LogoutRequest struct {
+	AllDevices bool `json:"all_devices"` // 是否同时登出该用户的所有设备
+}
+
+// TokenPair 访问令牌与刷新令牌
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64
+}
+
+var errRefreshTokenReused = errors.New("refresh token reused")
+
+// issueTokenPair 为用户签发一组新令牌
+// familyID 为空时开启新的令牌族（即一次新的登录会话）
+func issueTokenPair(tx *gorm.DB, c *gin.Context, user *models.User, familyID string) (*TokenPair, error) {
+	if familyID == "" {
+		id, err := utils.NewSessionID()
+		if err != nil {
+			return nil, err
+		}
+		familyID = id
+	}
+
+	refreshToken, tokenHash, err := utils.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	record := models.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(utils.RefreshTokenTTL),
+		UserAgent: truncate(c.Request.UserAgent(), 255),
+		ClientIP:  c.ClientIP(),
+	}
+	if err := tx.Create(&record).Error; err != nil {
+		return nil, err
+	}
+
+	accessToken, err := utils.GenerateToken(user.ID, user.Username, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(utils.AccessTokenTTL / time.Second),
+	}, nil
+}
+
+// revokeTokenFamily 吊销整个令牌族
+func revokeTokenFamily(tx *gorm.DB, familyID string) error {
+	return tx.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// Refresh 使用刷新令牌换取新的令牌对
+// POST /api/auth/refresh
+// 每个刷新令牌只能使用一次，重复使用会吊销整个令牌族
+func Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Warn("Refresh - Invalid request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDB()
+
+	var record models.RefreshToken
+	if err := db.Where("token_hash = ?", utils.HashToken(req.RefreshToken)).First(&record).Error; err != nil {
+		utils.Warn("Refresh - Unknown refresh token")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "刷新令牌无效"})
+		return
+	}
+
+	now := time.Now()
+	if record.UsedAt != nil || record.RevokedAt != nil {
+		// 已轮换或已吊销的令牌再次出现，视为令牌泄露
+		utils.Warn("Refresh - Token reuse detected: UserID=%d, Family=%s", record.UserID, record.FamilyID)
+		if err := revokeTokenFamily(db, record.FamilyID); err != nil {
+			utils.Error("Refresh - Revoke family failed: %v", err)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "刷新令牌已失效，请重新登录"})
+		return
+	}
+	if !now.Before(record.ExpiresAt) {
+		utils.Warn("Refresh - Token expired: UserID=%d", record.UserID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "刷新令牌已过期，请重新登录"})
+		return
+	}
+
+	var user models.User
+	if err := db.First(&user, record.UserID).Error; err != nil {
+		utils.Warn("Refresh - User not found: %d", record.UserID)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户不存在"})
+		return
+	}
+
+	var pair *TokenPair
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// 以 used_at IS NULL 为条件更新，防止并发请求重复使用同一令牌
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", record.ID).
+			Update("used_at", now)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errRefreshTokenReused
+		}
+
+		var err error
+		pair, err = issueTokenPair(tx, c, &user, record.FamilyID)
+		return err
+	})
+	if errors.Is(err, errRefreshTokenReused) {
+		utils.Warn("Refresh - Concurrent reuse detected: UserID=%d, Family=%s", record.UserID, record.FamilyID)
+		if err := revokeTokenFamily(db, record.FamilyID); err != nil {
+			utils.Error("Refresh - Revoke family failed: %v", err)
+		}
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "刷新令牌已失效，请重新登录"})
+		return
+	}
+	if err != nil {
+		utils.Error("Refresh - Rotate token failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "生成令牌失败"})
+		return
+	}
+
+	utils.Info("Refresh - Success: UserID=%d", user.ID)
+	c.JSON(http.StatusOK, newAuthResponse(pair, user))
+}
+
+// Logout 登出当前会话
+// POST /api/auth/logout
+func Logout(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("Logout - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	var req LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.Warn("Logout - Invalid request: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	db := database.GetDB()
+	query := db.Model(&models.RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", userID)
+	if !req.AllDevices {
+		query = query.Where("family_id = ?", c.GetString("sessionID"))
+	}
+	if err := query.Update("revoked_at", time.Now()).Error; err != nil {
+		utils.Error("Logout - Revoke failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "登出失败"})
+		return
+	}
+
+	utils.Info("Logout - Success: UserID=%v, AllDevices=%v", userID, req.AllDevices)
+	c.JSON(http.StatusOK, gin.H{"message": "已登出"})
+}
+
+// newAuthResponse 组装认证响应
+func newAuthResponse(pair *TokenPair, user models.User) AuthResponse {
+	return AuthResponse{
+		Token:        pair.AccessToken,
+		RefreshToken: pair.RefreshToken,
+		ExpiresIn:    pair.ExpiresIn,
+		User:         user,
+	}
+}
+
+// truncate 按字节截断字符串
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"server/database"
+	"server/models"
+	"server/scheduler"
+	"server/study"
+	"server/utils"
+)
+
+const (
+	// defaultDueLimit 单次拉取待复习单词的默认数量
+	defaultDueLimit = 20
+	// maxDueLimit 单次拉取待复习单词的最大数量
+	maxDueLimit = 100
+)
+
+// ReviewRequest 提交复习反馈请求
+type ReviewRequest struct {
+	Rating     string     `json:"rating" binding:"required"` // known / fuzzy / forgotten
+	ReviewedAt *time.Time `json:"reviewed_at"`               // 客户端复习时间，离线复习同步时使用
+}
+
+// DueReviewsResponse 待复习单词响应
+type DueReviewsResponse struct {
+	Total int64                     `json:"total"` // 当前到期的单词总数
+	Items []models.UserWordProgress `json:"items"` // 按到期时间排序的单词
+}
+
+// GetDueReviews 获取到期需要复习的单词
+// GET /api/vocab/review/due?limit=20
+func GetDueReviews(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("GetDueReviews - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	limit := defaultDueLimit
+	if s := c.Query("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			utils.Warn("GetDueReviews - Invalid limit: %s", s)
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit 参数无效"})
+			return
+		}
+		if n > maxDueLimit {
+			n = maxDueLimit
+		}
+		limit = n
+	}
+
+	db := database.GetDB()
+	now := time.Now()
+	due := db.Model(&models.UserWordProgress{}).Where("user_id = ? AND due_at <= ?", userID, now)
+
+	var resp DueReviewsResponse
+	if err := due.Count(&resp.Total).Error; err != nil {
+		utils.Error("GetDueReviews - Count failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	err := db.Preload("Word").
+		Where("user_id = ? AND due_at <= ?", userID, now).
+		Order("due_at ASC").
+		Limit(limit).
+		Find(&resp.Items).Error
+	if err != nil {
+		utils.Error("GetDueReviews - Query failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	utils.Debug("GetDueReviews - UserID: %v, Total: %d, Returned: %d", userID, resp.Total, len(resp.Items))
+	c.JSON(http.StatusOK, resp)
+}
+
+// SubmitReview 提交单词复习反馈并计算下次复习时间
+// POST /api/vocab/review/:wordId
+// 单词首次提交反馈时创建学习进度
+func SubmitReview(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("SubmitReview - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	wordID, ok := parseIDParam(c, "wordId")
+	if !ok {
+		return
+	}
+
+	var req ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Warn("SubmitReview - Invalid request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rating, err := scheduler.ParseRating(req.Rating)
+	if err != nil {
+		utils.Warn("SubmitReview - %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "反馈类型无效"})
+		return
+	}
+
+	now := time.Now()
+	reviewedAt := now
+	// 离线复习允许回填时间，但不接受未来时间
+	if req.ReviewedAt != nil && req.ReviewedAt.Before(now) {
+		reviewedAt = *req.ReviewedAt
+	}
+
+	db := database.GetDB()
+
+	var word models.Word
+	if err := db.First(&word, wordID).Error; err != nil {
+		utils.Warn("SubmitReview - Word not found: %d", wordID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "单词不存在"})
+		return
+	}
+
+	var progress models.UserWordProgress
+	err = db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND word_id = ?", userID, word.ID).First(&progress).Error
+		firstReview := false
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// 并发提交同一单词的首次复习时只有一个请求建立记录，其余请求按已有记录继续调度
+			state := scheduler.NewState(reviewedAt)
+			progress = models.UserWordProgress{
+				UserID:     userID.(uint),
+				WordID:     word.ID,
+				Status:     models.WordStatusNew,
+				EaseFactor: state.EaseFactor,
+				DueAt:      state.DueAt,
+			}
+			result := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "user_id"}, {Name: "word_id"}},
+				DoNothing: true,
+			}).Create(&progress)
+			if result.Error != nil {
+				return result.Error
+			}
+			firstReview = result.RowsAffected == 1
+			if !firstReview {
+				err = tx.Where("user_id = ? AND word_id = ?", userID, word.ID).First(&progress).Error
+			} else {
+				err = nil
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		state := scheduler.Schedule(scheduler.State{
+			EaseFactor:  progress.EaseFactor,
+			Interval:    progress.Interval,
+			Repetitions: progress.Repetitions,
+			Lapses:      progress.Lapses,
+			DueAt:       progress.DueAt,
+		}, rating, reviewedAt)
+
+		progress.Status = string(rating)
+		progress.EaseFactor = state.EaseFactor
+		progress.Interval = state.Interval
+		progress.Repetitions = state.Repetitions
+		progress.Lapses = state.Lapses
+		progress.DueAt = state.DueAt
+		progress.ReviewCount++
+		if rating == scheduler.RatingKnown {
+			progress.CorrectCount++
+		}
+		progress.LastReviewedAt = &reviewedAt
+
+		if err := tx.Save(&progress).Error; err != nil {
+			return err
+		}
+		if !firstReview {
+			return nil
+		}
+
+		// 首次学习该单词时记录学习事件，以单词ID去重
+		e := study.Event{
+			EventID:    study.WordLearnedEventID(word.ID),
+			Type:       models.StudyEventWordLearned,
+			RefID:      strconv.FormatUint(uint64(word.ID), 10),
+			OccurredAt: reviewedAt,
+		}
+		if err := e.Normalize(now); err != nil {
+			// 超出回填时限的离线记录按当前时间计入
+			e.OccurredAt = now
+		}
+		cal, err := study.CalendarForUser(tx, progress.UserID)
+		if err != nil {
+			return err
+		}
+		if _, err := study.Record(tx, progress.UserID, cal, e); err != nil {
+			return err
+		}
+		_, err = study.RecomputeStats(tx, progress.UserID, now)
+		return err
+	})
+	if err != nil {
+		utils.Error("SubmitReview - Save progress failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "保存复习记录失败"})
+		return
+	}
+	progress.Word = word
+
+	utils.Info("SubmitReview - UserID: %v, WordID: %d, Rating: %s, Next: %s",
+		userID, word.ID, rating, progress.DueAt.Format(time.RFC3339))
+	c.JSON(http.StatusOK, progress)
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"server/database"
+	"server/models"
+	"server/utils"
+	"server/waveform"
+)
+
+// WaveformResponse 波形峰值
+// data 依次为每段的最小值、最大值（8 位，-128~127），与 audiowaveform 的 JSON 格式一致
+type WaveformResponse struct {
+	Status      string  `json:"status"`      // 生成状态
+	Resolution  int     `json:"resolution"`  // 每秒峰值对数
+	Resolutions []int   `json:"resolutions"` // 可用的分辨率
+	Duration    float64 `json:"duration"`    // 时长（秒）
+	Bits        int     `json:"bits"`        // 峰值位数
+	Length      int     `json:"length"`      // 峰值对数
+	Data        []int   `json:"data"`        // 峰值
+}
+
+// GetAudioPeaks 获取托管音频的波形峰值
+// GET /api/audio/:id/peaks?resolution=50
+func GetAudioPeaks(c *gin.Context) {
+	if _, exists := c.Get("userID"); !exists {
+		utils.Warn("GetAudioPeaks - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+	asset, ok := loadAudioAsset(c, "GetAudioPeaks")
+	if !ok {
+		return
+	}
+	writePeaks(c, "GetAudioPeaks", models.WaveformSourceAudio, asset.ID)
+}
+
+// GetRecordingPeaks 获取录音的波形峰值，用于原声与录音的对比
+// GET /api/recordings/:id/peaks?resolution=50
+func GetRecordingPeaks(c *gin.Context) {
+	rec, ok := loadUserRecording(c, "GetRecordingPeaks")
+	if !ok {
+		return
+	}
+	writePeaks(c, "GetRecordingPeaks", models.WaveformSourceRecording, rec.ID)
+}
+
+// writePeaks 按分辨率返回波形峰值
+// resolution 取最接近的已生成分辨率；尚未生成时返回 202，旧数据没有任务或缺少该分辨率时补充入队
+func writePeaks(c *gin.Context, caller, sourceType string, sourceID uint) {
+	resolution := waveform.DefaultResolution
+	if s := c.Query("resolution"); s != "" {
+		r, err := strconv.Atoi(s)
+		if err != nil || r <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "resolution 参数无效"})
+			return
+		}
+		resolution = r
+	}
+	resolution = waveform.Nearest(resolution)
+
+	db := database.GetDB()
+	w, err := waveform.Find(db, sourceType, sourceID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if err := waveform.Enqueue(db, sourceType, sourceID); err != nil {
+			utils.Error("%s - Enqueue failed: %v", caller, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "生成波形失败"})
+			return
+		}
+		w = &models.Waveform{Status: models.WaveformStatusPending}
+	} else if err != nil {
+		utils.Error("%s - Query waveform failed: %v", caller, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	switch w.Status {
+	case models.WaveformStatusPending:
+		c.Header("Retry-After", "2")
+		c.JSON(http.StatusAccepted, gin.H{"status": w.Status, "message": "波形生成中"})
+		return
+	case models.WaveformStatusUnsupported:
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"status": w.Status, "error": w.Error})
+		return
+	case models.WaveformStatusFailed:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"status": w.Status, "error": "生成波形失败"})
+		return
+	}
+
+	var peaks models.WaveformPeaks
+	err = db.Where("waveform_id = ? AND resolution = ?", w.ID, resolution).First(&peaks).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// 峰值数据缺失（如新增了分辨率），重新生成
+		utils.Warn("%s - Peaks missing, requeue: WaveformID=%d, Resolution=%d", caller, w.ID, resolution)
+		if err := waveform.Enqueue(db, sourceType, sourceID); err != nil {
+			utils.Error("%s - Enqueue failed: %v", caller, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "生成波形失败"})
+			return
+		}
+		c.Header("Retry-After", "2")
+		c.JSON(http.StatusAccepted, gin.H{"status": models.WaveformStatusPending, "message": "波形生成中"})
+		return
+	}
+	if err != nil {
+		utils.Error("%s - Query peaks failed: WaveformID=%d, Resolution=%d: %v", caller, w.ID, resolution, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	etag := fmt.Sprintf(`"w%d-%d-%d"`, w.ID, resolution, w.UpdatedAt.Unix())
+	c.Header("Cache-Control", "private, max-age=86400")
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	resp := WaveformResponse{
+		Status:      w.Status,
+		Resolution:  resolution,
+		Resolutions: waveform.Resolutions,
+		Duration:    w.Duration,
+		Bits:        8,
+		Length:      peaks.Length,
+		Data:        make([]int, len(peaks.Data)),
+	}
+	for i, b := range peaks.Data {
+		resp.Data[i] = int(int8(b))
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,678 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"server/database"
+	"server/models"
+	"server/study"
+	"server/utils"
+)
+
+const (
+	// defaultDailyNewWords 订阅词书时默认的每日新词数
+	defaultDailyNewWords = 20
+	// personalWordbookName 个人词库名称
+	personalWordbookName = "个人词库"
+)
+
+var errWordbookNotSubscribed = errors.New("wordbook not subscribed")
+
+// SubscribeWordbookRequest 订阅词书请求
+type SubscribeWordbookRequest struct {
+	Role          string `json:"role"`            // primary / auxiliary，为空时无主词书则设为主词书
+	DailyNewWords int    `json:"daily_new_words"` // 每日新词数，为空使用默认值
+}
+
+// PauseWordbookRequest 暂停辅助词书请求
+type PauseWordbookRequest struct {
+	Paused bool `json:"paused"`
+}
+
+// PersonalWordRequest 个人词库添加单词请求
+type PersonalWordRequest struct {
+	WordID uint `json:"word_id" binding:"required"`
+}
+
+// WordbookProgress 词书学习进度
+type WordbookProgress struct {
+	WordbookID     uint    `json:"wordbook_id"`
+	TotalWords     int64   `json:"total_words"`     // 单词总数
+	LearnedWords   int64   `json:"learned_words"`   // 已学单词数
+	TodayNewWords  int64   `json:"today_new_words"` // 今日新学单词数
+	PendingReviews int64   `json:"pending_reviews"` // 到期待复习单词数
+	Progress       float64 `json:"progress"`        // 学习进度 (0.0-1.0)
+}
+
+// WordbookItem 词书目录条目
+type WordbookItem struct {
+	models.Wordbook
+	Subscribed bool   `json:"subscribed"`     // 当前用户是否已订阅
+	Role       string `json:"role,omitempty"` // 订阅角色
+	Paused     bool   `json:"paused"`         // 是否暂停
+}
+
+// MyWordbook 用户词书及进度
+type MyWordbook struct {
+	models.UserWordbook
+	Progress WordbookProgress `json:"progress"`
+}
+
+// ListWordbooks 获取词书目录
+// GET /api/wordbooks?category=
+// 返回所有官方词书和当前用户的个人词库，并标注订阅状态
+func ListWordbooks(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("ListWordbooks - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	db := database.GetDB()
+	if _, err := ensurePersonalWordbook(db, userID.(uint)); err != nil {
+		utils.Error("ListWordbooks - Ensure personal wordbook failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	query := db.Where("kind = ? OR owner_id = ?", models.WordbookKindSystem, userID)
+	if category := c.Query("category"); category != "" {
+		query = query.Where("category = ?", category)
+	}
+	var books []models.Wordbook
+	if err := query.Order("kind DESC, sort ASC, id ASC").Find(&books).Error; err != nil {
+		utils.Error("ListWordbooks - Query failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	var subs []models.UserWordbook
+	if err := db.Where("user_id = ?", userID).Find(&subs).Error; err != nil {
+		utils.Error("ListWordbooks - Query subscriptions failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+	subByBook := make(map[uint]models.UserWordbook, len(subs))
+	for _, s := range subs {
+		subByBook[s.WordbookID] = s
+	}
+
+	items := make([]WordbookItem, 0, len(books))
+	for _, b := range books {
+		item := WordbookItem{Wordbook: b}
+		if s, ok := subByBook[b.ID]; ok {
+			item.Subscribed = true
+			item.Role = s.Role
+			item.Paused = s.Paused
+		}
+		items = append(items, item)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// ListMyWordbooks 获取用户已订阅的词书及进度
+// GET /api/wordbooks/mine
+func ListMyWordbooks(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("ListMyWordbooks - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	db := database.GetDB()
+	if _, err := ensurePersonalWordbook(db, userID.(uint)); err != nil {
+		utils.Error("ListMyWordbooks - Ensure personal wordbook failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	var subs []models.UserWordbook
+	err := db.Preload("Wordbook").
+		Where("user_id = ?", userID).
+		Order("CASE role WHEN 'primary' THEN 0 WHEN 'auxiliary' THEN 1 ELSE 2 END, id ASC").
+		Find(&subs).Error
+	if err != nil {
+		utils.Error("ListMyWordbooks - Query failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	cal, err := study.CalendarForUser(db, userID.(uint))
+	if err != nil {
+		utils.Error("ListMyWordbooks - Load user failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	items := make([]MyWordbook, 0, len(subs))
+	for _, s := range subs {
+		progress, err := computeWordbookProgress(db, userID.(uint), s.WordbookID, cal)
+		if err != nil {
+			utils.Error("ListMyWordbooks - Compute progress failed: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+			return
+		}
+		items = append(items, MyWordbook{UserWordbook: s, Progress: *progress})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// SubscribeWordbook 订阅词书
+// POST /api/wordbooks/:id/subscribe
+func SubscribeWordbook(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("SubscribeWordbook - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	bookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req SubscribeWordbookRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.Warn("SubscribeWordbook - Invalid request: %v", err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if req.Role != "" && req.Role != models.WordbookRolePrimary && req.Role != models.WordbookRoleAuxiliary {
+		utils.Warn("SubscribeWordbook - Invalid role: %s", req.Role)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "词书角色无效"})
+		return
+	}
+	if req.DailyNewWords < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "每日新词数无效"})
+		return
+	}
+	if req.DailyNewWords == 0 {
+		req.DailyNewWords = defaultDailyNewWords
+	}
+
+	db := database.GetDB()
+	var book models.Wordbook
+	if err := db.Where("kind = ?", models.WordbookKindSystem).First(&book, bookID).Error; err != nil {
+		utils.Warn("SubscribeWordbook - Wordbook not found: %d", bookID)
+		c.JSON(http.StatusNotFound, gin.H{"error": "词书不存在"})
+		return
+	}
+
+	var sub models.UserWordbook
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// 并发订阅同一本词书时只建立一条订阅
+		sub = models.UserWordbook{UserID: userID.(uint), WordbookID: book.ID, Role: models.WordbookRoleAuxiliary}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "wordbook_id"}},
+			DoNothing: true,
+		}).Create(&sub).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ? AND wordbook_id = ?", userID, book.ID).First(&sub).Error; err != nil {
+			return err
+		}
+
+		// 没有其他主词书时本词书必须作为主词书，重新订阅原主词书时不会因指定辅助角色而失去主词书
+		var others int64
+		if err := tx.Model(&models.UserWordbook{}).
+			Where("user_id = ? AND role = ? AND id <> ?", userID, models.WordbookRolePrimary, sub.ID).
+			Count(&others).Error; err != nil {
+			return err
+		}
+		role := req.Role
+		if others == 0 {
+			role = models.WordbookRolePrimary
+		} else if role == "" {
+			role = models.WordbookRoleAuxiliary
+		}
+		sub.Role = models.WordbookRoleAuxiliary
+		sub.Paused = false
+		sub.DailyNewWords = req.DailyNewWords
+		if err := tx.Save(&sub).Error; err != nil {
+			return err
+		}
+		if role == models.WordbookRolePrimary {
+			return setPrimaryWordbook(tx, userID.(uint), &sub)
+		}
+		return nil
+	})
+	if err != nil {
+		utils.Error("SubscribeWordbook - Save failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "订阅失败"})
+		return
+	}
+	sub.Wordbook = book
+
+	utils.Info("SubscribeWordbook - UserID: %v, WordbookID: %d, Role: %s", userID, book.ID, sub.Role)
+	c.JSON(http.StatusOK, sub)
+}
+
+// UnsubscribeWordbook 取消订阅词书
+// DELETE /api/wordbooks/:id/subscribe
+// 学习进度保留在单词维度，重新订阅后继续有效
+func UnsubscribeWordbook(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("UnsubscribeWordbook - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	bookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		// 硬删除，避免软删除记录占用唯一索引导致无法重新订阅
+		result := tx.Unscoped().
+			Where("user_id = ? AND wordbook_id = ? AND role <> ?", userID, bookID, models.WordbookRolePersonal).
+			Delete(&models.UserWordbook{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return ensurePrimaryWordbook(tx, userID.(uint))
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "未订阅该词书"})
+		return
+	}
+	if err != nil {
+		utils.Error("UnsubscribeWordbook - Delete failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "取消订阅失败"})
+		return
+	}
+
+	utils.Info("UnsubscribeWordbook - UserID: %v, WordbookID: %d", userID, bookID)
+	c.JSON(http.StatusOK, gin.H{"message": "已取消订阅"})
+}
+
+// SetPrimaryWordbook 切换主词书
+// PUT /api/wordbooks/:id/primary
+// 原主词书自动降为辅助词书
+func SetPrimaryWordbook(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("SetPrimaryWordbook - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	bookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var sub models.UserWordbook
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		err := tx.Preload("Wordbook").
+			Where("user_id = ? AND wordbook_id = ? AND role <> ?", userID, bookID, models.WordbookRolePersonal).
+			First(&sub).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errWordbookNotSubscribed
+		}
+		if err != nil {
+			return err
+		}
+		return setPrimaryWordbook(tx, userID.(uint), &sub)
+	})
+	if errors.Is(err, errWordbookNotSubscribed) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "未订阅该词书"})
+		return
+	}
+	if err != nil {
+		utils.Error("SetPrimaryWordbook - Update failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "切换失败"})
+		return
+	}
+
+	utils.Info("SetPrimaryWordbook - UserID: %v, WordbookID: %d", userID, bookID)
+	c.JSON(http.StatusOK, sub)
+}
+
+// PauseWordbook 暂停或恢复辅助词书
+// PUT /api/wordbooks/:id/pause
+// 暂停的词书不再安排新词，已学单词的复习不受影响
+func PauseWordbook(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("PauseWordbook - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	bookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req PauseWordbookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Warn("PauseWordbook - Invalid request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDB()
+	var sub models.UserWordbook
+	if err := db.Preload("Wordbook").Where("user_id = ? AND wordbook_id = ?", userID, bookID).First(&sub).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "未订阅该词书"})
+		return
+	}
+	if sub.Role != models.WordbookRoleAuxiliary {
+		utils.Warn("PauseWordbook - Not an auxiliary wordbook: UserID=%v, WordbookID=%d, Role=%s", userID, bookID, sub.Role)
+		c.JSON(http.StatusBadRequest, gin.H{"error": "只能暂停辅助词书"})
+		return
+	}
+
+	sub.Paused = req.Paused
+	if err := db.Model(&sub).Update("paused", req.Paused).Error; err != nil {
+		utils.Error("PauseWordbook - Update failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "更新失败"})
+		return
+	}
+
+	utils.Info("PauseWordbook - UserID: %v, WordbookID: %d, Paused: %v", userID, bookID, req.Paused)
+	c.JSON(http.StatusOK, sub)
+}
+
+// GetWordbookProgress 获取词书学习进度
+// GET /api/wordbooks/:id/progress
+func GetWordbookProgress(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("GetWordbookProgress - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	bookID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	db := database.GetDB()
+	var book models.Wordbook
+	if err := db.Where("kind = ? OR owner_id = ?", models.WordbookKindSystem, userID).First(&book, bookID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "词书不存在"})
+		return
+	}
+
+	cal, err := study.CalendarForUser(db, userID.(uint))
+	if err != nil {
+		utils.Error("GetWordbookProgress - Load user failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	progress, err := computeWordbookProgress(db, userID.(uint), book.ID, cal)
+	if err != nil {
+		utils.Error("GetWordbookProgress - Compute failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "查询失败"})
+		return
+	}
+
+	c.JSON(http.StatusOK, progress)
+}
+
+// AddPersonalWord 向个人词库添加单词
+// POST /api/wordbooks/personal/words
+func AddPersonalWord(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("AddPersonalWord - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	var req PersonalWordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.Warn("AddPersonalWord - Invalid request: %v", err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	db := database.GetDB()
+	var word models.Word
+	if err := db.First(&word, req.WordID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "单词不存在"})
+		return
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		book, err := ensurePersonalWordbook(tx, userID.(uint))
+		if err != nil {
+			return err
+		}
+		var exists int64
+		if err := tx.Model(&models.WordbookWord{}).
+			Where("wordbook_id = ? AND word_id = ?", book.ID, word.ID).
+			Count(&exists).Error; err != nil {
+			return err
+		}
+		if exists > 0 {
+			return nil
+		}
+		if err := tx.Create(&models.WordbookWord{
+			WordbookID: book.ID,
+			WordID:     word.ID,
+			Position:   book.WordCount,
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Model(book).Update("word_count", gorm.Expr("word_count + 1")).Error
+	})
+	if err != nil {
+		utils.Error("AddPersonalWord - Save failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "添加失败"})
+		return
+	}
+
+	utils.Info("AddPersonalWord - UserID: %v, WordID: %d", userID, word.ID)
+	c.JSON(http.StatusOK, word)
+}
+
+// RemovePersonalWord 从个人词库移除单词
+// DELETE /api/wordbooks/personal/words/:wordId
+func RemovePersonalWord(c *gin.Context) {
+	userID, exists := c.Get("userID")
+	if !exists {
+		utils.Warn("RemovePersonalWord - User not authenticated")
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未授权"})
+		return
+	}
+
+	wordID, ok := parseIDParam(c, "wordId")
+	if !ok {
+		return
+	}
+
+	var removed int64
+	err := database.GetDB().Transaction(func(tx *gorm.DB) error {
+		book, err := ensurePersonalWordbook(tx, userID.(uint))
+		if err != nil {
+			return err
+		}
+		result := tx.Where("wordbook_id = ? AND word_id = ?", book.ID, wordID).Delete(&models.WordbookWord{})
+		if result.Error != nil {
+			return result.Error
+		}
+		removed = result.RowsAffected
+		if removed == 0 {
+			return nil
+		}
+		return tx.Model(book).Update("word_count", gorm.Expr("word_count - 1")).Error
+	})
+	if err != nil {
+		utils.Error("RemovePersonalWord - Delete failed: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "移除失败"})
+		return
+	}
+	if removed == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "个人词库中没有该单词"})
+		return
+	}
+
+	utils.Info("RemovePersonalWord - UserID: %v, WordID: %d", userID, wordID)
+	c.JSON(http.StatusOK, gin.H{"message": "已移除"})
+}
+
+// setPrimaryWordbook 将订阅设为主词书，并把原主词书降为辅助词书
+func setPrimaryWordbook(tx *gorm.DB, userID uint, sub *models.UserWordbook) error {
+	if err := tx.Model(&models.UserWordbook{}).
+		Where("user_id = ? AND role = ? AND id <> ?", userID, models.WordbookRolePrimary, sub.ID).
+		Update("role", models.WordbookRoleAuxiliary).Error; err != nil {
+		return err
+	}
+	sub.Role = models.WordbookRolePrimary
+	sub.Paused = false
+	return tx.Model(sub).Updates(map[string]interface{}{"role": sub.Role, "paused": false}).Error
+}
+
+// ensurePrimaryWordbook 没有主词书时将最早订阅的辅助词书设为主词书
+func ensurePrimaryWordbook(tx *gorm.DB, userID uint) error {
+	var primaries int64
+	if err := tx.Model(&models.UserWordbook{}).
+		Where("user_id = ? AND role = ?", userID, models.WordbookRolePrimary).
+		Count(&primaries).Error; err != nil || primaries > 0 {
+		return err
+	}
+	var sub models.UserWordbook
+	err := tx.Where("user_id = ? AND role = ?", userID, models.WordbookRoleAuxiliary).Order("id ASC").First(&sub).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return setPrimaryWordbook(tx, userID, &sub)
+}
+
+// ensurePersonalWordbook 获取用户的个人词库，不存在时创建并订阅
+func ensurePersonalWordbook(tx *gorm.DB, userID uint) (*models.Wordbook, error) {
+	var book models.Wordbook
+	err := tx.Where("kind = ? AND owner_id = ?", models.WordbookKindPersonal, userID).First(&book).Error
+	if err == nil {
+		return &book, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	// 并发请求同时创建时由所有者唯一索引保证只有一本个人词库，未创建成功的请求读取已有词库
+	err = tx.Transaction(func(tx *gorm.DB) error {
+		book = models.Wordbook{
+			Name:    personalWordbookName,
+			Kind:    models.WordbookKindPersonal,
+			OwnerID: &userID,
+		}
+		err := tx.Clauses(clause.OnConflict{
+			Columns:     []clause.Column{{Name: "owner_id"}},
+			TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+			DoNothing:   true,
+		}).Create(&book).Error
+		if err != nil {
+			return err
+		}
+		if err := tx.Where("kind = ? AND owner_id = ?", models.WordbookKindPersonal, userID).First(&book).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "wordbook_id"}},
+			DoNothing: true,
+		}).Create(&models.UserWordbook{
+			UserID:     userID,
+			WordbookID: book.ID,
+			Role:       models.WordbookRolePersonal,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &book, nil
+}
+
+// computeWordbookProgress 统计用户在某本词书上的学习进度
+// 今日新词按用户的学习日历计算
+func computeWordbookProgress(db *gorm.DB, userID uint, bookID uint, cal study.Calendar) (*WordbookProgress, error) {
+	p := &WordbookProgress{WordbookID: bookID}
+
+	bookWords := db.Model(&models.WordbookWord{}).Select("word_id").Where("wordbook_id = ?", bookID)
+	if err := db.Model(&models.WordbookWord{}).Where("wordbook_id = ?", bookID).Count(&p.TotalWords).Error; err != nil {
+		return nil, err
+	}
+
+	learned := func() *gorm.DB {
+		return db.Model(&models.UserWordProgress{}).Where("user_id = ? AND word_id IN (?)", userID, bookWords)
+	}
+
+	now := time.Now()
+	startOfDay := cal.StartOfDay(now)
+
+	if err := learned().Count(&p.LearnedWords).Error; err != nil {
+		return nil, err
+	}
+	if err := learned().Where("created_at >= ?", startOfDay).Count(&p.TodayNewWords).Error; err != nil {
+		return nil, err
+	}
+	if err := learned().Where("due_at <= ?", now).Count(&p.PendingReviews).Error; err != nil {
+		return nil, err
+	}
+
+	if p.TotalWords > 0 {
+		p.Progress = float64(p.LearnedWords) / float64(p.TotalWords)
+	}
+	return p, nil
+}
+
+// parseIDParam 解析路径中的数字ID参数
+// 解析失败时直接返回400响应
+func parseIDParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		utils.Warn("Invalid %s parameter: %s", name, c.Param(name))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID参数无效"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// parsePagination 解析 limit/offset 查询参数，limit 超过上限时截断
+// 解析失败时直接返回400响应
+func parsePagination(c *gin.Context, defaultLimit, maxLimit int) (limit, offset int, ok bool) {
+	limit = defaultLimit
+	if s := c.Query("limit"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit 参数无效"})
+			return 0, 0, false
+		}
+		limit = min(n, maxLimit)
+	}
+	if s := c.Query("offset"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "offset 参数无效"})
+			return 0, 0, false
+		}
+		offset = n
+	}
+	return limit, offset, true
+}
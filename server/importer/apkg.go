@@ -0,0 +1,231 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// maxCollectionSize Anki数据库解压后的大小上限
+const maxCollectionSize = 200 << 20
+
+// ankiFieldAliases Anki笔记字段名到列的映射
+var ankiFieldAliases = map[string]int{
+	"word":          colWord,
+	"front":         colWord,
+	"headword":      colWord,
+	"单词":            colWord,
+	"正面":            colWord,
+	"phonetic":      colPhonetic,
+	"ipa":           colPhonetic,
+	"pronunciation": colPhonetic,
+	"音标":            colPhonetic,
+	"meaning":       colMeaning,
+	"definition":    colMeaning,
+	"back":          colMeaning,
+	"释义":            colMeaning,
+	"背面":            colMeaning,
+	"example":       colExamples,
+	"examples":      colExamples,
+	"sentence":      colExamples,
+	"例句":            colExamples,
+	"translation":   colTranslations,
+	"例句翻译":          colTranslations,
+	"tip":           colMemoryTip,
+	"memory_tip":    colMemoryTip,
+	"记忆技巧":          colMemoryTip,
+}
+
+// ankiNote Anki笔记
+type ankiNote struct {
+	ID   int64
+	Mid  int64
+	Flds string
+}
+
+// parseApkg 解析Anki导出包
+func parseApkg(data []byte) (*Dataset, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("打开apkg失败: %w", err)
+	}
+
+	var collection *zip.File
+	for _, name := range []string{"collection.anki21", "collection.anki2"} {
+		for _, f := range zr.File {
+			if f.Name == name {
+				collection = f
+				break
+			}
+		}
+		if collection != nil {
+			break
+		}
+	}
+	if collection == nil {
+		for _, f := range zr.File {
+			if f.Name == "collection.anki21b" {
+				return nil, errors.New("不支持新版Anki压缩格式，请在导出时勾选“支持旧版Anki”")
+			}
+		}
+		return nil, errors.New("apkg中没有找到collection.anki2")
+	}
+
+	path, err := extractToTemp(collection)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(path)
+
+	db, err := gorm.Open(sqlite.Open("file:"+path+"?mode=ro"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("打开Anki数据库失败: %w", err)
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	fieldNames, err := loadAnkiFieldNames(db)
+	if err != nil {
+		return nil, err
+	}
+
+	var notes []ankiNote
+	if err := db.Raw("SELECT id, mid, flds FROM notes ORDER BY id").Scan(&notes).Error; err != nil {
+		return nil, fmt.Errorf("读取Anki笔记失败: %w", err)
+	}
+
+	ds := &Dataset{Format: FormatApkg}
+	for i, n := range notes {
+		values := strings.Split(n.Flds, "\x1f")
+		columns := mapAnkiFields(fieldNames[n.Mid], len(values))
+
+		fields := make([]string, colCount)
+		for j, v := range values {
+			if col := columns[j]; col >= 0 && fields[col] == "" {
+				fields[col] = v
+			}
+		}
+		ds.Entries = append(ds.Entries, Entry{
+			Row:          i + 1,
+			Headword:     fields[colWord],
+			Phonetic:     fields[colPhonetic],
+			Meaning:      fields[colMeaning],
+			MemoryTip:    fields[colMemoryTip],
+			Examples:     splitList(cleanText(fields[colExamples])),
+			Translations: splitList(cleanText(fields[colTranslations])),
+		})
+	}
+	return ds, nil
+}
+
+// extractToTemp 将zip中的数据库解压到临时文件
+func extractToTemp(f *zip.File) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", fmt.Errorf("解压Anki数据库失败: %w", err)
+	}
+	defer rc.Close()
+
+	tmp, err := os.CreateTemp("", "kaikouyi-apkg-*.db")
+	if err != nil {
+		return "", err
+	}
+	n, err := io.Copy(tmp, io.LimitReader(rc, maxCollectionSize+1))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil && n > maxCollectionSize {
+		err = errors.New("Anki数据库过大")
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("解压Anki数据库失败: %w", err)
+	}
+	return tmp.Name(), nil
+}
+
+// loadAnkiFieldNames 读取每种笔记类型的字段名
+// 旧版本保存在 col.models JSON 中，较新版本保存在 fields 表中
+func loadAnkiFieldNames(db *gorm.DB) (map[int64][]string, error) {
+	names := map[int64][]string{}
+
+	var modelsJSON string
+	if err := db.Raw("SELECT models FROM col LIMIT 1").Scan(&modelsJSON).Error; err != nil {
+		return nil, fmt.Errorf("读取Anki笔记类型失败: %w", err)
+	}
+	if strings.TrimSpace(modelsJSON) != "" && modelsJSON != "{}" {
+		var noteTypes map[string]struct {
+			ID   int64 `json:"id"`
+			Flds []struct {
+				Name string `json:"name"`
+				Ord  int    `json:"ord"`
+			} `json:"flds"`
+		}
+		if err := json.Unmarshal([]byte(modelsJSON), &noteTypes); err != nil {
+			return nil, fmt.Errorf("解析Anki笔记类型失败: %w", err)
+		}
+		for _, nt := range noteTypes {
+			flds := nt.Flds
+			sort.Slice(flds, func(i, j int) bool { return flds[i].Ord < flds[j].Ord })
+			for _, f := range flds {
+				names[nt.ID] = append(names[nt.ID], f.Name)
+			}
+		}
+		return names, nil
+	}
+
+	var rows []struct {
+		Ntid int64
+		Ord  int
+		Name string
+	}
+	if err := db.Raw("SELECT ntid, ord, name FROM fields ORDER BY ntid, ord").Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("读取Anki字段失败: %w", err)
+	}
+	for _, r := range rows {
+		names[r.Ntid] = append(names[r.Ntid], r.Name)
+	}
+	return names, nil
+}
+
+// mapAnkiFields 按字段名确定每个字段对应的列
+// 无法识别单词或释义字段时，取前两个字段作为单词和释义
+func mapAnkiFields(names []string, count int) []int {
+	columns := make([]int, count)
+	seen := map[int]bool{}
+	for i := range columns {
+		columns[i] = -1
+		if i < len(names) {
+			if col, ok := ankiFieldAliases[strings.ToLower(strings.TrimSpace(names[i]))]; ok && !seen[col] {
+				columns[i] = col
+				seen[col] = true
+			}
+		}
+	}
+	if !seen[colWord] && count > 0 && columns[0] < 0 {
+		columns[0] = colWord
+		seen[colWord] = true
+	}
+	if !seen[colMeaning] {
+		for i := 1; i < count; i++ {
+			if columns[i] < 0 {
+				columns[i] = colMeaning
+				break
+			}
+		}
+	}
+	return columns
+}
@@ -0,0 +1,152 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// csv列名，按无表头时的默认顺序排列
+const (
+	colWord = iota
+	colPhonetic
+	colMeaning
+	colMemoryTip
+	colExamples
+	colTranslations
+	colFrequency
+	colCount
+)
+
+// headerAliases 表头别名到列的映射
+var headerAliases = map[string]int{
+	"word":         colWord,
+	"headword":     colWord,
+	"单词":           colWord,
+	"phonetic":     colPhonetic,
+	"ipa":          colPhonetic,
+	"音标":           colPhonetic,
+	"meaning":      colMeaning,
+	"definition":   colMeaning,
+	"释义":           colMeaning,
+	"memory_tip":   colMemoryTip,
+	"tip":          colMemoryTip,
+	"记忆技巧":         colMemoryTip,
+	"examples":     colExamples,
+	"example":      colExamples,
+	"例句":           colExamples,
+	"translations": colTranslations,
+	"translation":  colTranslations,
+	"翻译":           colTranslations,
+	"frequency":    colFrequency,
+	"rank":         colFrequency,
+	"词频":           colFrequency,
+}
+
+// parseDelimited 解析CSV/TSV文件
+func parseDelimited(data []byte, comma rune) (*Dataset, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	if comma == '\t' {
+		// TSV 一般不使用引号转义
+		r.LazyQuotes = false
+	}
+
+	format := FormatCSV
+	if comma == '\t' {
+		format = FormatTSV
+	}
+	ds := &Dataset{Format: format}
+
+	columns := []int{colWord, colPhonetic, colMeaning, colMemoryTip, colExamples, colTranslations, colFrequency}
+	first := true
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				ds.Issues = append(ds.Issues, Issue{Row: perr.Line, Level: issueError, Message: perr.Err.Error()})
+				continue
+			}
+			return nil, err
+		}
+		// FieldPos 只能在读取成功后调用
+		line, _ := r.FieldPos(0)
+
+		if first {
+			first = false
+			if mapped, ok := parseHeader(record); ok {
+				columns = mapped
+				continue
+			}
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+
+		fields := make([]string, colCount)
+		for i, v := range record {
+			if i < len(columns) && columns[i] >= 0 {
+				fields[columns[i]] = v
+			}
+		}
+
+		e := Entry{
+			Row:          line,
+			Headword:     fields[colWord],
+			Phonetic:     fields[colPhonetic],
+			Meaning:      fields[colMeaning],
+			MemoryTip:    fields[colMemoryTip],
+			Examples:     splitList(fields[colExamples]),
+			Translations: splitList(fields[colTranslations]),
+		}
+		if s := strings.TrimSpace(fields[colFrequency]); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil {
+				ds.Issues = append(ds.Issues, Issue{Row: line, Headword: e.Headword, Level: issueError, Message: fmt.Sprintf("词频 %q 不是整数", s)})
+				continue
+			}
+			e.Frequency = n
+		}
+		ds.Entries = append(ds.Entries, e)
+	}
+	return ds, nil
+}
+
+// parseHeader 识别表头行
+// 至少识别出单词列和释义列时视为表头，未识别的列被忽略
+func parseHeader(record []string) ([]int, bool) {
+	columns := make([]int, len(record))
+	seen := map[int]bool{}
+	for i, name := range record {
+		col, ok := headerAliases[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			columns[i] = -1
+			continue
+		}
+		columns[i] = col
+		seen[col] = true
+	}
+	return columns, seen[colWord] && seen[colMeaning]
+}
+
+// isBlankRecord 判断是否为空行
+func isBlankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,37 @@
+// Package importer 词书批量导入
+//
+// 支持以下文件格式，格式由文件扩展名决定：
+//
+//   - .csv / .tsv：首行可为表头，列名支持 word, phonetic, meaning, memory_tip,
+//     examples, translations, frequency（也可使用中文列名 单词/音标/释义/记忆技巧/例句/翻译/词频）。
+//     没有可识别的表头时按上述顺序解析。多个例句或翻译用 | 分隔。
+//   - .json：见下方结构，也可直接提供 words 数组。
+//   - .apkg：Anki 导出包（zip 内含 SQLite 数据库 collection.anki2 / collection.anki21），
+//     按笔记字段名识别单词、音标、释义和例句，无法识别时取前两个字段作为单词和释义。
+//
+// JSON 结构：
+//
+//	{
+//	  "wordbook": {
+//	    "name": "雅思核心词汇",
+//	    "description": "雅思高频核心词汇",
+//	    "category": "考试",
+//	    "level": "B2"
+//	  },
+//	  "words": [
+//	    {
+//	      "word": "abandon",
+//	      "phonetic": "/əˈbændən/",
+//	      "meaning": "v. 放弃；抛弃",
+//	      "memory_tip": "a + band(乐队) + on → 乐队解散了，放弃",
+//	      "examples": ["They had to abandon the car."],
+//	      "translations": ["他们不得不弃车。"],
+//	      "frequency": 1200
+//	    }
+//	  ]
+//	}
+//
+// 导入流程先解析和校验全部条目（单词去重、音标和释义校验），
+// 再在单个事务中写入单词并关联到词书。试运行模式执行相同流程后回滚，
+// 返回的报告与正式导入一致。
+package importer
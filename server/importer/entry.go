@@ -0,0 +1,209 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// maxHeadwordLength 单词最大长度（字符）
+	maxHeadwordLength = 100
+	// maxMeaningLength 释义最大长度（字符）
+	maxMeaningLength = 500
+	// maxIssues 报告中保留的问题条数上限
+	maxIssues = 500
+)
+
+// Entry 待导入的单词条目
+type Entry struct {
+	Row          int      // 源文件中的行号（CSV）或条目序号（JSON/Anki）
+	Headword     string   // 英文单词
+	Phonetic     string   // 音标
+	Meaning      string   // 中文释义
+	MemoryTip    string   // 记忆技巧
+	Examples     []string // 例句
+	Translations []string // 例句翻译
+	Frequency    int      // 词频排名
+}
+
+// WordbookMeta 词书元数据
+type WordbookMeta struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+	Level       string `json:"level"`
+}
+
+// Dataset 解析后的导入数据
+type Dataset struct {
+	Format   string
+	Wordbook WordbookMeta // 文件自带的词书信息（仅 JSON）
+	Entries  []Entry
+	Issues   []Issue // 解析阶段发现的问题
+}
+
+// Issue 导入问题
+type Issue struct {
+	Row      int    `json:"row"`
+	Headword string `json:"word,omitempty"`
+	Level    string `json:"level"` // error: 条目被跳过; warning: 条目已导入或已合并
+	Message  string `json:"message"`
+}
+
+const (
+	issueError   = "error"
+	issueWarning = "warning"
+)
+
+var (
+	tagPattern   = regexp.MustCompile(`(?i)<br\s*/?>|<div>|</div>|<p>|</p>`)
+	htmlPattern  = regexp.MustCompile(`<[^>]*>`)
+	soundPattern = regexp.MustCompile(`\[sound:[^\]]*\]`)
+	spacePattern = regexp.MustCompile(`[ \t\x{00a0}]+`)
+)
+
+// cleanText 去除HTML标签、Anki音频引用并规范空白
+func cleanText(s string) string {
+	s = soundPattern.ReplaceAllString(s, "")
+	s = tagPattern.ReplaceAllString(s, "\n")
+	s = htmlPattern.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+
+	lines := strings.Split(s, "\n")
+	out := lines[:0]
+	for _, line := range lines {
+		line = strings.TrimSpace(spacePattern.ReplaceAllString(line, " "))
+		if line != "" {
+			out = append(out, line)
+		}
+	}
+	return strings.Join(out, "\n")
+}
+
+// splitList 拆分用 | 或换行分隔的列表
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.FieldsFunc(s, func(r rune) bool { return r == '|' || r == '\n' }) {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
+
+// normalize 清洗并校验条目
+// 返回的条目中音标统一为 /.../ 形式
+func (e Entry) normalize() (Entry, error) {
+	e.Headword = spacePattern.ReplaceAllString(strings.TrimSpace(cleanText(e.Headword)), " ")
+	e.Phonetic = cleanText(e.Phonetic)
+	e.Meaning = cleanText(e.Meaning)
+	e.MemoryTip = cleanText(e.MemoryTip)
+
+	if err := validateHeadword(e.Headword); err != nil {
+		return e, err
+	}
+	phonetic, err := normalizePhonetic(e.Phonetic)
+	if err != nil {
+		return e, err
+	}
+	e.Phonetic = phonetic
+	if err := validateMeaning(e.Meaning, e.Headword); err != nil {
+		return e, err
+	}
+	if e.Frequency < 0 {
+		return e, errors.New("词频不能为负数")
+	}
+	if len(e.Translations) > 0 && len(e.Translations) != len(e.Examples) {
+		return e, fmt.Errorf("例句(%d)与翻译(%d)数量不一致", len(e.Examples), len(e.Translations))
+	}
+	return e, nil
+}
+
+// dedupeKey 单词去重键，忽略大小写
+func (e Entry) dedupeKey() string {
+	return strings.ToLower(e.Headword)
+}
+
+// validateHeadword 校验单词
+// 允许字母、空格、连字符、撇号和点号，如 "a.m."、"mother-in-law"、"give up"
+func validateHeadword(w string) error {
+	if w == "" {
+		return errors.New("单词为空")
+	}
+	if utf8.RuneCountInString(w) > maxHeadwordLength {
+		return fmt.Errorf("单词超过%d个字符", maxHeadwordLength)
+	}
+	hasLetter := false
+	for _, r := range w {
+		switch {
+		case unicode.Is(unicode.Latin, r): // 包括 café、naïve 中的带音调字母
+			hasLetter = true
+		case r == ' ' || r == '-' || r == '\'' || r == '.' || r == '’':
+		default:
+			return fmt.Errorf("单词包含非法字符 %q", r)
+		}
+	}
+	if !hasLetter {
+		return errors.New("单词不包含字母")
+	}
+	return nil
+}
+
+// normalizePhonetic 校验音标并统一为 /.../ 形式
+// 音标可选；接受 /.../ 或 [...] 包裹及无包裹的写法，多个读音用 ; 或 , 分隔
+func normalizePhonetic(p string) (string, error) {
+	p = strings.TrimSpace(p)
+	if p == "" {
+		return "", nil
+	}
+	if strings.HasPrefix(p, "[") && strings.HasSuffix(p, "]") || strings.HasPrefix(p, "/") && strings.HasSuffix(p, "/") {
+		p = strings.TrimSpace(p[1 : len(p)-1])
+	}
+	if p == "" {
+		return "", nil
+	}
+	for _, r := range p {
+		if !isPhoneticRune(r) {
+			return "", fmt.Errorf("音标包含非法字符 %q", r)
+		}
+	}
+	return "/" + p + "/", nil
+}
+
+// isPhoneticRune 判断字符是否可出现在国际音标中
+func isPhoneticRune(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z':
+		return true
+	case r >= 0x0250 && r <= 0x02FF: // IPA扩展、间距修饰符（ˈ ˌ ː）
+		return true
+	case r >= 0x0300 && r <= 0x036F: // 组合附加符号
+		return true
+	case r >= 0x1D00 && r <= 0x1DBF: // 语音扩展
+		return true
+	}
+	switch r {
+	case 'æ', 'ð', 'ŋ', 'θ', ' ', '(', ')', '.', ',', ';', '-', '/', '\'', ':', '·':
+		return true
+	}
+	return false
+}
+
+// validateMeaning 校验释义
+func validateMeaning(m string, headword string) error {
+	if m == "" {
+		return errors.New("释义为空")
+	}
+	if utf8.RuneCountInString(m) > maxMeaningLength {
+		return fmt.Errorf("释义超过%d个字符", maxMeaningLength)
+	}
+	if strings.EqualFold(m, headword) {
+		return errors.New("释义与单词相同，可能是列顺序错误")
+	}
+	return nil
+}
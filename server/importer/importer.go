@@ -0,0 +1,329 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gorm.io/gorm"
+	"server/models"
+)
+
+// 支持的导入格式
+const (
+	FormatCSV  = "csv"
+	FormatTSV  = "tsv"
+	FormatJSON = "json"
+	FormatApkg = "apkg"
+)
+
+// lookupBatchSize 查询已有单词时每批的数量
+const lookupBatchSize = 500
+
+var (
+	// ErrNoWordbookName 选项和文件中都没有词书名称
+	ErrNoWordbookName = errors.New("未指定词书名称")
+
+	// errDryRun 试运行结束时用于回滚事务
+	errDryRun = errors.New("dry run")
+)
+
+// Options 导入选项
+type Options struct {
+	Wordbook  WordbookMeta // 目标词书，Name 为空时使用文件中的词书名
+	DryRun    bool         // 只生成报告，不写入数据库
+	Overwrite bool         // 覆盖已有单词的非空字段，默认只补全空字段
+}
+
+// Report 导入报告
+type Report struct {
+	Format       string  `json:"format"`
+	DryRun       bool    `json:"dry_run"`
+	Wordbook     string  `json:"wordbook"`
+	WordbookID   uint    `json:"wordbook_id,omitempty"` // 试运行时不返回
+	NewWordbook  bool    `json:"new_wordbook"`          // 是否新建词书
+	Total        int     `json:"total"`                 // 读取的条目数
+	Valid        int     `json:"valid"`                 // 通过校验且去重后的条目数
+	Invalid      int     `json:"invalid"`               // 校验失败被跳过的条目数
+	Duplicates   int     `json:"duplicates"`            // 文件内重复的条目数
+	WordsCreated int     `json:"words_created"`         // 新增单词数
+	WordsUpdated int     `json:"words_updated"`         // 已存在并被更新的单词数
+	WordsLinked  int     `json:"words_linked"`          // 新加入词书的单词数
+	Issues       []Issue `json:"issues"`
+	Truncated    bool    `json:"issues_truncated"` // 问题过多时只保留前若干条
+}
+
+// DetectFormat 根据文件名判断导入格式
+func DetectFormat(filename string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".csv":
+		return FormatCSV, nil
+	case ".tsv", ".txt":
+		return FormatTSV, nil
+	case ".json":
+		return FormatJSON, nil
+	case ".apkg":
+		return FormatApkg, nil
+	default:
+		return "", fmt.Errorf("不支持的文件类型 %q", ext)
+	}
+}
+
+// Parse 按格式解析文件内容
+func Parse(format string, data []byte) (*Dataset, error) {
+	switch format {
+	case FormatCSV:
+		return parseDelimited(data, ',')
+	case FormatTSV:
+		return parseDelimited(data, '\t')
+	case FormatJSON:
+		return parseJSON(data)
+	case FormatApkg:
+		return parseApkg(data)
+	default:
+		return nil, fmt.Errorf("不支持的导入格式 %q", format)
+	}
+}
+
+// Import 校验并导入解析后的数据
+// 所有写入在同一事务中完成；试运行时执行相同的写入后回滚
+func Import(db *gorm.DB, ds *Dataset, opts Options) (*Report, error) {
+	meta := opts.Wordbook
+	if meta.Name == "" {
+		meta = ds.Wordbook
+	}
+	meta.Name = strings.TrimSpace(meta.Name)
+	if meta.Name == "" {
+		return nil, ErrNoWordbookName
+	}
+
+	report := &Report{
+		Format:   ds.Format,
+		DryRun:   opts.DryRun,
+		Wordbook: meta.Name,
+		Total:    len(ds.Entries),
+	}
+	for _, issue := range ds.Issues {
+		report.addIssue(issue)
+		if issue.Level == issueError {
+			report.Invalid++
+		}
+	}
+
+	entries := report.prepare(ds.Entries)
+	report.Valid = len(entries)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		book, created, err := findOrCreateWordbook(tx, meta)
+		if err != nil {
+			return err
+		}
+		report.NewWordbook = created
+
+		words, err := upsertWords(tx, entries, opts.Overwrite, report)
+		if err != nil {
+			return err
+		}
+		if err := linkWords(tx, book, words, report); err != nil {
+			return err
+		}
+		report.WordbookID = book.ID
+
+		if opts.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if opts.DryRun && errors.Is(err, errDryRun) {
+		if report.NewWordbook {
+			report.WordbookID = 0
+		}
+		return report, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// prepare 校验条目并按单词去重，先出现的条目优先
+func (r *Report) prepare(entries []Entry) []Entry {
+	out := make([]Entry, 0, len(entries))
+	firstRow := make(map[string]int, len(entries))
+	for _, e := range entries {
+		ne, err := e.normalize()
+		if err != nil {
+			r.Invalid++
+			r.addIssue(Issue{Row: e.Row, Headword: strings.TrimSpace(e.Headword), Level: issueError, Message: err.Error()})
+			continue
+		}
+		key := ne.dedupeKey()
+		if row, ok := firstRow[key]; ok {
+			r.Duplicates++
+			r.addIssue(Issue{Row: e.Row, Headword: ne.Headword, Level: issueWarning, Message: fmt.Sprintf("与第%d条重复，已忽略", row)})
+			continue
+		}
+		firstRow[key] = e.Row
+		out = append(out, ne)
+	}
+	return out
+}
+
+// addIssue 记录问题，超过上限时只计数
+func (r *Report) addIssue(issue Issue) {
+	if len(r.Issues) >= maxIssues {
+		r.Truncated = true
+		return
+	}
+	r.Issues = append(r.Issues, issue)
+}
+
+// findOrCreateWordbook 按名称查找官方词书，不存在时创建
+func findOrCreateWordbook(tx *gorm.DB, meta WordbookMeta) (*models.Wordbook, bool, error) {
+	var book models.Wordbook
+	err := tx.Where("kind = ? AND name = ?", models.WordbookKindSystem, meta.Name).First(&book).Error
+	if err == nil {
+		updates := map[string]interface{}{}
+		if meta.Description != "" {
+			updates["description"] = meta.Description
+		}
+		if meta.Category != "" {
+			updates["category"] = meta.Category
+		}
+		if meta.Level != "" {
+			updates["level"] = meta.Level
+		}
+		if len(updates) > 0 {
+			if err := tx.Model(&book).Updates(updates).Error; err != nil {
+				return nil, false, err
+			}
+		}
+		return &book, false, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, err
+	}
+
+	book = models.Wordbook{
+		Name:        meta.Name,
+		Description: meta.Description,
+		Category:    meta.Category,
+		Level:       meta.Level,
+		Kind:        models.WordbookKindSystem,
+	}
+	if err := tx.Create(&book).Error; err != nil {
+		return nil, false, err
+	}
+	return &book, true, nil
+}
+
+// upsertWords 写入单词，已存在的单词按 overwrite 规则合并
+// 返回与 entries 顺序一致的单词记录
+func upsertWords(tx *gorm.DB, entries []Entry, overwrite bool, report *Report) ([]models.Word, error) {
+	existing := make(map[string]models.Word, len(entries))
+	for start := 0; start < len(entries); start += lookupBatchSize {
+		end := start + lookupBatchSize
+		if end > len(entries) {
+			end = len(entries)
+		}
+		keys := make([]string, 0, end-start)
+		for _, e := range entries[start:end] {
+			keys = append(keys, e.dedupeKey())
+		}
+		var found []models.Word
+		if err := tx.Where("LOWER(headword) IN ?", keys).Find(&found).Error; err != nil {
+			return nil, err
+		}
+		for _, w := range found {
+			existing[strings.ToLower(w.Headword)] = w
+		}
+	}
+
+	words := make([]models.Word, 0, len(entries))
+	for _, e := range entries {
+		w, ok := existing[e.dedupeKey()]
+		if !ok {
+			w = models.Word{
+				Headword:     e.Headword,
+				Phonetic:     e.Phonetic,
+				Meaning:      e.Meaning,
+				MemoryTip:    e.MemoryTip,
+				Examples:     e.Examples,
+				Translations: e.Translations,
+				Frequency:    e.Frequency,
+			}
+			if err := tx.Create(&w).Error; err != nil {
+				return nil, fmt.Errorf("创建单词 %s 失败: %w", e.Headword, err)
+			}
+			report.WordsCreated++
+			words = append(words, w)
+			continue
+		}
+
+		if mergeWord(&w, e, overwrite) {
+			if err := tx.Save(&w).Error; err != nil {
+				return nil, fmt.Errorf("更新单词 %s 失败: %w", e.Headword, err)
+			}
+			report.WordsUpdated++
+		}
+		words = append(words, w)
+	}
+	return words, nil
+}
+
+// mergeWord 将导入条目合并到已有单词
+// 返回是否有字段变化
+func mergeWord(w *models.Word, e Entry, overwrite bool) bool {
+	changed := false
+	setString := func(dst *string, v string) {
+		if v != "" && *dst != v && (overwrite || *dst == "") {
+			*dst = v
+			changed = true
+		}
+	}
+	setString(&w.Phonetic, e.Phonetic)
+	setString(&w.Meaning, e.Meaning)
+	setString(&w.MemoryTip, e.MemoryTip)
+	if len(e.Examples) > 0 && (overwrite || len(w.Examples) == 0) {
+		w.Examples = e.Examples
+		w.Translations = e.Translations
+		changed = true
+	}
+	if e.Frequency > 0 && w.Frequency != e.Frequency && (overwrite || w.Frequency == 0) {
+		w.Frequency = e.Frequency
+		changed = true
+	}
+	return changed
+}
+
+// linkWords 将单词加入词书，已在词书中的单词保持原有顺序
+func linkWords(tx *gorm.DB, book *models.Wordbook, words []models.Word, report *Report) error {
+	var linked []uint
+	if err := tx.Model(&models.WordbookWord{}).Where("wordbook_id = ?", book.ID).Pluck("word_id", &linked).Error; err != nil {
+		return err
+	}
+	inBook := make(map[uint]bool, len(linked))
+	for _, id := range linked {
+		inBook[id] = true
+	}
+
+	position := len(linked)
+	links := make([]models.WordbookWord, 0, len(words))
+	for _, w := range words {
+		if inBook[w.ID] {
+			continue
+		}
+		inBook[w.ID] = true
+		links = append(links, models.WordbookWord{WordbookID: book.ID, WordID: w.ID, Position: position})
+		position++
+	}
+	if len(links) > 0 {
+		if err := tx.CreateInBatches(links, lookupBatchSize).Error; err != nil {
+			return err
+		}
+	}
+	report.WordsLinked = len(links)
+
+	return tx.Model(book).Update("word_count", len(inBook)).Error
+}
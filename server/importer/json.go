@@ -0,0 +1,59 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// jsonFile JSON导入文件结构，见包文档
+type jsonFile struct {
+	Wordbook WordbookMeta `json:"wordbook"`
+	Words    []jsonWord   `json:"words"`
+}
+
+// jsonWord JSON导入文件中的单词
+type jsonWord struct {
+	Word         string   `json:"word"`
+	Phonetic     string   `json:"phonetic"`
+	Meaning      string   `json:"meaning"`
+	MemoryTip    string   `json:"memory_tip"`
+	Examples     []string `json:"examples"`
+	Translations []string `json:"translations"`
+	Frequency    int      `json:"frequency"`
+}
+
+// parseJSON 解析JSON文件
+// 顶层可以是完整结构，也可以直接是单词数组
+func parseJSON(data []byte) (*Dataset, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	var file jsonFile
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &file.Words); err != nil {
+			return nil, fmt.Errorf("解析JSON失败: %w", err)
+		}
+	} else {
+		dec := json.NewDecoder(bytes.NewReader(trimmed))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&file); err != nil {
+			return nil, fmt.Errorf("解析JSON失败: %w", err)
+		}
+	}
+
+	ds := &Dataset{Format: FormatJSON, Wordbook: file.Wordbook}
+	for i, w := range file.Words {
+		ds.Entries = append(ds.Entries, Entry{
+			Row:          i + 1,
+			Headword:     w.Word,
+			Phonetic:     w.Phonetic,
+			Meaning:      w.Meaning,
+			MemoryTip:    w.MemoryTip,
+			Examples:     w.Examples,
+			Translations: w.Translations,
+			Frequency:    w.Frequency,
+		})
+	}
+	return ds, nil
+}
@@ -0,0 +1,190 @@
+package listening
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"server/models"
+)
+
+const (
+	// MaxTags 每个材料的标签上限
+	MaxTags = 20
+	// maxTagLength 单个标签长度上限（字符）
+	maxTagLength = 50
+	// timeEpsilon 时间比较容差（秒），吸收字幕时间戳的舍入误差
+	timeEpsilon = 0.001
+)
+
+// ValidateSentences 校验句子时间轴
+// 要求每句 0 <= start < end，按开始时间排列且互不重叠；duration > 0 时结束时间不得超过音频时长
+func ValidateSentences(sentences []models.ListeningSentence, duration float64) error {
+	prevEnd := 0.0
+	for i, s := range sentences {
+		n := i + 1
+		if strings.TrimSpace(s.Text) == "" {
+			return fmt.Errorf("第%d句原文为空", n)
+		}
+		if math.IsNaN(s.StartTime) || math.IsNaN(s.EndTime) || math.IsInf(s.StartTime, 0) || math.IsInf(s.EndTime, 0) {
+			return fmt.Errorf("第%d句时间无效", n)
+		}
+		if s.StartTime < 0 {
+			return fmt.Errorf("第%d句开始时间不能为负", n)
+		}
+		if s.EndTime <= s.StartTime {
+			return fmt.Errorf("第%d句结束时间必须晚于开始时间", n)
+		}
+		if i > 0 && s.StartTime < prevEnd-timeEpsilon {
+			if s.StartTime < sentences[i-1].StartTime {
+				return fmt.Errorf("第%d句开始时间早于第%d句，句子必须按时间顺序排列", n, i)
+			}
+			return fmt.Errorf("第%d句与第%d句时间重叠", n, i)
+		}
+		if duration > 0 && s.EndTime > duration+timeEpsilon {
+			return fmt.Errorf("第%d句结束时间超出音频时长", n)
+		}
+		prevEnd = s.EndTime
+	}
+	return nil
+}
+
+// NormalizeTags 去除空白和重复标签，统一为小写
+func NormalizeTags(tags []string) ([]string, error) {
+	seen := make(map[string]bool, len(tags))
+	out := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.ToLower(strings.TrimSpace(t))
+		if t == "" || seen[t] {
+			continue
+		}
+		if len([]rune(t)) > maxTagLength {
+			return nil, fmt.Errorf("标签过长: %s", t)
+		}
+		seen[t] = true
+		out = append(out, t)
+	}
+	if len(out) > MaxTags {
+		return nil, fmt.Errorf("标签数量不能超过%d个", MaxTags)
+	}
+	return out, nil
+}
+
+// ReplaceSentences 用新的句子列表替换材料原有句子，并更新句子数
+// 句序按列表顺序从1重新编号；同一句序的句子原地更新以保留句子ID，
+// 挖空题和跟读记录中引用的句子ID仍然有效，只删除超出新句数的句子。
+// 训练题保存了片段时间和选项，文本或时间变化的句子上的训练题随之作废
+func ReplaceSentences(tx *gorm.DB, materialID uint, sentences []models.ListeningSentence) error {
+	var old []models.ListeningSentence
+	if err := tx.Unscoped().Select("id", "seq", "text", "start_time", "end_time").
+		Where("material_id = ?", materialID).Find(&old).Error; err != nil {
+		return err
+	}
+	for i := range sentences {
+		sentences[i].ID = 0
+		sentences[i].MaterialID = materialID
+		sentences[i].Seq = i + 1
+	}
+	var changed []uint
+	for _, o := range old {
+		if o.Seq > len(sentences) {
+			changed = append(changed, o.ID)
+			continue
+		}
+		n := sentences[o.Seq-1]
+		if n.Text != o.Text || n.StartTime != o.StartTime || n.EndTime != o.EndTime {
+			changed = append(changed, o.ID)
+		}
+	}
+	if len(changed) > 0 {
+		if err := tx.Where("sentence_id IN ?", changed).Delete(&models.DrillItem{}).Error; err != nil {
+			return err
+		}
+	}
+	if len(sentences) > 0 {
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "material_id"}, {Name: "seq"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"text", "translation", "start_time", "end_time", "keywords", "pronunciation", "updated_at", "deleted_at",
+			}),
+		}).CreateInBatches(sentences, 200).Error
+		if err != nil {
+			return err
+		}
+	}
+	if err := tx.Unscoped().Where("material_id = ? AND seq > ?", materialID, len(sentences)).
+		Delete(&models.ListeningSentence{}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.ListeningMaterial{}).Where("id = ?", materialID).
+		Update("sentence_count", len(sentences)).Error
+}
+
+// SetTags 替换材料的标签
+func SetTags(tx *gorm.DB, materialID uint, tags []string) error {
+	if err := tx.Where("material_id = ?", materialID).Delete(&models.ListeningMaterialTag{}).Error; err != nil {
+		return err
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+	rows := make([]models.ListeningMaterialTag, 0, len(tags))
+	for _, t := range tags {
+		rows = append(rows, models.ListeningMaterialTag{MaterialID: materialID, Tag: t})
+	}
+	return tx.Create(&rows).Error
+}
+
+// LoadTags 为材料列表填充标签
+func LoadTags(db *gorm.DB, materials []models.ListeningMaterial) error {
+	if len(materials) == 0 {
+		return nil
+	}
+	ids := make([]uint, 0, len(materials))
+	for _, m := range materials {
+		ids = append(ids, m.ID)
+	}
+	var rows []models.ListeningMaterialTag
+	if err := db.Where("material_id IN ?", ids).Order("tag ASC").Find(&rows).Error; err != nil {
+		return err
+	}
+	byMaterial := make(map[uint][]string, len(materials))
+	for _, r := range rows {
+		byMaterial[r.MaterialID] = append(byMaterial[r.MaterialID], r.Tag)
+	}
+	for i := range materials {
+		materials[i].Tags = byMaterial[materials[i].ID]
+		if materials[i].Tags == nil {
+			materials[i].Tags = []string{}
+		}
+	}
+	return nil
+}
+
+// CreateMaterial 在一个事务中创建材料及其句子和标签
+func CreateMaterial(db *gorm.DB, material *models.ListeningMaterial, sentences []models.ListeningSentence, tags []string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(material).Error; err != nil {
+			return err
+		}
+		if err := ReplaceSentences(tx, material.ID, sentences); err != nil {
+			return err
+		}
+		if err := SyncShadowing(tx, material); err != nil {
+			return err
+		}
+		return SetTags(tx, material.ID, tags)
+	})
+}
+
+// SyncShadowing 跟读类型的材料同时登记为影子跟读材料，已登记的保持不变
+// 改为其他类型时保留登记记录，跟读接口只接受 kind=shadowing 的材料
+func SyncShadowing(tx *gorm.DB, material *models.ListeningMaterial) error {
+	if material.Kind != models.MaterialKindShadowing {
+		return nil
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.ShadowingMaterial{ListeningMaterialID: material.ID}).Error
+}
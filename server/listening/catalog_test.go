@@ -0,0 +1,58 @@
+package listening
+
+import (
+	"testing"
+
+	"server/models"
+)
+
+func TestReplaceSentencesInvalidatesDrillItems(t *testing.T) {
+	db, material := newProgressDB(t)
+	if err := db.AutoMigrate(&models.DrillItem{}); err != nil {
+		t.Fatal(err)
+	}
+	var old []models.ListeningSentence
+	db.Where("material_id = ?", material.ID).Order("seq").Find(&old)
+	for _, s := range old {
+		item := models.DrillItem{Phenomenon: "contraction", Category: "connected", SentenceID: s.ID, Text: s.Text}
+		if err := db.Create(&item).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 第一句只改翻译，第二句改了文本，新增第三句
+	err := ReplaceSentences(db, material.ID, []models.ListeningSentence{
+		{Text: "I want to go home.", Translation: "我想回家。"},
+		{Text: "It's going to snow later."},
+		{Text: "Take an umbrella."},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sentences []models.ListeningSentence
+	db.Where("material_id = ?", material.ID).Order("seq").Find(&sentences)
+	if len(sentences) != 3 || sentences[0].ID != old[0].ID || sentences[1].ID != old[1].ID {
+		t.Fatalf("sentences = %+v, want ids kept in place", sentences)
+	}
+	if sentences[0].Translation != "我想回家。" || sentences[1].Text != "It's going to snow later." {
+		t.Errorf("sentences not updated: %+v", sentences)
+	}
+
+	var items []models.DrillItem
+	db.Find(&items)
+	if len(items) != 1 || items[0].SentenceID != old[0].ID {
+		t.Errorf("drill items = %+v, want only the item on the unchanged sentence", items)
+	}
+
+	// 句子减少时删除多出的句子，其上的训练题一并作废
+	if err := ReplaceSentences(db, material.ID, []models.ListeningSentence{{Text: "Hi."}}); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	db.Model(&models.ListeningSentence{}).Where("material_id = ?", material.ID).Count(&count)
+	db.Find(&items)
+	if count != 1 || len(items) != 0 {
+		t.Errorf("sentences = %d, drill items = %d, want 1, 0", count, len(items))
+	}
+}
@@ -0,0 +1,277 @@
+package listening
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"server/dictation"
+	"server/models"
+)
+
+var (
+	// ErrLayerLocked 层级尚未解锁
+	ErrLayerLocked = errors.New("该层级尚未解锁")
+	// ErrInvalidResult 练习结果中的层级或句序无效
+	ErrInvalidResult = errors.New("练习结果无效")
+)
+
+// SentenceResult 单句练习结果
+// 是否听对由服务端批改作答内容得出，不接受客户端自报
+type SentenceResult struct {
+	Seq  int    `json:"seq" binding:"required,min=1"`
+	Text string `json:"text" binding:"max=2000"` // L2 为填空补全后的整句，L3 为听写内容，L1 忽略
+}
+
+// State 材料的三层剥离进度及续练位置
+type State struct {
+	*models.ListeningProgress
+	L2Accuracy       float64                            `json:"l2_accuracy"`        // L2 正确率 (0-1)
+	L3Accuracy       float64                            `json:"l3_accuracy"`        // L3 正确率 (0-1)
+	L3UnlockAccuracy float64                            `json:"l3_unlock_accuracy"` // 解锁 L3 所需的 L2 正确率
+	ResumeLayer      int                                `json:"resume_layer"`       // 继续练习的层级
+	ResumeSeq        int                                `json:"resume_seq"`         // 继续练习的句序，0 表示该层已全部完成
+	Sentences        []models.ListeningSentenceProgress `json:"sentences"`          // 各句在各层的练习结果
+}
+
+// UnlockedLayer 根据各层完成情况计算已解锁的最高层级
+// L1 始终可练；L1 全部完成后解锁 L2；L2 全部完成且正确率达到 threshold 后解锁 L3
+func UnlockedLayer(p *models.ListeningProgress, threshold float64) int {
+	total := p.TotalSentences
+	switch {
+	case total == 0 || p.L1Completed < total:
+		return models.ListeningLayer1
+	case p.L2Completed < total || accuracy(p.L2Correct, total) < threshold:
+		return models.ListeningLayer2
+	default:
+		return models.ListeningLayer3
+	}
+}
+
+// accuracy 正确率
+func accuracy(correct, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(correct) / float64(total)
+}
+
+// Record 记录一批练习结果并重新汇总进度
+// 同一句在同一层重复练习时以最近一次结果为准；提交未解锁的层级返回 ErrLayerLocked
+func Record(tx *gorm.DB, userID uint, material *models.ListeningMaterial, layer int, results []SentenceResult, threshold float64, now time.Time) (*models.ListeningProgress, error) {
+	if layer < models.ListeningLayer1 || layer > models.ListeningLayer3 {
+		return nil, fmt.Errorf("%w: 层级 %d 不存在", ErrInvalidResult, layer)
+	}
+	for _, r := range results {
+		if r.Seq < 1 || r.Seq > material.SentenceCount {
+			return nil, fmt.Errorf("%w: 句序 %d 超出范围", ErrInvalidResult, r.Seq)
+		}
+	}
+
+	progress, err := summarize(tx, userID, material, threshold)
+	if err != nil {
+		return nil, err
+	}
+	if layer > progress.UnlockedLayer {
+		return nil, ErrLayerLocked
+	}
+
+	correct, err := grade(tx, material, layer, results)
+	if err != nil {
+		return nil, err
+	}
+	for i, r := range results {
+		record := models.ListeningSentenceProgress{
+			UserID:     userID,
+			MaterialID: material.ID,
+			Seq:        r.Seq,
+			Layer:      layer,
+			Correct:    correct[i],
+			Attempts:   1,
+			PracticeAt: now,
+		}
+		err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}, {Name: "material_id"}, {Name: "seq"}, {Name: "layer"}},
+			DoUpdates: clause.Assignments(map[string]any{
+				"correct":     record.Correct,
+				"attempts":    gorm.Expr("attempts + 1"),
+				"practice_at": now,
+				"updated_at":  now,
+				"deleted_at":  nil,
+			}),
+		}).Create(&record).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if progress, err = summarize(tx, userID, material, threshold); err != nil {
+		return nil, err
+	}
+	progress.CurrentLayer = layer
+	if len(results) > 0 {
+		progress.LastSeq = results[len(results)-1].Seq
+	}
+	progress.LastPracticeAt = now
+	if progress.CompletedAt == nil && progress.UnlockedLayer == models.ListeningLayer3 &&
+		progress.L3Completed >= progress.TotalSentences {
+		progress.CompletedAt = &now
+	}
+	if err := saveProgress(tx, progress); err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+// saveProgress 保存汇总进度
+// 首次练习时按用户和材料 upsert，并发提交同一材料的第一批结果不会因唯一索引冲突失败
+func saveProgress(tx *gorm.DB, progress *models.ListeningProgress) error {
+	if progress.ID != 0 {
+		return tx.Save(progress).Error
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "material_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"unlocked_layer", "current_layer", "total_sentences",
+			"l1_completed", "l2_completed", "l2_correct", "l3_completed", "l3_correct",
+			"last_seq", "last_practice_at", "completed_at", "updated_at", "deleted_at",
+		}),
+	}).Create(progress).Error
+}
+
+// grade 批改各句的作答，返回是否听对
+// L1 只需听完即算完成；L2/L3 与原句比较，忽略大小写、标点和弱读形式后全部一致才算听对
+func grade(tx *gorm.DB, material *models.ListeningMaterial, layer int, results []SentenceResult) ([]bool, error) {
+	correct := make([]bool, len(results))
+	if layer == models.ListeningLayer1 {
+		for i := range correct {
+			correct[i] = true
+		}
+		return correct, nil
+	}
+
+	seqs := make([]int, 0, len(results))
+	for _, r := range results {
+		seqs = append(seqs, r.Seq)
+	}
+	var sentences []models.ListeningSentence
+	if err := tx.Select("seq", "text").Where("material_id = ? AND seq IN ?", material.ID, seqs).Find(&sentences).Error; err != nil {
+		return nil, err
+	}
+	texts := make(map[int]string, len(sentences))
+	for _, s := range sentences {
+		texts[s.Seq] = s.Text
+	}
+	for i, r := range results {
+		text, ok := texts[r.Seq]
+		if !ok {
+			return nil, fmt.Errorf("%w: 句序 %d 不存在", ErrInvalidResult, r.Seq)
+		}
+		correct[i] = dictation.Grade(text, r.Text).Accuracy >= 1
+	}
+	return correct, nil
+}
+
+// summarize 加载进度记录并根据逐句结果重新统计各层完成情况
+// 尚未练习过的材料返回未保存的初始进度
+func summarize(tx *gorm.DB, userID uint, material *models.ListeningMaterial, threshold float64) (*models.ListeningProgress, error) {
+	progress := &models.ListeningProgress{
+		UserID:        userID,
+		MaterialID:    material.ID,
+		UnlockedLayer: models.ListeningLayer1,
+		CurrentLayer:  models.ListeningLayer1,
+	}
+	err := tx.Where("user_id = ? AND material_id = ?", userID, material.ID).First(progress).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	var rows []struct {
+		Layer     int
+		Completed int
+		Correct   int
+	}
+	err = tx.Model(&models.ListeningSentenceProgress{}).
+		Select("layer, COUNT(*) AS completed, SUM(CASE WHEN correct THEN 1 ELSE 0 END) AS correct").
+		Where("user_id = ? AND material_id = ? AND seq <= ?", userID, material.ID, material.SentenceCount).
+		Group("layer").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	progress.TotalSentences = material.SentenceCount
+	progress.L1Completed, progress.L2Completed, progress.L2Correct, progress.L3Completed, progress.L3Correct = 0, 0, 0, 0, 0
+	for _, r := range rows {
+		switch r.Layer {
+		case models.ListeningLayer1:
+			progress.L1Completed = r.Completed
+		case models.ListeningLayer2:
+			progress.L2Completed, progress.L2Correct = r.Completed, r.Correct
+		case models.ListeningLayer3:
+			progress.L3Completed, progress.L3Correct = r.Completed, r.Correct
+		}
+	}
+	progress.UnlockedLayer = UnlockedLayer(progress, threshold)
+	return progress, nil
+}
+
+// LoadState 获取材料的进度及续练位置
+func LoadState(db *gorm.DB, userID uint, material *models.ListeningMaterial, threshold float64) (*State, error) {
+	progress, err := summarize(db, userID, material, threshold)
+	if err != nil {
+		return nil, err
+	}
+	var records []models.ListeningSentenceProgress
+	err = db.Where("user_id = ? AND material_id = ? AND seq <= ?", userID, material.ID, material.SentenceCount).
+		Order("layer ASC, seq ASC").Find(&records).Error
+	if err != nil {
+		return nil, err
+	}
+
+	state := &State{
+		ListeningProgress: progress,
+		L2Accuracy:        accuracy(progress.L2Correct, progress.TotalSentences),
+		L3Accuracy:        accuracy(progress.L3Correct, progress.TotalSentences),
+		L3UnlockAccuracy:  threshold,
+		Sentences:         records,
+	}
+	state.ResumeLayer, state.ResumeSeq = resumePoint(progress, records)
+	return state, nil
+}
+
+// resumePoint 计算继续练习的位置
+// 从最近练习的层级开始，该层已全部完成且更高层已解锁时进入下一层；
+// 层内优先返回第一句未练习的句子，其次是第一句没听对的句子
+func resumePoint(p *models.ListeningProgress, records []models.ListeningSentenceProgress) (int, int) {
+	layer := min(max(p.CurrentLayer, models.ListeningLayer1), p.UnlockedLayer)
+	for {
+		seq := firstPending(p.TotalSentences, layer, records)
+		if seq > 0 || layer >= p.UnlockedLayer {
+			return layer, seq
+		}
+		layer++
+	}
+}
+
+// firstPending 返回层内第一句未练习的句子，其次是第一句没听对的句子，都没有时返回 0
+func firstPending(total, layer int, records []models.ListeningSentenceProgress) int {
+	done := make(map[int]bool, total)
+	wrong := 0
+	for _, r := range records {
+		if r.Layer != layer {
+			continue
+		}
+		done[r.Seq] = true
+		if !r.Correct && (wrong == 0 || r.Seq < wrong) {
+			wrong = r.Seq
+		}
+	}
+	for seq := 1; seq <= total; seq++ {
+		if !done[seq] {
+			return seq
+		}
+	}
+	return wrong
+}
@@ -0,0 +1,97 @@
+package listening
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"server/models"
+)
+
+// newProgressDB 创建内存数据库并写入一份两句的材料
+func newProgressDB(t *testing.T) (*gorm.DB, *models.ListeningMaterial) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.ListeningMaterial{}, &models.ListeningSentence{},
+		&models.ListeningProgress{}, &models.ListeningSentenceProgress{}); err != nil {
+		t.Fatal(err)
+	}
+	material := &models.ListeningMaterial{
+		Title: "Weekend", AudioURL: "/api/audio/1", Difficulty: "A2", SentenceCount: 2,
+		Sentences: []models.ListeningSentence{
+			{Seq: 1, Text: "I want to go home."},
+			{Seq: 2, Text: "It's going to rain later."},
+		},
+	}
+	if err := db.Create(material).Error; err != nil {
+		t.Fatal(err)
+	}
+	return db, material
+}
+
+func TestRecordGradesAnswers(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name         string
+		l2           []SentenceResult
+		wantCorrect  int
+		wantUnlocked int
+	}{
+		{"self reported", []SentenceResult{{Seq: 1}, {Seq: 2}}, 0, models.ListeningLayer2},
+		{"one wrong", []SentenceResult{{Seq: 1, Text: "I want to go home"}, {Seq: 2, Text: "It's going to train later"}}, 1, models.ListeningLayer2},
+		{"all correct", []SentenceResult{{Seq: 1, Text: "i wanna go home"}, {Seq: 2, Text: "It is gonna rain later!"}}, 2, models.ListeningLayer3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, material := newProgressDB(t)
+			if _, err := Record(db, 1, material, models.ListeningLayer1, []SentenceResult{{Seq: 1}, {Seq: 2}}, 0.8, now); err != nil {
+				t.Fatal(err)
+			}
+			p, err := Record(db, 1, material, models.ListeningLayer2, tt.l2, 0.8, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.L2Correct != tt.wantCorrect || p.UnlockedLayer != tt.wantUnlocked {
+				t.Errorf("L2Correct = %d, UnlockedLayer = %d, want %d, %d", p.L2Correct, p.UnlockedLayer, tt.wantCorrect, tt.wantUnlocked)
+			}
+		})
+	}
+}
+
+func TestRecordLockedAndInvalid(t *testing.T) {
+	db, material := newProgressDB(t)
+	now := time.Now()
+	if _, err := Record(db, 1, material, models.ListeningLayer3, []SentenceResult{{Seq: 1, Text: "I want to go home."}}, 0.8, now); !errors.Is(err, ErrLayerLocked) {
+		t.Errorf("L3 before unlock err = %v, want ErrLayerLocked", err)
+	}
+	if _, err := Record(db, 1, material, models.ListeningLayer1, []SentenceResult{{Seq: 3}}, 0.8, now); !errors.Is(err, ErrInvalidResult) {
+		t.Errorf("seq out of range err = %v, want ErrInvalidResult", err)
+	}
+}
+
+func TestUnlockedLayer(t *testing.T) {
+	tests := []struct {
+		name string
+		p    models.ListeningProgress
+		want int
+	}{
+		{"empty material", models.ListeningProgress{}, models.ListeningLayer1},
+		{"l1 in progress", models.ListeningProgress{TotalSentences: 10, L1Completed: 9}, models.ListeningLayer1},
+		{"l1 done", models.ListeningProgress{TotalSentences: 10, L1Completed: 10, L2Completed: 3}, models.ListeningLayer2},
+		{"l2 below threshold", models.ListeningProgress{TotalSentences: 10, L1Completed: 10, L2Completed: 10, L2Correct: 7}, models.ListeningLayer2},
+		{"l2 at threshold", models.ListeningProgress{TotalSentences: 10, L1Completed: 10, L2Completed: 10, L2Correct: 8}, models.ListeningLayer3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := UnlockedLayer(&tt.p, 0.8); got != tt.want {
+				t.Errorf("UnlockedLayer() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package listening
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"server/audio"
+	"server/models"
+)
+
+var (
+	// ErrStageLocked 前一阶段尚未通过
+	ErrStageLocked = errors.New("请先通过前一阶段")
+	// ErrUnknownStage 阶段不存在
+	ErrUnknownStage = errors.New("训练阶段不存在")
+)
+
+// SpeedStage 四阶训练法的一个阶段
+type SpeedStage struct {
+	Code  string  `json:"code"`
+	Name  string  `json:"name"`
+	Speed float64 `json:"speed"` // 播放速度
+	Goal  string  `json:"goal"`  // 练习目标
+}
+
+// SpeedStages 四阶训练法各阶段，按顺序逐个解锁
+var SpeedStages = []SpeedStage{
+	{models.SpeedStageSlowBreakdown, "慢速分解", 0.75, "放慢语速，逐句听清每个单词"},
+	{models.SpeedStageNormalKeywords, "常速关键词", 1.0, "恢复原速，抓住句中的关键词"},
+	{models.SpeedStageNormalFull, "常速全句", 1.0, "原速下听懂完整句子"},
+	{models.SpeedStageFastChallenge, "快速挑战", 1.25, "加快语速，巩固听力反应"},
+}
+
+// stageIndex 返回阶段下标，不存在时返回 -1
+func stageIndex(code string) int {
+	for i, s := range SpeedStages {
+		if s.Code == code {
+			return i
+		}
+	}
+	return -1
+}
+
+// StageState 阶段及用户的通过情况
+type StageState struct {
+	SpeedStage
+	AudioURL  string     `json:"audio_url"`  // 该速度的音频地址
+	Rendered  bool       `json:"rendered"`   // 是否由服务端变速；为 false 时客户端需自行调整播放速率
+	Unlocked  bool       `json:"unlocked"`   // 第一阶段始终解锁，之后需通过前一阶段
+	Passed    bool       `json:"passed"`     // 是否已通过
+	Attempts  int        `json:"attempts"`   // 练习次数
+	LastScore int        `json:"last_score"` // 最近一次得分
+	BestScore int        `json:"best_score"` // 最高得分
+	PassedAt  *time.Time `json:"passed_at"`  // 首次通过时间
+}
+
+// LoadStages 获取材料各阶段的状态
+func LoadStages(db *gorm.DB, userID uint, material *models.ListeningMaterial) ([]StageState, error) {
+	var rows []models.SpeedStageProgress
+	if err := db.Where("user_id = ? AND material_id = ?", userID, material.ID).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	byStage := make(map[string]models.SpeedStageProgress, len(rows))
+	for _, r := range rows {
+		byStage[r.Stage] = r
+	}
+
+	states := make([]StageState, 0, len(SpeedStages))
+	unlocked := true
+	for _, s := range SpeedStages {
+		p := byStage[s.Code]
+		state := StageState{
+			SpeedStage: s,
+			Unlocked:   unlocked,
+			Passed:     p.PassedAt != nil,
+			Attempts:   p.Attempts,
+			LastScore:  p.LastScore,
+			BestScore:  p.BestScore,
+			PassedAt:   p.PassedAt,
+		}
+		state.AudioURL, state.Rendered = audio.SpeedURL(material.AudioURL, s.Speed)
+		states = append(states, state)
+		unlocked = state.Passed
+	}
+	return states, nil
+}
+
+// RecordStage 记录一次阶段练习得分
+// 得分达到 passScore 即通过该阶段，通过后不会因之后的低分而失效；前一阶段未通过时返回 ErrStageLocked
+func RecordStage(tx *gorm.DB, userID uint, material *models.ListeningMaterial, code string, score, passScore int, now time.Time) error {
+	idx := stageIndex(code)
+	if idx < 0 {
+		return ErrUnknownStage
+	}
+	if idx > 0 {
+		var count int64
+		err := tx.Model(&models.SpeedStageProgress{}).
+			Where("user_id = ? AND material_id = ? AND stage = ? AND passed_at IS NOT NULL",
+				userID, material.ID, SpeedStages[idx-1].Code).
+			Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrStageLocked
+		}
+	}
+
+	// 按用户、材料和阶段 upsert，并发提交同一阶段的第一次成绩不会因唯一索引冲突失败
+	progress := models.SpeedStageProgress{
+		UserID:     userID,
+		MaterialID: material.ID,
+		Stage:      code,
+		Attempts:   1,
+		LastScore:  score,
+		BestScore:  score,
+		PracticeAt: now,
+	}
+	if score >= passScore {
+		progress.PassedAt = &now
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "material_id"}, {Name: "stage"}},
+		DoUpdates: clause.Assignments(map[string]any{
+			"attempts":    gorm.Expr("attempts + 1"),
+			"last_score":  score,
+			"best_score":  gorm.Expr("MAX(best_score, ?)", score),
+			"practice_at": now,
+			"passed_at":   gorm.Expr("COALESCE(passed_at, ?)", progress.PassedAt),
+			"updated_at":  now,
+			"deleted_at":  nil,
+		}),
+	}).Create(&progress).Error
+}
@@ -1,23 +1,39 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"server/audio"
+	"server/config"
 	"server/database"
+	"server/dialogue"
+	"server/importer"
+	"server/models"
+	"server/recording"
 	"server/router"
+	"server/speech"
+	"server/storage"
 	"server/utils"
+	"server/waveform"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
 var (
-	host string
-	port int
+	host       string
+	port       int
+	configPath string
 )
 
 func init() {
 	flag.StringVar(&host, "host", "0.0.0.0", "Server host")
 	flag.IntVar(&port, "port", 8080, "Server port")
+	flag.StringVar(&configPath, "config", "./config.json", "Config file path")
 	flag.Parse()
 }
 
@@ -30,10 +46,72 @@ func main() {
 	utils.InitLogger()
 	defer utils.CloseLogger()
 
+	// 加载配置
+	cfg, err := config.Load(configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	// 子命令：批量导入词书
+	if flag.Arg(0) == "import-wordbook" {
+		database.InitDB()
+		defer database.CloseDB()
+		if err := runImportWordbook(flag.Args()[1:]); err != nil {
+			utils.CloseLogger()
+			log.Fatalf("Import wordbook failed: %v", err)
+		}
+		return
+	}
+
+	// 子命令：设置用户角色
+	if flag.Arg(0) == "set-role" {
+		database.InitDB()
+		defer database.CloseDB()
+		if err := runSetRole(flag.Args()[1:]); err != nil {
+			utils.CloseLogger()
+			log.Fatalf("Set role failed: %v", err)
+		}
+		return
+	}
+
+	// 初始化JWT签名密钥
+	if err := utils.InitJWT(cfg.JWT); err != nil {
+		log.Fatalf("Failed to init JWT keys: %v", err)
+	}
+
+	// 初始化音频签名地址密钥
+	if err := audio.InitSigning(cfg.Audio.SigningKey); err != nil {
+		log.Fatalf("Failed to init audio signing key: %v", err)
+	}
+
+	// 检查发音评分引擎
+	scorer, err := speech.Get(cfg.Speaking.Scorer)
+	if err != nil {
+		log.Fatalf("Failed to init pronunciation scorer: %v", err)
+	}
+
+	// 初始化对话引擎
+	if err := dialogue.Init(cfg.Dialogue); err != nil {
+		log.Fatalf("Failed to init dialogue model: %v", err)
+	}
+
 	// 初始化数据库
 	database.InitDB()
 	defer database.CloseDB()
 
+	// 初始化文件存储
+	if err := storage.Init(cfg.Storage); err != nil {
+		log.Fatalf("Failed to init storage: %v", err)
+	}
+	if cfg.Recordings.RetentionDays > 0 {
+		recording.StartRetention(database.GetDB(), storage.Get(), time.Hour)
+	}
+	if cfg.Audio.CacheMaxSizeMB > 0 {
+		audio.StartCachePruner(cfg.Audio.CacheDir, int64(cfg.Audio.CacheMaxSizeMB)<<20, 10*time.Minute)
+	}
+	waveform.StartWorker(database.GetDB(), storage.Get(), cfg.Audio.FFmpegPath, time.Minute)
+	dialogue.StartScoring(database.GetDB(), storage.Get(), scorer, time.Minute)
+
 	// 设置路由
 	r := router.SetupRouter()
 
@@ -47,5 +125,104 @@ func main() {
 
 func printUsage() {
 	fmt.Fprintf(os.Stderr, "Usage: %s [options]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s [options] import-wordbook -file <path> [-name <wordbook>] [-commit]\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "       %s [options] set-role -user <username> -role <user|admin>\n", os.Args[0])
 	flag.PrintDefaults()
 }
+
+// runSetRole 执行 set-role 子命令
+// 管理员只能在服务器上授予，注册接口和用户接口都不能修改角色
+func runSetRole(args []string) error {
+	fs := flag.NewFlagSet("set-role", flag.ExitOnError)
+	username := fs.String("user", "", "Username")
+	role := fs.String("role", "", "Role: user or admin")
+	fs.Usage = func() {
+		printUsage()
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *username == "" || (*role != models.RoleUser && *role != models.RoleAdmin) {
+		fs.Usage()
+		return fmt.Errorf("-user and -role (user or admin) are required")
+	}
+
+	result := database.GetDB().Model(&models.User{}).Where("username = ?", *username).Update("role", *role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user %q not found", *username)
+	}
+	log.Printf("User %s is now %s", *username, *role)
+	return nil
+}
+
+// runImportWordbook 执行 import-wordbook 子命令
+// 默认只输出试运行报告，加 -commit 才写入数据库
+func runImportWordbook(args []string) error {
+	fs := flag.NewFlagSet("import-wordbook", flag.ExitOnError)
+	file := fs.String("file", "", "Wordbook file (.csv/.tsv/.json/.apkg)")
+	format := fs.String("format", "", "File format, detected from extension if empty")
+	name := fs.String("name", "", "Wordbook name, defaults to the name in the JSON file")
+	description := fs.String("description", "", "Wordbook description")
+	category := fs.String("category", "", "Wordbook category")
+	level := fs.String("level", "", "Wordbook level (A1-C2)")
+	commit := fs.Bool("commit", false, "Write to the database instead of a dry run")
+	overwrite := fs.Bool("overwrite", false, "Overwrite non-empty fields of existing words")
+	fs.Usage = func() {
+		printUsage()
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		fs.Usage()
+		return fmt.Errorf("-file is required")
+	}
+
+	if *format == "" {
+		f, err := importer.DetectFormat(*file)
+		if err != nil {
+			return err
+		}
+		*format = f
+	}
+
+	data, err := os.ReadFile(*file)
+	if err != nil {
+		return err
+	}
+	ds, err := importer.Parse(*format, data)
+	if err != nil {
+		return err
+	}
+
+	// 导入涉及大量SQL，只记录警告以免淹没报告
+	db := database.GetDB().Session(&gorm.Session{Logger: logger.Default.LogMode(logger.Warn)})
+	report, err := importer.Import(db, ds, importer.Options{
+		Wordbook: importer.WordbookMeta{
+			Name:        *name,
+			Description: *description,
+			Category:    *category,
+			Level:       *level,
+		},
+		DryRun:    !*commit,
+		Overwrite: *overwrite,
+	})
+	if err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	if report.DryRun {
+		log.Println("Dry run only, re-run with -commit to write to the database")
+	}
+	return nil
+}
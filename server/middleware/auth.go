@@ -2,9 +2,13 @@ package middleware
 
 import (
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"server/audio"
+	"server/database"
+	"server/models"
 	"server/utils"
 )
 
@@ -38,9 +42,70 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// 会话已登出或被吊销时，未过期的访问令牌同样失效
+		if claims.SessionID != "" {
+			var active int64
+			err := database.GetDB().Model(&models.RefreshToken{}).
+				Where("family_id = ? AND user_id = ? AND revoked_at IS NULL", claims.SessionID, claims.UserID).
+				Count(&active).Error
+			if err != nil || active == 0 {
+				utils.Warn("AuthMiddleware - Session revoked: UserID=%d, Session=%s", claims.UserID, claims.SessionID)
+				c.JSON(401, gin.H{"error": "Session revoked"})
+				c.Abort()
+				return
+			}
+		}
+
 		utils.Debug("AuthMiddleware - Authenticated: UserID=%d, Username=%s", claims.UserID, claims.Username)
 		c.Set("userID", claims.UserID)
 		c.Set("username", claims.Username)
+		c.Set("sessionID", claims.SessionID)
+		c.Next()
+	}
+}
+
+// AudioAuthMiddleware 托管音频访问鉴权
+// 带 sig 参数的请求按签名地址校验，无需 Authorization 头；否则按 AuthMiddleware 校验 Bearer Token
+func AudioAuthMiddleware() gin.HandlerFunc {
+	auth := AuthMiddleware()
+	return func(c *gin.Context) {
+		sig := c.Query("sig")
+		if sig == "" {
+			auth(c)
+			return
+		}
+
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(404, gin.H{"error": "音频不存在"})
+			c.Abort()
+			return
+		}
+		expires, err := audio.Verify(uint(id), c.Query("speed"), c.Query("v"), c.Query("exp"), sig, time.Now())
+		if err != nil {
+			utils.Warn("AudioAuthMiddleware - Invalid signed URL: AudioID=%d: %v", id, err)
+			c.JSON(403, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+
+		c.Set("signedURLExpires", expires)
+		c.Next()
+	}
+}
+
+// AdminMiddleware 管理员权限中间件
+// 需在 AuthMiddleware 之后使用，按数据库中的用户角色判断，不信任令牌中的用户名
+func AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, _ := c.Get("userID")
+		var user models.User
+		if err := database.GetDB().Select("id", "role").First(&user, userID).Error; err != nil || !user.IsAdmin() {
+			utils.Warn("AdminMiddleware - Forbidden: UserID=%v, Username=%s", userID, c.GetString("username"))
+			c.JSON(403, gin.H{"error": "需要管理员权限"})
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
@@ -51,7 +116,8 @@ func CORSMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, Range, If-None-Match, If-Range")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Range, Accept-Ranges, Content-Length, ETag, Last-Modified")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
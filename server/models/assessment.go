@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 测评类型
+const (
+	AssessmentKindVocab     = "vocab"     // 词汇测试
+	AssessmentKindListening = "listening" // 听力测试
+)
+
+// 测评状态
+const (
+	AssessmentStatusActive    = "active"    // 进行中
+	AssessmentStatusCompleted = "completed" // 已完成
+	AssessmentStatusAbandoned = "abandoned" // 被新的测评取代
+)
+
+// AssessmentSession 测评会话
+type AssessmentSession struct {
+	gorm.Model
+	UserID      uint       `gorm:"index;not null" json:"user_id"`        // 用户ID
+	Kind        string     `gorm:"size:20;index;not null" json:"kind"`   // 测评类型
+	Status      string     `gorm:"size:20;index;not null" json:"status"` // 测评状态
+	ItemCount   int        `json:"item_count"`                           // 总题数
+	Answered    int        `json:"answered"`                             // 已答题数
+	Theta       float64    `json:"theta"`                                // 当前能力估计
+	StdErr      float64    `json:"std_err"`                              // 能力估计标准误
+	CompletedAt *time.Time `json:"completed_at"`                         // 完成时间
+	Result      string     `gorm:"size:10" json:"result"`                // 测评结论，如CEFR等级
+	Score       int        `json:"score"`                                // 测评分数 (0-100)
+	VocabSize   int        `json:"vocab_size,omitempty"`                 // 估计词汇量（词汇测试）
+}
+
+// TableName 指定数据库表名
+func (AssessmentSession) TableName() string {
+	return "assessment_sessions"
+}
+
+// VocabTestItem 词汇测试题目
+type VocabTestItem struct {
+	gorm.Model
+	SessionID    uint       `gorm:"uniqueIndex:idx_session_seq;not null" json:"session_id"` // 测评会话ID
+	Seq          int        `gorm:"uniqueIndex:idx_session_seq;not null" json:"seq"`        // 题号，从1开始
+	WordID       uint       `gorm:"not null" json:"-"`                                      // 考查的单词
+	Word         string     `json:"word"`                                                   // 题面单词
+	Difficulty   float64    `json:"-"`                                                      // 题目难度
+	Options      []string   `gorm:"serializer:json" json:"options"`                         // 中文释义选项
+	CorrectIndex int        `json:"-"`                                                      // 正确选项下标
+	AnswerIndex  *int       `json:"answer_index"`                                           // 用户选择，-1 表示不认识或超时
+	Correct      *bool      `json:"correct"`                                                // 是否答对
+	ServedAt     time.Time  `json:"served_at"`                                              // 出题时间
+	AnsweredAt   *time.Time `json:"answered_at"`                                            // 作答时间
+}
+
+// TableName 指定数据库表名
+func (VocabTestItem) TableName() string {
+	return "vocab_test_items"
+}
@@ -0,0 +1,23 @@
+package models
+
+import "gorm.io/gorm"
+
+// AudioAsset 服务端托管的音频
+// 原始文件保存在对象存储中；变速版本按需生成并缓存在本地磁盘
+type AudioAsset struct {
+	gorm.Model
+	Title       string  `gorm:"size:200" json:"title"`                  // 名称，便于管理
+	StorageKey  string  `gorm:"size:255;uniqueIndex;not null" json:"-"` // 对象存储 key
+	ContentType string  `gorm:"size:50" json:"content_type"`            // 内容类型
+	ContentHash string  `gorm:"size:64" json:"content_hash"`            // 内容的 SHA-256，用于 ETag 和带版本号的地址
+	Size        int64   `json:"size"`                                   // 文件大小（字节）
+	Duration    float64 `json:"duration"`                               // 时长（秒）
+	SampleRate  int     `json:"sample_rate"`                            // 采样率
+	Channels    int     `json:"channels"`                               // 声道数
+	Restricted  bool    `json:"restricted"`                             // 只能通过接口下发的短期签名地址访问，如限制播放次数的测评题目
+}
+
+// TableName 指定数据库表名
+func (AudioAsset) TableName() string {
+	return "audio_assets"
+}
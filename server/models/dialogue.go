@@ -0,0 +1,150 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 对话角色
+const (
+	DialogueRoleAssistant = "assistant" // AI 对话伙伴
+	DialogueRoleUser      = "user"      // 学习者
+)
+
+// 对话状态
+const (
+	DialogueStatusActive    = "active"    // 进行中
+	DialogueStatusCompleted = "completed" // 已结束
+)
+
+// 救命按钮建议回答的难度
+const (
+	DialogueHintSimple   = "simple"   // 简单：最短能完成交流的说法
+	DialogueHintNatural  = "natural"  // 自然：母语者常用的说法
+	DialogueHintAdvanced = "advanced" // 进阶：更地道、更丰富的表达
+)
+
+// DialogueHintLevels 建议回答的难度，由易到难
+var DialogueHintLevels = []string{DialogueHintSimple, DialogueHintNatural, DialogueHintAdvanced}
+
+// IsValidDialogueHintLevel 检查建议回答的难度是否有效
+func IsValidDialogueHintLevel(level string) bool {
+	for _, v := range DialogueHintLevels {
+		if v == level {
+			return true
+		}
+	}
+	return false
+}
+
+// DialogueVariant 参考回答的其他难度说法
+type DialogueVariant struct {
+	Level       string `json:"level"`                 // simple / advanced
+	Text        string `json:"text"`                  // 说法
+	Translation string `json:"translation,omitempty"` // 中文翻译
+}
+
+// DialogueLine 场景剧本中的一句台词
+// 按对话顺序排列，AI 台词与学习者的参考回答交替出现；离线剧本引擎按此推进对话
+type DialogueLine struct {
+	Role        string            `json:"role"`                  // assistant / user
+	Text        string            `json:"text"`                  // 台词；user 行为参考回答
+	Translation string            `json:"translation,omitempty"` // 中文翻译
+	Keywords    []string          `json:"keywords,omitempty"`    // user 行：回答中出现任一关键词即视为回应了该句，为空时任何回答都可推进
+	Variants    []DialogueVariant `json:"variants,omitempty"`    // user 行：参考回答的简单/进阶说法，供救命按钮使用
+}
+
+// DialogueScenario 实战对话场景
+type DialogueScenario struct {
+	gorm.Model
+	Slug        string         `gorm:"size:50;uniqueIndex;not null" json:"slug"` // 场景标识，用于路由，如 coffee_order
+	Title       string         `gorm:"size:100;not null" json:"title"`           // 标题，如 咖啡店点单
+	Description string         `gorm:"type:text" json:"description"`             // 场景描述
+	Difficulty  string         `gorm:"size:10;index" json:"difficulty"`          // 难度等级 (A1-C2)
+	AIRole      string         `gorm:"size:100" json:"ai_role"`                  // AI 扮演的角色，如 barista
+	UserRole    string         `gorm:"size:100" json:"user_role"`                // 学习者扮演的角色，如 customer
+	Goal        string         `gorm:"size:500" json:"goal"`                     // 对话目标，如 点一杯大杯拿铁并付款
+	Prompt      string         `gorm:"type:text" json:"-"`                       // 给大模型的补充说明，不下发给客户端
+	Lines       []DialogueLine `gorm:"serializer:json" json:"lines,omitempty"`   // 剧本，仅详情返回
+}
+
+// TableName 指定数据库表名
+func (DialogueScenario) TableName() string {
+	return "dialogue_scenarios"
+}
+
+// DialogueSession 一次实战对话
+type DialogueSession struct {
+	gorm.Model
+	UserID      uint           `gorm:"index;not null" json:"user_id"`               // 用户ID
+	ScenarioID  uint           `gorm:"index;not null" json:"scenario_id"`           // 场景ID
+	Status      string         `gorm:"size:20;index;not null" json:"status"`        // 对话状态
+	Engine      string         `gorm:"size:50" json:"engine"`                       // 开始时使用的对话引擎
+	UserTurns   int            `json:"user_turns"`                                  // 学习者发言次数
+	HintsUsed   int            `json:"hints_used"`                                  // 使用救命按钮的轮数
+	CompletedAt *time.Time     `json:"completed_at"`                                // 结束时间
+	Turns       []DialogueTurn `gorm:"foreignKey:SessionID" json:"turns,omitempty"` // 对话记录，仅详情返回
+}
+
+// TableName 指定数据库表名
+func (DialogueSession) TableName() string {
+	return "dialogue_sessions"
+}
+
+// DialogueTurn 对话中的一轮发言
+type DialogueTurn struct {
+	gorm.Model
+	SessionID   uint   `gorm:"uniqueIndex:idx_dialogue_turn_seq;not null" json:"session_id"` // 对话ID
+	Seq         int    `gorm:"uniqueIndex:idx_dialogue_turn_seq;not null" json:"seq"`        // 发言顺序，从1开始
+	Role        string `gorm:"size:20;not null" json:"role"`                                 // assistant / user
+	Text        string `gorm:"type:text;not null" json:"text"`                               // 发言内容
+	Translation string `gorm:"type:text" json:"translation"`                                 // 中文翻译（剧本台词提供）
+	RecordingID *uint  `json:"recording_id"`                                                 // 学习者的语音录音（可选）
+	Engine      string `gorm:"size:50" json:"engine,omitempty"`                              // 生成该回复的对话引擎
+
+	// 语音发言的评分，生成复盘报告时计算一次并保存，录音过期后仍可查看
+	Pronunciation float64    `json:"pronunciation,omitempty"`               // 发音准确度
+	Fluency       float64    `json:"fluency,omitempty"`                     // 流利度
+	Intonation    float64    `json:"intonation,omitempty"`                  // 语调自然度
+	Overall       float64    `json:"overall,omitempty"`                     // 综合评分
+	Feedback      []string   `gorm:"serializer:json" json:"-"`              // 评分引擎的改进建议
+	ScoreEngine   string     `gorm:"size:50" json:"score_engine,omitempty"` // 评分引擎
+	ScoreError    string     `gorm:"size:255" json:"-"`                     // 无法评分的原因
+	ScoredAt      *time.Time `json:"scored_at,omitempty"`                   // 评分时间
+}
+
+// Scored 是否已成功评分
+func (t *DialogueTurn) Scored() bool {
+	return t.ScoredAt != nil && t.ScoreError == ""
+}
+
+// TableName 指定数据库表名
+func (DialogueTurn) TableName() string {
+	return "dialogue_turns"
+}
+
+// DialogueSuggestion 救命按钮给出的一条建议回答
+type DialogueSuggestion struct {
+	Level       string   `json:"level"`                 // simple / natural / advanced
+	Text        string   `json:"text"`                  // 建议回答
+	Translation string   `json:"translation"`           // 中文翻译
+	KeyPhrases  []string `json:"key_phrases,omitempty"` // 关键短语
+}
+
+// DialogueHint 学习者在某一轮使用救命按钮的记录
+// 同一轮重复求助返回同一组建议，只计一次
+type DialogueHint struct {
+	gorm.Model
+	SessionID   uint                 `gorm:"uniqueIndex:idx_dialogue_hint_seq;not null" json:"session_id"` // 对话ID
+	Seq         int                  `gorm:"uniqueIndex:idx_dialogue_hint_seq;not null" json:"seq"`        // 求助时学习者即将发言的轮次
+	UserID      uint                 `gorm:"index;not null" json:"user_id"`                                // 用户ID
+	Recommended string               `gorm:"size:20" json:"recommended"`                                   // 按词汇等级推荐的难度
+	Engine      string               `gorm:"size:50" json:"engine"`                                        // 生成建议的对话引擎
+	Suggestions []DialogueSuggestion `gorm:"serializer:json" json:"suggestions"`                           // 建议回答，由易到难
+}
+
+// TableName 指定数据库表名
+func (DialogueHint) TableName() string {
+	return "dialogue_hints"
+}
@@ -0,0 +1,146 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SpeechPhenomenon 语流音变现象
+// 连读、弱读、吞音三类训练各自包含若干细分现象，练习结果汇总到对应的听力分项水平
+type SpeechPhenomenon struct {
+	Code        string `json:"code"`
+	Category    string `json:"category"`    // 所属听力分项: liaison / weak_form / elision
+	Name        string `json:"name"`        // 中文名称
+	Description string `json:"description"` // 说明及示例
+}
+
+// 语流音变现象代码
+const (
+	SpeechCVLinking      = "cv_linking"      // 辅元连读
+	SpeechVVLinking      = "vv_linking"      // 元元连读
+	SpeechTFlapping      = "t_flapping"      // /t/ 闪音
+	SpeechAssimilation   = "assimilation"    // 同化
+	SpeechWeakForm       = "weak_form"       // 功能词弱读
+	SpeechReduction      = "reduction"       // 口语缩略
+	SpeechElision        = "elision"         // 辅音省略
+	SpeechUnreleasedStop = "unreleased_stop" // 不完全爆破
+)
+
+// SpeechPhenomena 全部语流音变现象
+var SpeechPhenomena = []SpeechPhenomenon{
+	{SpeechCVLinking, PhenomenonLiaison, "辅元连读", "词尾辅音与下一个词的词首元音连读，如 pick it up → pi-ki-tup"},
+	{SpeechVVLinking, PhenomenonLiaison, "元元连读", "两个元音之间插入 /j/ 或 /w/ 过渡，如 go on → go-won"},
+	{SpeechTFlapping, PhenomenonLiaison, "/t/ 闪音", "美音中元音之间的 /t/ 变为闪音，如 water、get it"},
+	{SpeechAssimilation, PhenomenonLiaison, "同化", "相邻音互相影响而变音，如 did you → didju、want you → wanchu"},
+	{SpeechWeakForm, PhenomenonWeakForm, "功能词弱读", "冠词、介词、助动词等读成弱读式，如 for → /fə/、can → /kən/"},
+	{SpeechReduction, PhenomenonWeakForm, "口语缩略", "常用词组在口语中缩略，如 want to → wanna、going to → gonna"},
+	{SpeechElision, PhenomenonElision, "辅音省略", "辅音丛中的 /t/ /d/ 等被省略，如 next day → nex day、last night"},
+	{SpeechUnreleasedStop, PhenomenonElision, "不完全爆破", "爆破音后接辅音时只做口型不爆破，如 good job、that day"},
+}
+
+// FindSpeechPhenomenon 按代码查找语流音变现象
+func FindSpeechPhenomenon(code string) (SpeechPhenomenon, bool) {
+	for _, p := range SpeechPhenomena {
+		if p.Code == code {
+			return p, true
+		}
+	}
+	return SpeechPhenomenon{}, false
+}
+
+// DrillCategories 可单独训练的听力分项，对应连读破解器、弱读放大镜、吞音探测器
+var DrillCategories = []string{PhenomenonLiaison, PhenomenonWeakForm, PhenomenonElision}
+
+// IsValidDrillCategory 检查训练分项是否有效
+func IsValidDrillCategory(c string) bool {
+	for _, v := range DrillCategories {
+		if v == c {
+			return true
+		}
+	}
+	return false
+}
+
+// DrillItem 语流音变训练题
+// 题目截取听力材料中某句的一段音频，让用户辨认听到的内容
+type DrillItem struct {
+	gorm.Model
+	Phenomenon   string   `gorm:"size:30;index;not null" json:"phenomenon"` // 语流音变现象代码
+	Category     string   `gorm:"size:20;index;not null" json:"category"`   // 所属听力分项
+	SentenceID   uint     `gorm:"index;not null" json:"sentence_id"`        // 所属句子
+	StartTime    float64  `json:"start_time"`                               // 音频片段开始时间（秒，相对材料音频）
+	EndTime      float64  `json:"end_time"`                                 // 音频片段结束时间（秒）
+	Text         string   `gorm:"size:200" json:"text"`                     // 片段原文，如 want to
+	Focus        string   `gorm:"size:100" json:"focus"`                    // 考查点，如 want to → wanna
+	Options      []string `gorm:"serializer:json" json:"options"`           // 选项
+	CorrectIndex int      `json:"correct_index"`                            // 正确选项下标
+	Level        string   `gorm:"size:10" json:"level"`                     // 难度等级
+}
+
+// TableName 指定数据库表名
+func (DrillItem) TableName() string {
+	return "drill_items"
+}
+
+// 训练状态
+const (
+	DrillStatusActive    = "active"    // 进行中
+	DrillStatusCompleted = "completed" // 已完成
+	DrillStatusAbandoned = "abandoned" // 被新的训练取代
+)
+
+// DrillSession 语流音变训练会话
+type DrillSession struct {
+	gorm.Model
+	UserID      uint       `gorm:"index;not null" json:"user_id"`        // 用户ID
+	Category    string     `gorm:"size:20" json:"category"`              // 训练分项，为空表示综合训练
+	Status      string     `gorm:"size:20;index;not null" json:"status"` // 训练状态
+	ItemCount   int        `json:"item_count"`                           // 总题数
+	Answered    int        `json:"answered"`                             // 已答题数
+	Correct     int        `json:"correct"`                              // 答对题数
+	CompletedAt *time.Time `json:"completed_at"`                         // 完成时间
+}
+
+// TableName 指定数据库表名
+func (DrillSession) TableName() string {
+	return "drill_sessions"
+}
+
+// DrillSessionItem 训练会话中的一道题
+type DrillSessionItem struct {
+	gorm.Model
+	SessionID    uint       `gorm:"uniqueIndex:idx_drill_session_seq;not null" json:"session_id"` // 训练会话ID
+	Seq          int        `gorm:"uniqueIndex:idx_drill_session_seq;not null" json:"seq"`        // 题号，从1开始
+	ItemID       uint       `gorm:"index;not null" json:"-"`                                      // 题库题目ID
+	Phenomenon   string     `gorm:"size:30" json:"phenomenon"`                                    // 语流音变现象代码
+	Category     string     `gorm:"size:20" json:"category"`                                      // 所属听力分项
+	AudioURL     string     `gorm:"size:500" json:"audio_url"`                                    // 材料音频地址
+	StartTime    float64    `json:"start_time"`                                                   // 片段开始时间（秒）
+	EndTime      float64    `json:"end_time"`                                                     // 片段结束时间（秒）
+	Options      []string   `gorm:"serializer:json" json:"options"`                               // 选项
+	CorrectIndex int        `json:"-"`                                                            // 正确选项下标
+	AnswerIndex  *int       `json:"answer_index"`                                                 // 用户选择，-1 表示没听清
+	Correct      *bool      `json:"correct"`                                                      // 是否答对
+	AnsweredAt   *time.Time `json:"answered_at"`                                                  // 作答时间
+}
+
+// TableName 指定数据库表名
+func (DrillSessionItem) TableName() string {
+	return "drill_session_items"
+}
+
+// UserPhenomenonStat 用户在各语流音变现象上的练习统计，用于按错误率安排训练
+type UserPhenomenonStat struct {
+	gorm.Model
+	UserID         uint      `gorm:"uniqueIndex:idx_user_phenomenon;not null" json:"user_id"`            // 用户ID
+	Phenomenon     string    `gorm:"uniqueIndex:idx_user_phenomenon;size:30;not null" json:"phenomenon"` // 语流音变现象代码
+	Attempts       int       `json:"attempts"`                                                           // 作答次数
+	Errors         int       `json:"errors"`                                                             // 答错次数
+	LastPracticeAt time.Time `json:"last_practice_at"`                                                   // 最近练习时间
+}
+
+// TableName 指定数据库表名
+func (UserPhenomenonStat) TableName() string {
+	return "user_phenomenon_stats"
+}
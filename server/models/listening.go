@@ -0,0 +1,93 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 听力现象
+const (
+	PhenomenonSlow     = "slow"      // 慢速
+	PhenomenonNormal   = "normal"    // 常速
+	PhenomenonLiaison  = "liaison"   // 连读
+	PhenomenonWeakForm = "weak_form" // 弱读
+	PhenomenonElision  = "elision"   // 吞音
+)
+
+// ListeningPhenomena 全部听力现象，按测试出题顺序排列
+var ListeningPhenomena = []string{
+	PhenomenonSlow,
+	PhenomenonNormal,
+	PhenomenonLiaison,
+	PhenomenonWeakForm,
+	PhenomenonElision,
+}
+
+// IsValidPhenomenon 检查听力现象是否有效
+func IsValidPhenomenon(p string) bool {
+	for _, v := range ListeningPhenomena {
+		if v == p {
+			return true
+		}
+	}
+	return false
+}
+
+// ListeningItem 听力测试题库
+type ListeningItem struct {
+	gorm.Model
+	Phenomenon   string   `gorm:"size:20;index;not null" json:"phenomenon"` // 考查的听力现象
+	AudioURL     string   `gorm:"size:500;not null" json:"-"`               // 托管音频地址，每次播放时下发短期签名地址
+	Transcript   string   `gorm:"type:text" json:"transcript"`              // 原文
+	Focus        string   `gorm:"size:100" json:"focus"`                    // 考查点，如 want to → wanna
+	Options      []string `gorm:"serializer:json" json:"options"`           // 选项
+	CorrectIndex int      `json:"correct_index"`                            // 正确选项下标
+	Level        string   `gorm:"size:10" json:"level"`                     // 难度等级
+}
+
+// TableName 指定数据库表名
+func (ListeningItem) TableName() string {
+	return "listening_items"
+}
+
+// ListeningTestItem 听力测试中的一道题
+type ListeningTestItem struct {
+	gorm.Model
+	SessionID    uint       `gorm:"uniqueIndex:idx_listening_session_seq;not null" json:"session_id"` // 测评会话ID
+	Seq          int        `gorm:"uniqueIndex:idx_listening_session_seq;not null" json:"seq"`        // 题号，从1开始
+	ItemID       uint       `gorm:"not null" json:"-"`                                                // 题库题目ID
+	Phenomenon   string     `gorm:"size:20" json:"phenomenon"`                                        // 考查的听力现象
+	Options      []string   `gorm:"serializer:json" json:"options"`                                   // 选项
+	CorrectIndex int        `json:"-"`                                                                // 正确选项下标
+	Plays        int        `json:"plays"`                                                            // 已播放次数
+	AnswerIndex  *int       `json:"answer_index"`                                                     // 用户选择，-1 表示没听清
+	Correct      *bool      `json:"correct"`                                                          // 是否答对
+	AnsweredAt   *time.Time `json:"answered_at"`                                                      // 作答时间
+}
+
+// TableName 指定数据库表名
+func (ListeningTestItem) TableName() string {
+	return "listening_test_items"
+}
+
+// ListeningProfile 用户听力分项水平
+// 每次完成听力测试后覆盖更新，对应听力首页展示的各项百分比
+type ListeningProfile struct {
+	gorm.Model
+	UserID        uint      `gorm:"uniqueIndex;not null" json:"user_id"` // 用户ID
+	SessionID     uint      `json:"session_id"`                          // 来源测评会话
+	SlowScore     int       `json:"slow_score"`                          // 慢速 (0-100)
+	NormalScore   int       `json:"normal_score"`                        // 常速 (0-100)
+	LiaisonScore  int       `json:"liaison_score"`                       // 连读识别 (0-100)
+	WeakFormScore int       `json:"weak_form_score"`                     // 弱读识别 (0-100)
+	ElisionScore  int       `json:"elision_score"`                       // 吞音识别 (0-100)
+	OverallScore  int       `json:"overall_score"`                       // 综合听力分数 (0-100)
+	Level         string    `gorm:"size:10" json:"level"`                // 听力等级
+	AssessedAt    time.Time `json:"assessed_at"`                         // 测评时间
+}
+
+// TableName 指定数据库表名
+func (ListeningProfile) TableName() string {
+	return "listening_profiles"
+}
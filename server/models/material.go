@@ -0,0 +1,157 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CEFRLevels 难度等级，由易到难
+var CEFRLevels = []string{"A1", "A2", "B1", "B2", "C1", "C2"}
+
+// IsValidCEFRLevel 检查难度等级是否有效
+func IsValidCEFRLevel(level string) bool {
+	for _, v := range CEFRLevels {
+		if v == level {
+			return true
+		}
+	}
+	return false
+}
+
+// 材料类型
+const (
+	MaterialKindListening = "listening" // 听力训练
+	MaterialKindShadowing = "shadowing" // 跟读训练
+)
+
+// IsValidMaterialKind 检查材料类型是否有效
+func IsValidMaterialKind(kind string) bool {
+	return kind == MaterialKindListening || kind == MaterialKindShadowing
+}
+
+// ListeningMaterial 听力训练材料
+type ListeningMaterial struct {
+	gorm.Model
+	Kind          string              `gorm:"size:20;index;not null;default:listening" json:"kind"` // listening / shadowing
+	Title         string              `gorm:"size:200;not null" json:"title"`                       // 标题
+	Subtitle      string              `gorm:"size:200" json:"subtitle"`                             // 副标题
+	AudioURL      string              `gorm:"size:500;not null" json:"audio_url"`                   // 音频地址
+	VideoURL      string              `gorm:"size:500" json:"video_url,omitempty"`                  // 视频地址（可选）
+	ImageURL      string              `gorm:"size:500" json:"image_url,omitempty"`                  // 封面图（可选）
+	Difficulty    string              `gorm:"size:10;index" json:"difficulty"`                      // 难度等级 (A1-C2)
+	Duration      float64             `json:"duration"`                                             // 时长（秒）
+	SentenceCount int                 `json:"sentence_count"`                                       // 句子数
+	Tags          []string            `gorm:"-" json:"tags"`                                        // 标签，存储在 listening_material_tags
+	Sentences     []ListeningSentence `gorm:"foreignKey:MaterialID" json:"sentences,omitempty"`     // 句子列表，仅详情返回
+}
+
+// TableName 指定数据库表名
+func (ListeningMaterial) TableName() string {
+	return "listening_materials"
+}
+
+// ListeningSentence 听力材料中的一个句子
+type ListeningSentence struct {
+	gorm.Model
+	MaterialID    uint     `gorm:"uniqueIndex:idx_material_sentence_seq;not null" json:"material_id"` // 所属材料
+	Seq           int      `gorm:"uniqueIndex:idx_material_sentence_seq;not null" json:"seq"`         // 句序，从1开始
+	Text          string   `gorm:"type:text;not null" json:"text"`                                    // 英文原文
+	Translation   string   `gorm:"type:text" json:"translation"`                                      // 中文翻译
+	StartTime     float64  `json:"start_time"`                                                        // 开始时间（秒）
+	EndTime       float64  `json:"end_time"`                                                          // 结束时间（秒）
+	Keywords      []string `gorm:"serializer:json" json:"keywords"`                                   // 关键词
+	Pronunciation string   `gorm:"size:500" json:"pronunciation,omitempty"`                           // 发音提示（可选）
+}
+
+// TableName 指定数据库表名
+func (ListeningSentence) TableName() string {
+	return "listening_sentences"
+}
+
+// ListeningMaterialTag 听力材料标签
+type ListeningMaterialTag struct {
+	MaterialID uint   `gorm:"primaryKey"`
+	Tag        string `gorm:"primaryKey;size:50;index"`
+}
+
+// TableName 指定数据库表名
+func (ListeningMaterialTag) TableName() string {
+	return "listening_material_tags"
+}
+
+// 三层剥离训练层级
+const (
+	ListeningLayer1 = 1 // L1 字幕全开
+	ListeningLayer2 = 2 // L2 关键词保留
+	ListeningLayer3 = 3 // L3 无字幕挑战
+)
+
+// ListeningProgress 用户在某个材料上的三层剥离进度
+// 由 listening_sentence_progress 汇总得出，用于听力首页展示和断点续练
+type ListeningProgress struct {
+	gorm.Model
+	UserID         uint               `gorm:"uniqueIndex:idx_listening_progress_user_material;not null" json:"user_id"`           // 用户ID
+	MaterialID     uint               `gorm:"uniqueIndex:idx_listening_progress_user_material;index;not null" json:"material_id"` // 材料ID
+	UnlockedLayer  int                `gorm:"not null;default:1" json:"unlocked_layer"`                                           // 已解锁的最高层级
+	CurrentLayer   int                `gorm:"not null;default:1" json:"current_layer"`                                            // 最近练习的层级
+	TotalSentences int                `json:"total_sentences"`                                                                    // 材料句子数
+	L1Completed    int                `json:"l1_completed"`                                                                       // L1 已完成句数
+	L2Completed    int                `json:"l2_completed"`                                                                       // L2 已完成句数
+	L2Correct      int                `json:"l2_correct"`                                                                         // L2 答对句数
+	L3Completed    int                `json:"l3_completed"`                                                                       // L3 已完成句数
+	L3Correct      int                `json:"l3_correct"`                                                                         // L3 答对句数
+	LastSeq        int                `json:"last_seq"`                                                                           // 最近练习的句序
+	LastPracticeAt time.Time          `gorm:"index" json:"last_practice_at"`                                                      // 最近练习时间
+	CompletedAt    *time.Time         `json:"completed_at"`                                                                       // 三层全部完成的时间
+	Material       *ListeningMaterial `gorm:"foreignKey:MaterialID" json:"material,omitempty"`                                    // 材料摘要，仅列表返回
+}
+
+// TableName 指定数据库表名
+func (ListeningProgress) TableName() string {
+	return "listening_progress"
+}
+
+// ListeningSentenceProgress 用户在某一层级对某个句子的练习结果
+// 按句序记录，材料重新导入句子后已有进度仍然有效
+type ListeningSentenceProgress struct {
+	gorm.Model
+	UserID     uint      `gorm:"uniqueIndex:idx_sentence_progress;not null" json:"-"`     // 用户ID
+	MaterialID uint      `gorm:"uniqueIndex:idx_sentence_progress;not null" json:"-"`     // 材料ID
+	Seq        int       `gorm:"uniqueIndex:idx_sentence_progress;not null" json:"seq"`   // 句序
+	Layer      int       `gorm:"uniqueIndex:idx_sentence_progress;not null" json:"layer"` // 层级 (1-3)
+	Correct    bool      `json:"correct"`                                                 // 最近一次是否答对，L1 恒为 true
+	Attempts   int       `json:"attempts"`                                                // 练习次数
+	PracticeAt time.Time `json:"practice_at"`                                             // 最近练习时间
+}
+
+// TableName 指定数据库表名
+func (ListeningSentenceProgress) TableName() string {
+	return "listening_sentence_progress"
+}
+
+// 四阶训练法阶段
+const (
+	SpeedStageSlowBreakdown  = "slow_breakdown"  // 慢速分解 0.75x
+	SpeedStageNormalKeywords = "normal_keywords" // 常速关键词 1.0x
+	SpeedStageNormalFull     = "normal_full"     // 常速全句 1.0x
+	SpeedStageFastChallenge  = "fast_challenge"  // 快速挑战 1.25x
+)
+
+// SpeedStageProgress 用户在某个材料上各阶段的通过情况
+type SpeedStageProgress struct {
+	gorm.Model
+	UserID     uint       `gorm:"uniqueIndex:idx_speed_stage;not null" json:"-"`             // 用户ID
+	MaterialID uint       `gorm:"uniqueIndex:idx_speed_stage;not null" json:"material_id"`   // 材料ID
+	Stage      string     `gorm:"uniqueIndex:idx_speed_stage;size:30;not null" json:"stage"` // 阶段代码
+	Attempts   int        `json:"attempts"`                                                  // 练习次数
+	LastScore  int        `json:"last_score"`                                                // 最近一次得分 (0-100)
+	BestScore  int        `json:"best_score"`                                                // 最高得分 (0-100)
+	PracticeAt time.Time  `json:"practice_at"`                                               // 最近练习时间
+	PassedAt   *time.Time `json:"passed_at"`                                                 // 首次通过时间，为空表示未通过
+}
+
+// TableName 指定数据库表名
+func (SpeedStageProgress) TableName() string {
+	return "speed_stage_progress"
+}
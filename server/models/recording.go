@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 录音用途
+const (
+	RecordingPurposePractice     = "practice"      // 自由练习
+	RecordingPurposeShadowing    = "shadowing"     // 影子跟读
+	RecordingPurposeSpeakingTest = "speaking_test" // 口语测试
+	RecordingPurposeDialogue     = "dialogue"      // AI对话
+)
+
+// IsValidRecordingPurpose 检查录音用途是否有效
+func IsValidRecordingPurpose(p string) bool {
+	switch p {
+	case RecordingPurposePractice, RecordingPurposeShadowing, RecordingPurposeSpeakingTest, RecordingPurposeDialogue:
+		return true
+	}
+	return false
+}
+
+// Recording 用户录音
+// 音频内容保存在对象存储中，数据库只记录元数据
+type Recording struct {
+	gorm.Model
+	UserID      uint       `gorm:"index;not null" json:"user_id"`          // 用户ID
+	Purpose     string     `gorm:"size:20;index;not null" json:"purpose"`  // 录音用途
+	RefID       string     `gorm:"size:100" json:"ref_id"`                 // 关联对象ID，如跟读句子、对话轮次
+	StorageKey  string     `gorm:"size:255;uniqueIndex;not null" json:"-"` // 对象存储 key
+	ContentType string     `gorm:"size:50" json:"content_type"`            // 内容类型（服务端识别）
+	Size        int64      `json:"size"`                                   // 文件大小（字节）
+	Duration    float64    `json:"duration"`                               // 时长（秒）
+	ExpiresAt   *time.Time `gorm:"index" json:"expires_at"`                // 过期时间，为空表示永久保留
+}
+
+// TableName 指定数据库表名
+func (Recording) TableName() string {
+	return "recordings"
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ShadowingMaterial 影子跟读材料
+// 音频和逐句时间轴沿用 kind=shadowing 的听力材料（可由字幕导入生成），这里记录跟读专用的信息；
+// 创建跟读类型的听力材料时自动登记
+type ShadowingMaterial struct {
+	gorm.Model
+	ListeningMaterialID uint               `gorm:"uniqueIndex;not null" json:"listening_material_id"`        // 音频和句子所在的听力材料
+	Source              string             `gorm:"size:200" json:"source"`                                   // 来源，如影视剧名
+	PassScore           int                `json:"pass_score"`                                               // 单句达标分数，为0时使用配置 speaking.shadowing_pass_score
+	Material            *ListeningMaterial `gorm:"foreignKey:ListeningMaterialID" json:"material,omitempty"` // 听力材料摘要，不含句子
+}
+
+// TableName 指定数据库表名
+func (ShadowingMaterial) TableName() string {
+	return "shadowing_materials"
+}
+
+// ShadowingAttempt 影子跟读的一次录音评分
+// 每次提交都保留，按句序关联材料，材料重新导入句子后历史记录仍然有效
+type ShadowingAttempt struct {
+	gorm.Model
+	UserID        uint      `gorm:"index:idx_shadowing_attempt_user_material;not null" json:"user_id"`     // 用户ID
+	MaterialID    uint      `gorm:"index:idx_shadowing_attempt_user_material;not null" json:"material_id"` // 跟读材料ID
+	Seq           int       `gorm:"not null" json:"seq"`                                                   // 句序
+	SentenceID    uint      `json:"sentence_id"`                                                           // 提交时的句子ID
+	RecordingID   uint      `json:"recording_id"`                                                          // 录音ID，录音过期清理后不可再播放
+	Pronunciation float64   `json:"pronunciation"`                                                         // 发音准确度
+	Fluency       float64   `json:"fluency"`                                                               // 流利度
+	Intonation    float64   `json:"intonation"`                                                            // 语调自然度
+	Overall       float64   `json:"overall"`                                                               // 综合评分 (0-100)
+	Feedback      []string  `gorm:"serializer:json" json:"feedback"`                                       // 改进建议
+	Engine        string    `gorm:"size:50" json:"engine"`                                                 // 评分引擎
+	PracticeAt    time.Time `json:"practice_at"`                                                           // 练习时间
+}
+
+// TableName 指定数据库表名
+func (ShadowingAttempt) TableName() string {
+	return "shadowing_attempts"
+}
+
+// ShadowingProgress 用户在某个跟读材料上的练习汇总
+// 由 shadowing_attempts 汇总得出，用于口语首页展示和口语分数计算
+type ShadowingProgress struct {
+	gorm.Model
+	UserID             uint               `gorm:"uniqueIndex:idx_shadowing_progress_user_material;not null" json:"user_id"`           // 用户ID
+	MaterialID         uint               `gorm:"uniqueIndex:idx_shadowing_progress_user_material;index;not null" json:"material_id"` // 跟读材料ID
+	TotalSentences     int                `json:"total_sentences"`                                                                    // 材料句子数
+	PracticedSentences int                `json:"practiced_sentences"`                                                                // 练习过的句数
+	Attempts           int                `json:"attempts"`                                                                           // 累计录音次数
+	BestScore          int                `json:"best_score"`                                                                         // 各句最高分的平均值
+	LatestScore        int                `json:"latest_score"`                                                                       // 各句最近一次得分的平均值
+	LastSeq            int                `json:"last_seq"`                                                                           // 最近练习的句序
+	LastPracticeAt     time.Time          `gorm:"index" json:"last_practice_at"`                                                      // 最近练习时间
+	CompletedAt        *time.Time         `json:"completed_at"`                                                                       // 全部句子都练习过的时间
+	Material           *ShadowingMaterial `gorm:"foreignKey:MaterialID" json:"material,omitempty"`                                    // 材料摘要，仅列表返回
+}
+
+// TableName 指定数据库表名
+func (ShadowingProgress) TableName() string {
+	return "shadowing_progress"
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+// 学习事件类型
+const (
+	StudyEventWordLearned       = "word_learned"       // 学会单词，Amount 为单词数
+	StudyEventListeningMinutes  = "listening_minutes"  // 听力练习，Amount 为分钟数
+	StudyEventSpeakingMinutes   = "speaking_minutes"   // 口语练习，Amount 为分钟数
+	StudyEventExerciseCompleted = "exercise_completed" // 完成练习，Amount 为练习数
+)
+
+// StudyEvent 学习事件
+// 只追加不修改，UserStats 由事件汇总得出；同一用户的 EventID 唯一，保证重复提交幂等
+type StudyEvent struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	UserID     uint      `gorm:"uniqueIndex:idx_user_event;index:idx_user_day,priority:1;not null" json:"user_id"` // 用户ID
+	EventID    string    `gorm:"uniqueIndex:idx_user_event;size:64;not null" json:"event_id"`                      // 客户端生成的事件ID
+	Type       string    `gorm:"size:32;index;not null" json:"type"`                                               // 事件类型
+	Amount     int       `gorm:"not null" json:"amount"`                                                           // 数量（单词数/分钟数/练习数）
+	RefID      string    `gorm:"size:64" json:"ref_id,omitempty"`                                                  // 关联对象，如单词ID、材料ID
+	OccurredAt time.Time `gorm:"not null" json:"occurred_at"`                                                      // 事件发生时间
+	Day        string    `gorm:"size:10;index:idx_user_day,priority:2;not null" json:"day"`                        // 计入的学习日 (YYYY-MM-DD)
+	CreatedAt  time.Time `json:"created_at"`                                                                       // 服务端接收时间
+}
+
+// TableName 指定数据库表名
+func (StudyEvent) TableName() string {
+	return "study_events"
+}
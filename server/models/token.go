@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshToken 刷新令牌模型
+// 数据库只保存令牌的SHA-256摘要，同一次登录轮换出的令牌共享 FamilyID
+type RefreshToken struct {
+	gorm.Model
+	UserID    uint       `gorm:"index;not null" json:"user_id"`   // 所属用户ID
+	TokenHash string     `gorm:"uniqueIndex;not null" json:"-"`   // 令牌摘要，不返回给前端
+	FamilyID  string     `gorm:"index;not null" json:"family_id"` // 令牌族ID，即一次登录会话
+	ExpiresAt time.Time  `gorm:"not null" json:"expires_at"`      // 过期时间
+	UsedAt    *time.Time `json:"used_at"`                         // 被轮换使用的时间，非空表示已失效
+	RevokedAt *time.Time `gorm:"index" json:"revoked_at"`         // 被吊销的时间（登出或检测到重用）
+	UserAgent string     `gorm:"size:255" json:"user_agent"`      // 签发时的客户端标识
+	ClientIP  string     `gorm:"size:64" json:"client_ip"`        // 签发时的客户端IP
+}
+
+// TableName 指定数据库表名
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
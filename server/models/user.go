@@ -10,12 +10,28 @@ import (
 // 使用 gorm.Model 包含 ID, CreatedAt, UpdatedAt, DeletedAt 字段
 type User struct {
 	gorm.Model
-	Username string    `gorm:"uniqueIndex;not null" json:"username"`        // 登录用户名，唯一索引
-	Password string    `gorm:"not null" json:"-"`                           // 密码，不返回给前端
-	Nickname string    `json:"nickname"`                                    // 用户昵称，用于显示
-	Avatar   string    `json:"avatar"`                                      // 头像URL
-	Level    UserLevel `gorm:"embedded;embeddedPrefix:level_" json:"level"` // 用户等级信息
-	Stats    UserStats `gorm:"embedded;embeddedPrefix:stats_" json:"stats"` // 学习统计数据
+	Username        string    `gorm:"uniqueIndex;not null" json:"username"`        // 登录用户名，唯一索引
+	Password        string    `gorm:"not null" json:"-"`                           // 密码，不返回给前端
+	Nickname        string    `json:"nickname"`                                    // 用户昵称，用于显示
+	Avatar          string    `json:"avatar"`                                      // 头像URL，由服务端生成
+	AvatarKey       string    `gorm:"size:100" json:"-"`                           // 头像在对象存储中的目录
+	Timezone        string    `gorm:"size:64" json:"timezone"`                     // IANA时区，如 Asia/Shanghai，为空使用服务端默认
+	DayRolloverHour *int      `json:"day_rollover_hour"`                           // 学习日切换时刻（0-23点），为空使用服务端默认
+	StreakFreeze    bool      `json:"streak_freeze"`                               // 是否开启连续学习保护
+	Role            string    `gorm:"size:20;not null;default:user" json:"role"`   // 用户角色，只能由服务端修改
+	Level           UserLevel `gorm:"embedded;embeddedPrefix:level_" json:"level"` // 用户等级信息
+	Stats           UserStats `gorm:"embedded;embeddedPrefix:stats_" json:"stats"` // 学习统计数据
+}
+
+// 用户角色
+const (
+	RoleUser  = "user"  // 普通用户，注册时的默认角色
+	RoleAdmin = "admin" // 管理员，只能通过 set-role 子命令授予
+)
+
+// IsAdmin 是否为管理员
+func (u *User) IsAdmin() bool {
+	return u.Role == RoleAdmin
 }
 
 // UserLevel 用户等级信息
@@ -39,6 +55,7 @@ type UserStats struct {
 	TotalListeningMinutes int        `json:"total_listening_minutes"` // 累计听力练习分钟数
 	TotalSpeakingMinutes  int        `json:"total_speaking_minutes"`  // 累计口语练习分钟数
 	LastStudyDate         *time.Time `json:"last_study_date"`         // 上次学习日期
+	StreakFreezesLeft     int        `json:"streak_freezes_left"`     // 本月剩余的连续学习保护次数
 }
 
 // TableName 指定数据库表名
@@ -68,5 +85,6 @@ func GetDefaultStats() UserStats {
 		TotalListeningMinutes: 0,
 		TotalSpeakingMinutes:  0,
 		LastStudyDate:         nil,
+		StreakFreezesLeft:     0,
 	}
 }
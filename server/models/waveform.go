@@ -0,0 +1,49 @@
+package models
+
+import "gorm.io/gorm"
+
+// 波形来源
+const (
+	WaveformSourceAudio     = "audio"     // 托管音频
+	WaveformSourceRecording = "recording" // 用户录音
+)
+
+// 波形生成状态
+const (
+	WaveformStatusPending     = "pending"     // 等待后台任务处理
+	WaveformStatusReady       = "ready"       // 已生成
+	WaveformStatusFailed      = "failed"      // 解码或计算失败
+	WaveformStatusUnsupported = "unsupported" // 服务端无法解码该格式
+)
+
+// Waveform 音频波形
+// 上传后由后台任务解码并按多个分辨率计算峰值，客户端无需下载完整音频即可绘制波形
+type Waveform struct {
+	gorm.Model
+	SourceType string  `gorm:"size:20;uniqueIndex:idx_waveform_source;not null" json:"source_type"` // 来源类型
+	SourceID   uint    `gorm:"uniqueIndex:idx_waveform_source;not null" json:"source_id"`           // 托管音频或录音ID
+	Status     string  `gorm:"size:20;index;not null" json:"status"`                                // 生成状态
+	Error      string  `gorm:"size:255" json:"error"`                                               // 失败原因
+	Attempts   int     `json:"attempts"`                                                            // 已尝试次数
+	Duration   float64 `json:"duration"`                                                            // 解码得到的时长（秒）
+}
+
+// TableName 指定数据库表名
+func (Waveform) TableName() string {
+	return "waveforms"
+}
+
+// WaveformPeaks 某一分辨率下的波形峰值
+// Data 依次存放每段的最小值、最大值，均为 8 位有符号整数（-128~127）
+type WaveformPeaks struct {
+	gorm.Model
+	WaveformID uint   `gorm:"uniqueIndex:idx_waveform_resolution;not null" json:"waveform_id"` // 波形ID
+	Resolution int    `gorm:"uniqueIndex:idx_waveform_resolution;not null" json:"resolution"`  // 每秒峰值对数
+	Length     int    `json:"length"`                                                          // 峰值对数
+	Data       []byte `json:"-"`                                                               // 峰值数据
+}
+
+// TableName 指定数据库表名
+func (WaveformPeaks) TableName() string {
+	return "waveform_peaks"
+}
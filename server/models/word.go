@@ -0,0 +1,57 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Word 单词模型
+// 词条本身与学习状态分离，学习状态见 UserWordProgress
+type Word struct {
+	gorm.Model
+	Headword     string   `gorm:"size:100;uniqueIndex:idx_words_headword_active,where:deleted_at IS NULL;not null" json:"word"` // 英文单词，只在未删除的单词中唯一
+	Phonetic     string   `gorm:"size:100" json:"phonetic"`                                                                     // 音标
+	Meaning      string   `gorm:"not null" json:"meaning"`                                                                      // 中文释义
+	MemoryTip    string   `json:"memory_tip"`                                                                                   // 记忆技巧
+	Examples     []string `gorm:"serializer:json" json:"examples"`                                                              // 双语例句列表
+	Translations []string `gorm:"serializer:json" json:"translations"`                                                          // 例句翻译列表
+	Frequency    int      `gorm:"index" json:"frequency"`                                                                       // 词频排名，越小越常用
+	AudioURL     string   `json:"audio_url"`                                                                                    // 发音音频
+}
+
+// TableName 指定数据库表名
+func (Word) TableName() string {
+	return "words"
+}
+
+// 单词学习状态，与前端 WordStatus 对应
+const (
+	WordStatusNew       = "new"       // 新词（未学习）
+	WordStatusKnown     = "known"     // 认识
+	WordStatusFuzzy     = "fuzzy"     // 模糊
+	WordStatusForgotten = "forgotten" // 忘记
+)
+
+// UserWordProgress 用户单词学习进度
+// 每个用户每个单词一条记录，保存间隔重复调度状态，使复习进度跨设备同步
+type UserWordProgress struct {
+	gorm.Model
+	UserID         uint       `gorm:"uniqueIndex:idx_user_word;index:idx_user_due,priority:1;not null" json:"user_id"` // 用户ID
+	WordID         uint       `gorm:"uniqueIndex:idx_user_word;not null" json:"word_id"`                               // 单词ID
+	Word           Word       `json:"word"`                                                                            // 单词详情
+	Status         string     `gorm:"size:20;not null" json:"status"`                                                  // 最近一次反馈
+	EaseFactor     float64    `gorm:"not null" json:"ease_factor"`                                                     // SM-2 难度系数
+	Interval       int        `json:"interval"`                                                                        // 当前复习间隔（天）
+	Repetitions    int        `json:"repetitions"`                                                                     // 连续答对次数
+	Lapses         int        `json:"lapses"`                                                                          // 遗忘次数
+	ReviewCount    int        `json:"review_count"`                                                                    // 复习次数
+	CorrectCount   int        `json:"correct_count"`                                                                   // 认识次数
+	LastReviewedAt *time.Time `json:"last_reviewed_at"`                                                                // 上次复习时间
+	DueAt          time.Time  `gorm:"index:idx_user_due,priority:2;not null" json:"next_review_time"`                  // 下次复习时间
+}
+
+// TableName 指定数据库表名
+func (UserWordProgress) TableName() string {
+	return "user_word_progress"
+}
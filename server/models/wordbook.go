@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 词书类型
+const (
+	WordbookKindSystem   = "system"   // 官方词书，所有用户可订阅
+	WordbookKindPersonal = "personal" // 个人词库，仅所有者可见
+)
+
+// 用户词书角色
+const (
+	WordbookRolePrimary   = "primary"   // 主词书，每个用户仅一本
+	WordbookRoleAuxiliary = "auxiliary" // 辅助词书，可有多本
+	WordbookRolePersonal  = "personal"  // 个人词库
+)
+
+// Wordbook 词书模型
+type Wordbook struct {
+	gorm.Model
+	Name        string `gorm:"size:100;not null" json:"name"`                                                               // 词书名称
+	Description string `json:"description"`                                                                                 // 词书描述
+	Category    string `gorm:"size:50;index" json:"category"`                                                               // 分类，如 考试/场景/兴趣
+	Level       string `gorm:"size:10" json:"level"`                                                                        // 适用等级 (A1-C2)
+	Kind        string `gorm:"size:20;index;not null" json:"kind"`                                                          // system / personal
+	OwnerID     *uint  `gorm:"uniqueIndex:idx_wordbooks_personal_owner,where:deleted_at IS NULL" json:"owner_id,omitempty"` // 个人词库的所有者，每个用户只有一本个人词库
+	WordCount   int    `json:"total_words"`                                                                                 // 单词总数
+	CoverURL    string `json:"cover_url"`                                                                                   // 封面图
+	Sort        int    `gorm:"index" json:"-"`                                                                              // 目录排序
+}
+
+// TableName 指定数据库表名
+func (Wordbook) TableName() string {
+	return "wordbooks"
+}
+
+// WordbookWord 词书与单词的关联
+type WordbookWord struct {
+	WordbookID uint      `gorm:"primaryKey" json:"wordbook_id"`
+	WordID     uint      `gorm:"primaryKey;index" json:"word_id"`
+	Position   int       `gorm:"index" json:"position"` // 在词书中的学习顺序
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// TableName 指定数据库表名
+func (WordbookWord) TableName() string {
+	return "wordbook_words"
+}
+
+// UserWordbook 用户订阅的词书
+type UserWordbook struct {
+	gorm.Model
+	UserID        uint     `gorm:"uniqueIndex:idx_user_wordbook;not null" json:"user_id"`     // 用户ID
+	WordbookID    uint     `gorm:"uniqueIndex:idx_user_wordbook;not null" json:"wordbook_id"` // 词书ID
+	Wordbook      Wordbook `json:"wordbook"`                                                  // 词书详情
+	Role          string   `gorm:"size:20;not null" json:"role"`                              // primary / auxiliary / personal
+	Paused        bool     `json:"paused"`                                                    // 是否暂停（仅辅助词书）
+	DailyNewWords int      `json:"daily_new_words"`                                           // 每日新词数
+}
+
+// TableName 指定数据库表名
+func (UserWordbook) TableName() string {
+	return "user_wordbooks"
+}
@@ -0,0 +1,243 @@
+package recording
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+// audioTypes 允许上传的录音格式及其规范内容类型和扩展名
+// 手机录音常被识别为 video/* 容器，统一按音频保存
+var audioTypes = map[string]struct {
+	ContentType string
+	Ext         string
+}{
+	"audio/wav":   {"audio/wav", ".wav"},
+	"audio/mpeg":  {"audio/mpeg", ".mp3"},
+	"audio/aac":   {"audio/aac", ".aac"},
+	"audio/x-m4a": {"audio/mp4", ".m4a"},
+	"audio/mp4":   {"audio/mp4", ".m4a"},
+	"video/mp4":   {"audio/mp4", ".m4a"},
+	"video/3gpp":  {"audio/3gpp", ".3gp"},
+	"audio/ogg":   {"audio/ogg", ".ogg"},
+	"video/webm":  {"audio/webm", ".webm"},
+	"audio/flac":  {"audio/flac", ".flac"},
+	"audio/amr":   {"audio/amr", ".amr"},
+}
+
+// Sniff 根据文件内容识别录音格式，不信任客户端声明的类型
+// 返回规范内容类型和扩展名，非音频文件返回 ok=false
+func Sniff(data []byte) (contentType, ext string, ok bool) {
+	for m := mimetype.Detect(data); m != nil; m = m.Parent() {
+		if t, found := audioTypes[m.String()]; found {
+			return t.ContentType, t.Ext, true
+		}
+	}
+	return "", "", false
+}
+
+// Duration 从容器头部解析录音时长（秒）
+// 不支持的格式（如 webm、amr）返回 ok=false
+func Duration(data []byte, contentType string) (float64, bool) {
+	switch contentType {
+	case "audio/wav":
+		return wavDuration(data)
+	case "audio/mp4", "audio/3gpp":
+		return mp4Duration(data)
+	case "audio/ogg":
+		return oggDuration(data)
+	case "audio/flac":
+		return flacDuration(data)
+	case "audio/mpeg":
+		return mp3Duration(data)
+	case "audio/aac":
+		return adtsDuration(data)
+	}
+	return 0, false
+}
+
+// wavDuration data 块长度除以每秒字节数
+func wavDuration(data []byte) (float64, bool) {
+	var byteRate uint32
+	for off := 12; off+8 <= len(data); {
+		id := string(data[off : off+4])
+		size := int(binary.LittleEndian.Uint32(data[off+4 : off+8]))
+		switch id {
+		case "fmt ":
+			if off+20 <= len(data) {
+				byteRate = binary.LittleEndian.Uint32(data[off+16 : off+20])
+			}
+		case "data":
+			if byteRate == 0 {
+				return 0, false
+			}
+			if remain := len(data) - off - 8; size > remain {
+				size = remain
+			}
+			return float64(size) / float64(byteRate), true
+		}
+		off += 8 + size + size%2
+	}
+	return 0, false
+}
+
+// mp4Duration 读取 moov/mvhd 中的时长和时间刻度
+func mp4Duration(data []byte) (float64, bool) {
+	moov, ok := findBox(data, "moov")
+	if !ok {
+		return 0, false
+	}
+	mvhd, ok := findBox(moov, "mvhd")
+	if !ok || len(mvhd) < 20 {
+		return 0, false
+	}
+	var timescale uint32
+	var duration uint64
+	if mvhd[0] == 1 {
+		if len(mvhd) < 32 {
+			return 0, false
+		}
+		timescale = binary.BigEndian.Uint32(mvhd[20:24])
+		duration = binary.BigEndian.Uint64(mvhd[24:32])
+	} else {
+		timescale = binary.BigEndian.Uint32(mvhd[12:16])
+		duration = uint64(binary.BigEndian.Uint32(mvhd[16:20]))
+	}
+	if timescale == 0 {
+		return 0, false
+	}
+	return float64(duration) / float64(timescale), true
+}
+
+// findBox 在同一层级中查找指定类型的 box，返回其内容
+func findBox(data []byte, name string) ([]byte, bool) {
+	for off := 0; off+8 <= len(data); {
+		size := int(binary.BigEndian.Uint32(data[off : off+4]))
+		header := 8
+		switch size {
+		case 0:
+			size = len(data) - off
+		case 1:
+			if off+16 > len(data) {
+				return nil, false
+			}
+			size = int(binary.BigEndian.Uint64(data[off+8 : off+16]))
+			header = 16
+		}
+		if size < header || off+size > len(data) {
+			return nil, false
+		}
+		if string(data[off+4:off+8]) == name {
+			return data[off+header : off+size], true
+		}
+		off += size
+	}
+	return nil, false
+}
+
+// oggDuration 最后一页的 granule position 除以采样率
+// Opus 固定以 48kHz 计数并需扣除 pre-skip，Vorbis 从标识头读取采样率
+func oggDuration(data []byte) (float64, bool) {
+	if len(data) < 28 || !bytes.HasPrefix(data, []byte("OggS")) {
+		return 0, false
+	}
+	segments := int(data[26])
+	if 27+segments > len(data) {
+		return 0, false
+	}
+	packet := data[27+segments:]
+
+	var rate, preSkip float64
+	switch {
+	case bytes.HasPrefix(packet, []byte("OpusHead")) && len(packet) >= 12:
+		rate = 48000
+		preSkip = float64(binary.LittleEndian.Uint16(packet[10:12]))
+	case bytes.HasPrefix(packet, []byte("\x01vorbis")) && len(packet) >= 16:
+		rate = float64(binary.LittleEndian.Uint32(packet[12:16]))
+	default:
+		return 0, false
+	}
+	if rate == 0 {
+		return 0, false
+	}
+
+	last := bytes.LastIndex(data, []byte("OggS"))
+	if last < 0 || last+14 > len(data) {
+		return 0, false
+	}
+	granule := float64(binary.LittleEndian.Uint64(data[last+6 : last+14]))
+	if granule <= preSkip {
+		return 0, false
+	}
+	return (granule - preSkip) / rate, true
+}
+
+// flacDuration 读取 STREAMINFO 中的采样率和总采样数
+func flacDuration(data []byte) (float64, bool) {
+	if len(data) < 8+18 || !bytes.HasPrefix(data, []byte("fLaC")) || data[4]&0x7F != 0 {
+		return 0, false
+	}
+	info := data[8:]
+	rate := uint32(info[10])<<12 | uint32(info[11])<<4 | uint32(info[12])>>4
+	total := uint64(info[13]&0x0F)<<32 | uint64(binary.BigEndian.Uint32(info[14:18]))
+	if rate == 0 || total == 0 {
+		return 0, false
+	}
+	return float64(total) / float64(rate), true
+}
+
+// MPEG-1 和 MPEG-2/2.5 Layer III 比特率表 (kbps)
+var (
+	mp3Bitrates  = [16]int{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 0}
+	mp3Bitrates2 = [16]int{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160, 0}
+)
+
+// mp3Duration 按首帧比特率估算时长
+// 录音通常为固定码率，可变码率时仅为近似值
+func mp3Duration(data []byte) (float64, bool) {
+	off := 0
+	if bytes.HasPrefix(data, []byte("ID3")) && len(data) >= 10 {
+		size := int(data[6])<<21 | int(data[7])<<14 | int(data[8])<<7 | int(data[9])
+		off = 10 + size
+	}
+	for ; off+4 <= len(data); off++ {
+		if data[off] != 0xFF || data[off+1]&0xE0 != 0xE0 {
+			continue
+		}
+		table := &mp3Bitrates
+		if (data[off+1]>>3)&0x03 != 3 {
+			table = &mp3Bitrates2
+		}
+		bitrate := table[data[off+2]>>4]
+		if bitrate == 0 {
+			continue
+		}
+		return float64(len(data)-off) * 8 / float64(bitrate*1000), true
+	}
+	return 0, false
+}
+
+// adtsSampleRates ADTS 采样率索引表
+var adtsSampleRates = [16]int{96000, 88200, 64000, 48000, 44100, 32000, 24000, 22050, 16000, 12000, 11025, 8000, 7350, 0, 0, 0}
+
+// adtsDuration 逐帧累加 AAC ADTS 帧，每帧 1024 个采样
+func adtsDuration(data []byte) (float64, bool) {
+	var frames, rate int
+	for off := 0; off+7 <= len(data); {
+		if data[off] != 0xFF || data[off+1]&0xF6 != 0xF0 {
+			break
+		}
+		rate = adtsSampleRates[(data[off+2]>>2)&0x0F]
+		length := int(data[off+3]&0x03)<<11 | int(data[off+4])<<3 | int(data[off+5])>>5
+		if length < 7 || rate == 0 {
+			break
+		}
+		frames++
+		off += length
+	}
+	if frames == 0 {
+		return 0, false
+	}
+	return float64(frames*1024) / float64(rate), true
+}
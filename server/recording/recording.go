@@ -0,0 +1,201 @@
+package recording
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"gorm.io/gorm"
+	"server/config"
+	"server/models"
+	"server/storage"
+	"server/utils"
+	"server/waveform"
+)
+
+// 录音上传错误
+var (
+	ErrUnsupportedType = errors.New("不支持的录音格式")
+	ErrTooLarge        = errors.New("录音文件过大")
+	ErrTooLong         = errors.New("录音时长超出限制")
+	ErrEmpty           = errors.New("录音文件为空")
+	ErrUnknownDuration = errors.New("无法识别该格式的录音时长，请使用 WAV、M4A 或 MP3 格式")
+)
+
+// purgeBatchSize 每批清理的过期录音数
+const purgeBatchSize = 100
+
+// Upload 待保存的录音
+type Upload struct {
+	UserID           uint
+	Purpose          string
+	RefID            string
+	Data             []byte
+	DeclaredDuration float64 // 客户端上报的时长，仅在无法从文件解析且未限制时长时使用
+}
+
+// Save 校验并保存录音
+// 容器头部无法解析时长的格式（如 webm、amr）在配置了 ffmpeg 时解码测量；仍无法确定且限制了时长时拒绝上传，
+// 避免客户端上报的时长绕过限制
+// 先写对象存储再写数据库，数据库写入失败时删除已上传的对象
+func Save(ctx context.Context, db *gorm.DB, store storage.Storage, cfg config.RecordingsConfig, up Upload, now time.Time) (*models.Recording, error) {
+	if len(up.Data) == 0 {
+		return nil, ErrEmpty
+	}
+	if cfg.MaxSizeMB > 0 && len(up.Data) > cfg.MaxSizeMB<<20 {
+		return nil, ErrTooLarge
+	}
+
+	contentType, ext, ok := Sniff(up.Data)
+	if !ok {
+		return nil, ErrUnsupportedType
+	}
+	duration, ok := Duration(up.Data, contentType)
+	if !ok {
+		duration, ok = probeDuration(ctx, up.Data, contentType)
+	}
+	if !ok {
+		if cfg.MaxDurationSeconds > 0 {
+			return nil, ErrUnknownDuration
+		}
+		duration = up.DeclaredDuration
+	}
+	if duration < 0 {
+		duration = 0
+	}
+	if cfg.MaxDurationSeconds > 0 && duration > float64(cfg.MaxDurationSeconds) {
+		return nil, ErrTooLong
+	}
+
+	key, err := newKey(up.UserID, now, ext)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.Put(ctx, key, bytes.NewReader(up.Data), int64(len(up.Data)), contentType); err != nil {
+		return nil, fmt.Errorf("store recording: %w", err)
+	}
+
+	rec := &models.Recording{
+		UserID:      up.UserID,
+		Purpose:     up.Purpose,
+		RefID:       up.RefID,
+		StorageKey:  key,
+		ContentType: contentType,
+		Size:        int64(len(up.Data)),
+		Duration:    duration,
+	}
+	if cfg.RetentionDays > 0 {
+		expires := now.AddDate(0, 0, cfg.RetentionDays)
+		rec.ExpiresAt = &expires
+	}
+	if err := db.Create(rec).Error; err != nil {
+		if delErr := store.Delete(ctx, key); delErr != nil {
+			utils.Warn("recording.Save - Cleanup object %s failed: %v", key, delErr)
+		}
+		return nil, err
+	}
+	// 波形由后台生成，入队失败不影响上传，查询波形时会重新入队
+	if err := waveform.Enqueue(db, models.WaveformSourceRecording, rec.ID); err != nil {
+		utils.Warn("recording.Save - Enqueue waveform failed: RecordingID=%d: %v", rec.ID, err)
+	}
+	return rec, nil
+}
+
+// probeDuration 调用 ffmpeg 解码测量时长，未配置 ffmpeg 或解码失败时返回 ok=false
+func probeDuration(ctx context.Context, data []byte, contentType string) (float64, bool) {
+	ffmpegPath := config.Get().Audio.FFmpegPath
+	if ffmpegPath == "" {
+		return 0, false
+	}
+	audio, err := waveform.Decode(ctx, data, contentType, ffmpegPath)
+	if err != nil {
+		utils.Warn("recording.probeDuration - Decode %s failed: %v", contentType, err)
+		return 0, false
+	}
+	return audio.Duration(), true
+}
+
+// Delete 删除录音及其音频文件
+// 先删除对象，失败时保留数据库记录以便重试
+func Delete(ctx context.Context, db *gorm.DB, store storage.Storage, rec *models.Recording) error {
+	if err := store.Delete(ctx, rec.StorageKey); err != nil {
+		return fmt.Errorf("delete object: %w", err)
+	}
+	if err := waveform.Remove(db, models.WaveformSourceRecording, rec.ID); err != nil {
+		return err
+	}
+	return db.Unscoped().Delete(rec).Error
+}
+
+// Purge 清理已过期的录音，返回删除数量
+// 删除失败的录音记录日志后跳过，留到下一轮重试，不阻塞其后的录音
+func Purge(ctx context.Context, db *gorm.DB, store storage.Storage, now time.Time) (int, error) {
+	deleted := 0
+	var lastID uint
+	for {
+		var batch []models.Recording
+		if err := db.Where("expires_at IS NOT NULL AND expires_at <= ? AND id > ?", now, lastID).
+			Order("id ASC").Limit(purgeBatchSize).Find(&batch).Error; err != nil {
+			return deleted, err
+		}
+		if len(batch) == 0 {
+			return deleted, nil
+		}
+		for i := range batch {
+			lastID = batch[i].ID
+			if err := Delete(ctx, db, store, &batch[i]); err != nil {
+				if ctx.Err() != nil {
+					return deleted, ctx.Err()
+				}
+				utils.Warn("Recording retention - Delete failed, skipped: RecordingID=%d: %v", batch[i].ID, err)
+				continue
+			}
+			deleted++
+		}
+		if err := ctx.Err(); err != nil {
+			return deleted, err
+		}
+	}
+}
+
+// StartRetention 启动后台任务，定期清理过期录音
+func StartRetention(db *gorm.DB, store storage.Storage, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			purgeGuarded(db, store)
+			<-ticker.C
+		}
+	}()
+}
+
+// purgeGuarded 执行一轮清理，panic 时记录日志，不影响下一轮和整个服务
+func purgeGuarded(db *gorm.DB, store storage.Storage) {
+	defer func() {
+		if r := recover(); r != nil {
+			utils.Error("Recording retention - Panic: %v\n%s", r, debug.Stack())
+		}
+	}()
+	n, err := Purge(context.Background(), db, store, time.Now())
+	if err != nil {
+		utils.Error("Recording retention - Purge failed after %d deletions: %v", n, err)
+	} else if n > 0 {
+		utils.Info("Recording retention - Purged %d expired recordings", n)
+	}
+}
+
+// newKey 生成录音的对象存储 key
+// 按用户和月份分目录，文件名随机，避免被猜测
+func newKey(userID uint, now time.Time, ext string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("recordings/%d/%s/%s%s", userID, now.UTC().Format("2006-01"), hex.EncodeToString(b), ext), nil
+}
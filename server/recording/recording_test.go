@@ -0,0 +1,75 @@
+package recording
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"server/models"
+	"server/storage"
+	"server/utils"
+)
+
+// failingStore 删除指定 key 时失败的存储
+type failingStore struct {
+	failKey string
+	deleted []string
+}
+
+func (s *failingStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	return nil
+}
+
+func (s *failingStore) Open(ctx context.Context, key string) (*storage.Object, error) {
+	return nil, storage.ErrNotFound
+}
+
+func (s *failingStore) Delete(ctx context.Context, key string) error {
+	if key == s.failKey {
+		return errors.New("permission denied")
+	}
+	s.deleted = append(s.deleted, key)
+	return nil
+}
+
+func TestPurgeSkipsFailedDelete(t *testing.T) {
+	// 删除失败以警告级别记录，测试中未初始化日志文件
+	utils.SetLogLevel("ERROR")
+	t.Cleanup(func() { utils.SetLogLevel("INFO") })
+
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.Recording{}, &models.Waveform{}, &models.WaveformPeaks{}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	expired := now.Add(-time.Hour)
+	for _, key := range []string{"bad", "a", "b"} {
+		rec := models.Recording{UserID: 1, StorageKey: key, ExpiresAt: &expired}
+		if err := db.Create(&rec).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	later := now.Add(time.Hour)
+	if err := db.Create(&models.Recording{UserID: 1, StorageKey: "kept", ExpiresAt: &later}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	store := &failingStore{failKey: "bad"}
+	n, err := Purge(context.Background(), db, store, now)
+	if err != nil || n != 2 {
+		t.Fatalf("Purge = %d, %v, want 2, nil", n, err)
+	}
+	var keys []string
+	db.Model(&models.Recording{}).Order("id").Pluck("storage_key", &keys)
+	if len(keys) != 2 || keys[0] != "bad" || keys[1] != "kept" {
+		t.Errorf("remaining = %v, want [bad kept]", keys)
+	}
+}
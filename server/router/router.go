@@ -24,12 +24,18 @@ func SetupRouter() *gin.Engine {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	// 公钥集合，供其他服务验证访问令牌
+	r.GET("/.well-known/jwks.json", handlers.JWKS)
+
 	// API路由组
 	api := r.Group("/api")
 	{
 		// 公开路由（无需认证）
 		api.POST("/auth/register", handlers.Register)
 		api.POST("/auth/login", handlers.Login)
+		api.POST("/auth/refresh", handlers.Refresh)
+		api.POST("/auth/logout", middleware.AuthMiddleware(), handlers.Logout)
+		api.GET("/avatars/:userId/:version/:file", handlers.GetAvatar)
 
 		// 用户相关路由（需要认证）
 		user := api.Group("/user")
@@ -39,6 +45,135 @@ func SetupRouter() *gin.Engine {
 			user.PUT("/profile", handlers.UpdateProfile)
 			user.PUT("/level", handlers.UpdateLevel)
 			user.PUT("/stats", handlers.UpdateStats)
+			user.POST("/avatar", handlers.UploadAvatar)
+			user.DELETE("/avatar", handlers.DeleteAvatar)
+		}
+
+		// 学习记录路由（需要认证）
+		studyGroup := api.Group("/study")
+		studyGroup.Use(middleware.AuthMiddleware())
+		{
+			studyGroup.POST("/events", handlers.PostStudyEvents)
+			studyGroup.GET("/stats", handlers.GetStudyStats)
+		}
+
+		// 单词复习路由（需要认证）
+		vocab := api.Group("/vocab")
+		vocab.Use(middleware.AuthMiddleware())
+		{
+			vocab.GET("/review/due", handlers.GetDueReviews)
+			vocab.POST("/review/:wordId", handlers.SubmitReview)
+		}
+
+		// 词书管理路由（需要认证）
+		wordbooks := api.Group("/wordbooks")
+		wordbooks.Use(middleware.AuthMiddleware())
+		{
+			wordbooks.GET("", handlers.ListWordbooks)
+			wordbooks.GET("/mine", handlers.ListMyWordbooks)
+			wordbooks.POST("/personal/words", handlers.AddPersonalWord)
+			wordbooks.DELETE("/personal/words/:wordId", handlers.RemovePersonalWord)
+			wordbooks.POST("/:id/subscribe", handlers.SubscribeWordbook)
+			wordbooks.DELETE("/:id/subscribe", handlers.UnsubscribeWordbook)
+			wordbooks.PUT("/:id/primary", handlers.SetPrimaryWordbook)
+			wordbooks.PUT("/:id/pause", handlers.PauseWordbook)
+			wordbooks.GET("/:id/progress", handlers.GetWordbookProgress)
+		}
+
+		// 口语路由（需要认证）
+		speaking := api.Group("/speaking")
+		speaking.Use(middleware.AuthMiddleware())
+		{
+			speaking.POST("/score", handlers.ScoreSpeaking)
+			speaking.GET("/shadowing/materials", handlers.ListShadowingMaterials)
+			speaking.GET("/shadowing/progress", handlers.ListShadowingProgress)
+			speaking.GET("/shadowing/materials/:id", handlers.GetShadowingState)
+			speaking.GET("/shadowing/materials/:id/next", handlers.GetNextShadowingSentence)
+			speaking.POST("/shadowing/sentences/:id/attempts", handlers.SubmitShadowingAttempt)
+			speaking.GET("/shadowing/sentences/:id/attempts", handlers.ListShadowingAttempts)
+		}
+
+		// 录音路由（需要认证）
+		recordings := api.Group("/recordings")
+		recordings.Use(middleware.AuthMiddleware())
+		{
+			recordings.POST("", handlers.UploadRecording)
+			recordings.GET("", handlers.ListRecordings)
+			recordings.GET("/:id", handlers.GetRecording)
+			recordings.GET("/:id/audio", handlers.GetRecordingAudio)
+			recordings.GET("/:id/peaks", handlers.GetRecordingPeaks)
+			recordings.DELETE("/:id", handlers.DeleteRecording)
+		}
+
+		// 听力训练路由（需要认证）
+		listeningGroup := api.Group("/listening")
+		listeningGroup.Use(middleware.AuthMiddleware())
+		{
+			listeningGroup.GET("/materials", handlers.ListListeningMaterials)
+			listeningGroup.GET("/materials/:id", handlers.GetListeningMaterial)
+			listeningGroup.GET("/materials/:id/progress", handlers.GetListeningProgress)
+			listeningGroup.POST("/materials/:id/progress", handlers.RecordListeningProgress)
+			listeningGroup.GET("/materials/:id/stages", handlers.GetSpeedStages)
+			listeningGroup.POST("/materials/:id/stages/:stage", handlers.RecordSpeedStage)
+			listeningGroup.GET("/progress", handlers.ListListeningProgress)
+			listeningGroup.GET("/sentences/:id/cloze", handlers.GetSentenceCloze)
+			listeningGroup.POST("/sentences/:id/dictation", handlers.GradeDictation)
+			listeningGroup.GET("/drills/phenomena", handlers.ListSpeechPhenomena)
+			listeningGroup.POST("/drills", handlers.StartDrill)
+			listeningGroup.GET("/drills/:id", handlers.GetDrill)
+			listeningGroup.POST("/drills/:id/answer", handlers.AnswerDrillItem)
+		}
+
+		// 实战对话路由（需要认证）
+		dialogues := api.Group("/dialogues")
+		dialogues.Use(middleware.AuthMiddleware())
+		{
+			dialogues.GET("", handlers.ListDialogueScenarios)
+			dialogues.GET("/:scenario", handlers.GetDialogueScenario)
+			dialogues.POST("/:scenario/sessions", handlers.StartDialogue)
+			dialogues.GET("/:scenario/sessions", handlers.ListDialogueSessions)
+			dialogues.GET("/:scenario/sessions/:id", handlers.GetDialogueSession)
+			dialogues.POST("/:scenario/sessions/:id/turns", handlers.PostDialogueTurn)
+			dialogues.POST("/:scenario/sessions/:id/finish", handlers.FinishDialogue)
+			dialogues.POST("/sessions/:id/hint", handlers.GetDialogueHint)
+			dialogues.GET("/sessions/:id/report", handlers.GetDialogueReport)
+		}
+
+		// 托管音频路由（需要认证）
+		api.GET("/audio/:id", middleware.AudioAuthMiddleware(), handlers.GetAudio)
+		api.GET("/audio/:id/url", middleware.AuthMiddleware(), handlers.GetAudioURL)
+		api.GET("/audio/:id/peaks", middleware.AuthMiddleware(), handlers.GetAudioPeaks)
+
+		// 测评路由（需要认证）
+		assessmentGroup := api.Group("/assessment")
+		assessmentGroup.Use(middleware.AuthMiddleware())
+		{
+			assessmentGroup.POST("/vocab", handlers.StartVocabAssessment)
+			assessmentGroup.GET("/vocab/:id", handlers.GetVocabAssessment)
+			assessmentGroup.GET("/vocab/:id/next", handlers.NextVocabItem)
+			assessmentGroup.POST("/vocab/:id/answer", handlers.AnswerVocabItem)
+			assessmentGroup.POST("/listening", handlers.StartListeningAssessment)
+			assessmentGroup.GET("/listening/profile", handlers.GetListeningProfile)
+			assessmentGroup.GET("/listening/:id", handlers.GetListeningAssessment)
+			assessmentGroup.POST("/listening/:id/items/:itemId/play", handlers.PlayListeningItem)
+			assessmentGroup.POST("/listening/:id/answer", handlers.AnswerListeningItem)
+		}
+
+		// 管理路由（需要管理员权限）
+		admin := api.Group("/admin")
+		admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+		{
+			admin.POST("/wordbooks/import", handlers.ImportWordbook)
+			admin.POST("/listening/items", handlers.AddListeningItems)
+			admin.POST("/listening/drills/items", handlers.AddDrillItems)
+			admin.POST("/audio", handlers.UploadAudio)
+			admin.DELETE("/audio/:id", handlers.DeleteAudio)
+			admin.POST("/listening/materials", handlers.CreateListeningMaterial)
+			admin.POST("/listening/materials/import", handlers.ImportSubtitles)
+			admin.PUT("/listening/materials/:id", handlers.UpdateListeningMaterial)
+			admin.DELETE("/listening/materials/:id", handlers.DeleteListeningMaterial)
+			admin.PUT("/shadowing/materials/:id", handlers.UpdateShadowingMaterial)
+			admin.POST("/dialogues/scenarios", handlers.SaveDialogueScenario)
 		}
 	}
 
@@ -0,0 +1,110 @@
+package scheduler
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Rating 单词卡片的复习反馈
+// 对应前端的 认识/模糊/忘记 三个按钮
+type Rating string
+
+const (
+	RatingKnown     Rating = "known"     // 认识
+	RatingFuzzy     Rating = "fuzzy"     // 模糊
+	RatingForgotten Rating = "forgotten" // 忘记
+)
+
+const (
+	// DefaultEaseFactor SM-2 初始难度系数
+	DefaultEaseFactor = 2.5
+	// MinEaseFactor SM-2 难度系数下限
+	MinEaseFactor = 1.3
+	// RelearnDelay 忘记后重新学习的间隔，在同一次学习中再次出现
+	RelearnDelay = 10 * time.Minute
+	// MaxInterval 复习间隔上限（天）
+	MaxInterval = 365
+)
+
+// ParseRating 解析复习反馈
+func ParseRating(s string) (Rating, error) {
+	switch r := Rating(s); r {
+	case RatingKnown, RatingFuzzy, RatingForgotten:
+		return r, nil
+	}
+	return "", fmt.Errorf("unknown rating %q", s)
+}
+
+// quality 将反馈映射为 SM-2 的 0-5 回忆质量
+func (r Rating) quality() int {
+	switch r {
+	case RatingKnown:
+		return 5
+	case RatingFuzzy:
+		return 3
+	default:
+		return 1
+	}
+}
+
+// State 单词的记忆状态
+type State struct {
+	EaseFactor  float64   // 难度系数
+	Interval    int       // 当前复习间隔（天）
+	Repetitions int       // 连续答对次数
+	Lapses      int       // 遗忘次数
+	DueAt       time.Time // 下次复习时间
+}
+
+// NewState 返回新词的初始状态
+func NewState(now time.Time) State {
+	return State{EaseFactor: DefaultEaseFactor, DueAt: now}
+}
+
+// Schedule 按 SM-2 算法根据反馈计算下一次复习时间
+// 参数: s 当前状态, r 反馈, now 复习时间
+// 返回: 更新后的状态
+func Schedule(s State, r Rating, now time.Time) State {
+	if s.EaseFactor < MinEaseFactor {
+		s.EaseFactor = DefaultEaseFactor
+	}
+
+	q := r.quality()
+	if q >= 3 {
+		switch s.Repetitions {
+		case 0:
+			s.Interval = 1
+		case 1:
+			s.Interval = 6
+		default:
+			s.Interval = int(math.Round(float64(s.Interval) * s.EaseFactor))
+		}
+		// 模糊的单词不按完整系数放大，避免间隔过快拉长
+		if r == RatingFuzzy && s.Repetitions > 1 {
+			s.Interval = int(math.Round(float64(s.Interval) * 0.6))
+		}
+		if s.Interval < 1 {
+			s.Interval = 1
+		}
+		if s.Interval > MaxInterval {
+			s.Interval = MaxInterval
+		}
+		s.Repetitions++
+		s.DueAt = now.AddDate(0, 0, s.Interval)
+	} else {
+		if s.Repetitions > 0 {
+			s.Lapses++
+		}
+		s.Repetitions = 0
+		s.Interval = 0
+		s.DueAt = now.Add(RelearnDelay)
+	}
+
+	d := float64(5 - q)
+	s.EaseFactor += 0.1 - d*(0.08+d*0.02)
+	if s.EaseFactor < MinEaseFactor {
+		s.EaseFactor = MinEaseFactor
+	}
+	return s
+}
@@ -0,0 +1,61 @@
+package scheduler
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSchedule(t *testing.T) {
+	now := time.Date(2026, 10, 17, 9, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	tests := []struct {
+		name   string
+		state  State
+		rating Rating
+		want   State
+	}{
+		{"new word known", NewState(now), RatingKnown,
+			State{EaseFactor: 2.6, Interval: 1, Repetitions: 1, DueAt: now.Add(day)}},
+		{"second known", State{EaseFactor: 2.6, Interval: 1, Repetitions: 1}, RatingKnown,
+			State{EaseFactor: 2.7, Interval: 6, Repetitions: 2, DueAt: now.Add(6 * day)}},
+		{"third known multiplies by ease", State{EaseFactor: 2.7, Interval: 6, Repetitions: 2}, RatingKnown,
+			State{EaseFactor: 2.8, Interval: 16, Repetitions: 3, DueAt: now.Add(16 * day)}},
+		{"new word fuzzy", NewState(now), RatingFuzzy,
+			State{EaseFactor: 2.36, Interval: 1, Repetitions: 1, DueAt: now.Add(day)}},
+		{"fuzzy damps growth", State{EaseFactor: 2.5, Interval: 6, Repetitions: 2}, RatingFuzzy,
+			State{EaseFactor: 2.36, Interval: 9, Repetitions: 3, DueAt: now.Add(9 * day)}},
+		{"forgotten resets and counts lapse", State{EaseFactor: 2.5, Interval: 16, Repetitions: 3}, RatingForgotten,
+			State{EaseFactor: 1.96, Interval: 0, Repetitions: 0, Lapses: 1, DueAt: now.Add(RelearnDelay)}},
+		{"forgotten new word is not a lapse", NewState(now), RatingForgotten,
+			State{EaseFactor: 1.96, DueAt: now.Add(RelearnDelay)}},
+		{"ease floor", State{EaseFactor: MinEaseFactor, Interval: 3, Repetitions: 3, Lapses: 2}, RatingForgotten,
+			State{EaseFactor: MinEaseFactor, Lapses: 3, DueAt: now.Add(RelearnDelay)}},
+		{"interval cap", State{EaseFactor: 2.5, Interval: 200, Repetitions: 5}, RatingKnown,
+			State{EaseFactor: 2.6, Interval: MaxInterval, Repetitions: 6, DueAt: now.Add(MaxInterval * day)}},
+		{"missing ease uses default", State{Repetitions: 0}, RatingKnown,
+			State{EaseFactor: 2.6, Interval: 1, Repetitions: 1, DueAt: now.Add(day)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Schedule(tt.state, tt.rating, now)
+			if math.Abs(got.EaseFactor-tt.want.EaseFactor) > 1e-9 || got.Interval != tt.want.Interval ||
+				got.Repetitions != tt.want.Repetitions || got.Lapses != tt.want.Lapses || !got.DueAt.Equal(tt.want.DueAt) {
+				t.Errorf("Schedule() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRating(t *testing.T) {
+	for _, s := range []string{"known", "fuzzy", "forgotten"} {
+		if r, err := ParseRating(s); err != nil || string(r) != s {
+			t.Errorf("ParseRating(%q) = %q, %v", s, r, err)
+		}
+	}
+	for _, s := range []string{"", "Known", "easy"} {
+		if _, err := ParseRating(s); err == nil {
+			t.Errorf("ParseRating(%q) succeeded, want error", s)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package shadowing
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"server/models"
+)
+
+// rollupWindow 计算口语分数时只统计该时间内练习过的材料，反映近期水平
+const rollupWindow = 30 * 24 * time.Hour
+
+// SentenceSummary 单句的跟读成绩
+type SentenceSummary struct {
+	Seq            int        `json:"seq"`
+	SentenceID     uint       `json:"sentence_id"`
+	Text           string     `json:"text"`
+	StartTime      float64    `json:"start_time"`
+	EndTime        float64    `json:"end_time"`
+	Attempts       int        `json:"attempts"`         // 录音次数
+	BestScore      float64    `json:"best_score"`       // 最高分
+	LatestScore    float64    `json:"latest_score"`     // 最近一次得分
+	LastPracticeAt *time.Time `json:"last_practice_at"` // 最近练习时间，未练习为空
+}
+
+// State 材料的跟读汇总、逐句成绩及下一句
+type State struct {
+	*models.ShadowingProgress
+	PassScore int               `json:"pass_score"` // 单句达标分数
+	NextSeq   int               `json:"next_seq"`   // 下一句句序，0 表示全部达标
+	Sentences []SentenceSummary `json:"sentences"`  // 各句成绩，按句序排列
+}
+
+// sentenceStat 单句的汇总统计
+type sentenceStat struct {
+	Seq        int
+	Attempts   int
+	BestScore  float64
+	LatestID   uint
+	Latest     float64
+	PracticeAt time.Time
+}
+
+// PassScore 材料的单句达标分数，材料未单独设置时使用默认值
+func PassScore(material *models.ShadowingMaterial, defaultScore int) int {
+	if material.PassScore > 0 {
+		return material.PassScore
+	}
+	return defaultScore
+}
+
+// Record 保存一次跟读评分并重新汇总材料进度，同时更新用户的口语分数
+// material 需已加载所属的听力材料
+func Record(tx *gorm.DB, attempt *models.ShadowingAttempt, material *models.ShadowingMaterial, now time.Time) (*models.ShadowingProgress, error) {
+	attempt.PracticeAt = now
+	if err := tx.Create(attempt).Error; err != nil {
+		return nil, err
+	}
+
+	progress, _, err := summarize(tx, attempt.UserID, material)
+	if err != nil {
+		return nil, err
+	}
+	progress.LastSeq = attempt.Seq
+	progress.LastPracticeAt = now
+	if progress.CompletedAt == nil && progress.TotalSentences > 0 && progress.PracticedSentences >= progress.TotalSentences {
+		progress.CompletedAt = &now
+	}
+	if err := saveProgress(tx, progress); err != nil {
+		return nil, err
+	}
+	if err := ApplyToUser(tx, attempt.UserID, now); err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+// saveProgress 保存汇总进度
+// 首次练习时按用户和材料 upsert，并发提交同一材料的第一次跟读不会因唯一索引冲突失败
+func saveProgress(tx *gorm.DB, progress *models.ShadowingProgress) error {
+	if progress.ID != 0 {
+		return tx.Save(progress).Error
+	}
+	return tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "material_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"total_sentences", "practiced_sentences", "attempts", "best_score", "latest_score",
+			"last_seq", "last_practice_at", "completed_at", "updated_at", "deleted_at",
+		}),
+	}).Create(progress).Error
+}
+
+// summarize 加载进度记录并根据逐次评分重新统计
+// 尚未练习过的材料返回未保存的初始进度
+func summarize(tx *gorm.DB, userID uint, material *models.ShadowingMaterial) (*models.ShadowingProgress, map[int]sentenceStat, error) {
+	progress := &models.ShadowingProgress{UserID: userID, MaterialID: material.ID}
+	err := tx.Where("user_id = ? AND material_id = ?", userID, material.ID).First(progress).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, err
+	}
+
+	var rows []sentenceStat
+	err = tx.Model(&models.ShadowingAttempt{}).
+		Select("seq, COUNT(*) AS attempts, MAX(overall) AS best_score, MAX(id) AS latest_id").
+		Where("user_id = ? AND material_id = ? AND seq <= ?", userID, material.ID, material.Material.SentenceCount).
+		Group("seq").Scan(&rows).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	latestIDs := make([]uint, 0, len(rows))
+	for _, r := range rows {
+		latestIDs = append(latestIDs, r.LatestID)
+	}
+	var latest []models.ShadowingAttempt
+	if len(latestIDs) > 0 {
+		if err := tx.Select("id", "seq", "overall", "practice_at").Where("id IN ?", latestIDs).Find(&latest).Error; err != nil {
+			return nil, nil, err
+		}
+	}
+
+	stats := make(map[int]sentenceStat, len(rows))
+	for _, r := range rows {
+		stats[r.Seq] = r
+	}
+	for _, a := range latest {
+		s := stats[a.Seq]
+		s.Latest, s.PracticeAt = a.Overall, a.PracticeAt
+		stats[a.Seq] = s
+	}
+
+	progress.TotalSentences = material.Material.SentenceCount
+	progress.PracticedSentences, progress.Attempts = len(stats), 0
+	var best, last float64
+	for _, s := range stats {
+		progress.Attempts += s.Attempts
+		best += s.BestScore
+		last += s.Latest
+	}
+	progress.BestScore, progress.LatestScore = 0, 0
+	if n := float64(len(stats)); n > 0 {
+		progress.BestScore = int(math.Round(best / n))
+		progress.LatestScore = int(math.Round(last / n))
+	}
+	return progress, stats, nil
+}
+
+// LoadState 获取材料的跟读汇总及逐句成绩
+// material 需已加载所属的听力材料，defaultPassScore 为材料未单独设置时的达标分数
+func LoadState(db *gorm.DB, userID uint, material *models.ShadowingMaterial, defaultPassScore int) (*State, error) {
+	progress, stats, err := summarize(db, userID, material)
+	if err != nil {
+		return nil, err
+	}
+	passScore := PassScore(material, defaultPassScore)
+	var sentences []models.ListeningSentence
+	if err := db.Where("material_id = ?", material.ListeningMaterialID).Order("seq ASC").Find(&sentences).Error; err != nil {
+		return nil, err
+	}
+
+	state := &State{ShadowingProgress: progress, PassScore: passScore, Sentences: make([]SentenceSummary, 0, len(sentences))}
+	for _, s := range sentences {
+		summary := SentenceSummary{Seq: s.Seq, SentenceID: s.ID, Text: s.Text, StartTime: s.StartTime, EndTime: s.EndTime}
+		if st, ok := stats[s.Seq]; ok {
+			summary.Attempts = st.Attempts
+			summary.BestScore = st.BestScore
+			summary.LatestScore = st.Latest
+			practiceAt := st.PracticeAt
+			summary.LastPracticeAt = &practiceAt
+		}
+		state.Sentences = append(state.Sentences, summary)
+	}
+	state.NextSeq = nextSeq(state.Sentences, passScore)
+	return state, nil
+}
+
+// nextSeq 下一句要练习的句子
+// 优先返回第一句未练习的句子，其次是最高分未达标的句子中得分最低的一句，都达标时返回 0
+func nextSeq(sentences []SentenceSummary, passScore int) int {
+	weakest := 0
+	lowest := math.Inf(1)
+	for _, s := range sentences {
+		if s.Attempts == 0 {
+			return s.Seq
+		}
+		if s.BestScore < float64(passScore) && s.BestScore < lowest {
+			weakest, lowest = s.Seq, s.BestScore
+		}
+	}
+	return weakest
+}
+
+// ApplyToUser 根据近期跟读成绩更新用户的口语分数
+// 口语分数为近期练习过的材料最高分平均值，按各材料练习过的句数加权；近期没有练习时保持不变
+func ApplyToUser(tx *gorm.DB, userID uint, now time.Time) error {
+	var row struct {
+		Weighted  float64
+		Sentences int
+	}
+	err := tx.Model(&models.ShadowingProgress{}).
+		Select("COALESCE(SUM(best_score * practiced_sentences), 0) AS weighted, COALESCE(SUM(practiced_sentences), 0) AS sentences").
+		Where("user_id = ? AND last_practice_at >= ?", userID, now.Add(-rollupWindow)).
+		Scan(&row).Error
+	if err != nil || row.Sentences == 0 {
+		return err
+	}
+	score := int(math.Round(row.Weighted / float64(row.Sentences)))
+	return tx.Model(&models.User{}).Where("id = ?", userID).Update("level_speaking_score", score).Error
+}
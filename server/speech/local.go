@@ -0,0 +1,228 @@
+package speech
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// LocalScorerName 内置离线评分引擎名称
+const LocalScorerName = "local"
+
+const (
+	// maxLocalDuration 离线引擎可处理的最长录音（秒）
+	maxLocalDuration = 120.0
+	// 理想语速区间（词/分钟）
+	idealRateLow  = 100.0
+	idealRateHigh = 170.0
+	// 停顿占比不超过该值时流利度不扣分
+	idealPauseRatio = 0.15
+	// 自然语调的音高变化幅度区间（半音）
+	idealPitchRangeLow  = 2.0
+	idealPitchRangeHigh = 6.0
+	// 综合分权重
+	weightPronunciation = 0.4
+	weightFluency       = 0.3
+	weightIntonation    = 0.3
+)
+
+func init() {
+	Register(LocalScorer{})
+}
+
+// LocalScorer 基于声学特征的离线评分引擎
+// 不做语音识别：流利度由 VAD 得到的语速和停顿计算，语调比较与原声的音高曲线，
+// 发音准确度以音节数和发声时长与参考的匹配程度近似，仅支持 WAV 录音
+type LocalScorer struct{}
+
+// Name 引擎名称
+func (LocalScorer) Name() string {
+	return LocalScorerName
+}
+
+// Score 对录音评分
+func (LocalScorer) Score(ctx context.Context, req *ScoreRequest) (*Score, error) {
+	if !isWAV(req.Format) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, req.Format)
+	}
+	user, err := analyzeWAV(req.Audio)
+	if err != nil {
+		return nil, err
+	}
+
+	var ref *Analysis
+	if len(req.ReferenceAudio) > 0 {
+		if !isWAV(req.ReferenceFormat) {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedFormat, req.ReferenceFormat)
+		}
+		if ref, err = analyzeWAV(req.ReferenceAudio); err != nil {
+			return nil, fmt.Errorf("reference audio: %w", err)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s := &Score{Engine: LocalScorerName, Feedback: []string{}}
+	d := &ScoreDetails{
+		Duration:          round2(user.Audio.Duration()),
+		SpeechDuration:    round2(user.PhonationTime()),
+		Syllables:         user.CountSyllables(),
+		ExpectedSyllables: TextSyllables(req.ReferenceText),
+	}
+	s.Details = d
+
+	s.Fluency = scoreFluency(user, len(Words(req.ReferenceText)), d, &s.Feedback)
+	s.Intonation = scoreIntonation(user, ref, d, &s.Feedback)
+	s.Pronunciation = scorePronunciation(user, ref, d, &s.Feedback)
+	s.Overall = round1(weightPronunciation*s.Pronunciation + weightFluency*s.Fluency + weightIntonation*s.Intonation)
+
+	if user.PeakDB-user.NoiseDB < 20 {
+		s.Feedback = append(s.Feedback, "环境噪音较大，建议在安静的地方录音")
+	}
+	if len(s.Feedback) == 0 {
+		s.Feedback = append(s.Feedback, "表现很好，继续保持")
+	}
+	return s, nil
+}
+
+// scoreFluency 根据语速和停顿计算流利度
+func scoreFluency(a *Analysis, words int, d *ScoreDetails, feedback *[]string) float64 {
+	span := a.SpeechSpan()
+	pauses := a.Pauses()
+	var pauseTime float64
+	for _, p := range pauses {
+		pauseTime += p
+		if p >= longPause {
+			d.LongPauses++
+		}
+	}
+	d.PauseRatio = round2(pauseTime / span)
+	if words > 0 {
+		d.SpeechRate = round1(float64(words) / span * 60)
+	}
+
+	rateScore := 100.0
+	switch {
+	case words == 0:
+		// 没有参考文本时无法计算语速，只按停顿评分
+	case d.SpeechRate < idealRateLow:
+		rateScore = linear(d.SpeechRate, 30, idealRateLow)
+		*feedback = append(*feedback, "语速偏慢，试着把词连起来说")
+	case d.SpeechRate > idealRateHigh:
+		rateScore = 60 + 40*linear(250-d.SpeechRate, 0, 250-idealRateHigh)/100
+		*feedback = append(*feedback, "语速偏快，注意吐字清晰")
+	}
+
+	pauseScore := 100.0
+	if d.PauseRatio > idealPauseRatio {
+		pauseScore = 100 - linear(d.PauseRatio, idealPauseRatio, 0.6)
+	}
+	if d.LongPauses > 0 {
+		*feedback = append(*feedback, fmt.Sprintf("有%d处较长停顿，尽量一口气说完意群", d.LongPauses))
+	}
+	penalty := 10 * math.Max(0, float64(d.LongPauses-1))
+
+	return round1(clamp(0.5*rateScore + 0.5*pauseScore - penalty))
+}
+
+// scoreIntonation 比较与原声的音高曲线；没有原声时按音高变化幅度评分
+func scoreIntonation(user, ref *Analysis, d *ScoreDetails, feedback *[]string) float64 {
+	contour := user.Contour()
+	if contour == nil {
+		*feedback = append(*feedback, "声音过轻或过短，无法分析语调")
+		return 0
+	}
+	d.PitchRange = round2(stddev(contour))
+
+	if ref != nil {
+		if refContour := ref.Contour(); refContour != nil {
+			r := correlation(contour, refContour)
+			d.PitchCorrelation = &r
+			rmsScore := math.Max(0, 1-rmse(contour, refContour)/6)
+			score := clamp(100 * (0.6*math.Max(r, 0) + 0.4*rmsScore))
+			if r < 0.5 {
+				*feedback = append(*feedback, "语调与原声差异较大，注意句中重读和句尾升降调")
+			}
+			return round1(score)
+		}
+	}
+
+	switch {
+	case d.PitchRange < idealPitchRangeLow:
+		*feedback = append(*feedback, "语调偏平，试着加强重读词的起伏")
+		return round1(40 + 60*d.PitchRange/idealPitchRangeLow)
+	case d.PitchRange > idealPitchRangeHigh:
+		return round1(clamp(100 - 10*(d.PitchRange-idealPitchRangeHigh)))
+	}
+	return 100
+}
+
+// scorePronunciation 以音节数和发声时长的匹配程度近似发音准确度
+// 吞音、漏读会减少音节核，拖音、多读会增加音节核或发声时长
+func scorePronunciation(user, ref *Analysis, d *ScoreDetails, feedback *[]string) float64 {
+	if d.ExpectedSyllables == 0 {
+		return 0
+	}
+	syllableMatch := ratio(float64(d.Syllables), float64(d.ExpectedSyllables))
+	score := 100 * syllableMatch
+
+	if ref != nil {
+		durationMatch := ratio(user.PhonationTime(), ref.PhonationTime())
+		score = 100 * (0.7*syllableMatch + 0.3*durationMatch)
+	}
+
+	if syllableMatch < 0.75 {
+		if d.Syllables < d.ExpectedSyllables {
+			*feedback = append(*feedback, "部分音节没有读出来，注意不要吞音漏读")
+		} else {
+			*feedback = append(*feedback, "音节偏多，注意不要在辅音后加多余的元音")
+		}
+	}
+	return round1(clamp(score))
+}
+
+// analyzeWAV 解码并分析 WAV 录音
+func analyzeWAV(data []byte) (*Analysis, error) {
+	audio, err := DecodeWAV(data)
+	if err != nil {
+		return nil, err
+	}
+	if audio.Duration() > maxLocalDuration {
+		return nil, ErrAudioTooLong
+	}
+	return Analyze(audio)
+}
+
+// isWAV 判断格式是否为 WAV
+func isWAV(format string) bool {
+	f := strings.ToLower(strings.TrimPrefix(format, "."))
+	return f == "wav" || f == "wave"
+}
+
+// ratio 两个正数的较小值与较大值之比
+func ratio(a, b float64) float64 {
+	if a <= 0 || b <= 0 {
+		return 0
+	}
+	return math.Min(a, b) / math.Max(a, b)
+}
+
+// linear 将 x 从 [lo, hi] 线性映射到 [0, 100]
+func linear(x, lo, hi float64) float64 {
+	return clamp(100 * (x - lo) / (hi - lo))
+}
+
+// clamp 限制在 [0, 100]
+func clamp(x float64) float64 {
+	return math.Max(0, math.Min(100, x))
+}
+
+func round1(x float64) float64 {
+	return math.Round(x*10) / 10
+}
+
+func round2(x float64) float64 {
+	return math.Round(x*100) / 100
+}
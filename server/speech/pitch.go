@@ -0,0 +1,229 @@
+package speech
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// minPitch / maxPitch 基频搜索范围 (Hz)
+	minPitch = 75.0
+	maxPitch = 400.0
+	// pitchWindow 基频分析窗长（秒），需覆盖最低基频的两个周期
+	pitchWindow = 0.04
+	// voicingThreshold 归一化自相关峰值低于该值视为清音
+	voicingThreshold = 0.45
+	// octaveTolerance 自相关峰达到最大值的该比例即视为基本周期
+	octaveTolerance = 0.9
+	// contourPoints 音高曲线比较时的重采样点数
+	contourPoints = 40
+	// pitchSampleRate 基频分析使用的采样率
+	pitchSampleRate = 8000
+)
+
+// PitchTrack 逐帧基频，清音或静音帧为0
+func (a *Analysis) PitchTrack() []float64 {
+	samples, rate := downsample(a.Audio.Samples, a.Audio.SampleRate, pitchSampleRate)
+	sr := float64(rate)
+	win := int(pitchWindow * sr)
+	hop := int(frameHop * sr)
+	minLag := int(sr / maxPitch)
+	maxLag := int(sr / minPitch)
+
+	track := make([]float64, len(a.Voiced))
+	for i, v := range a.Voiced {
+		start := i * hop
+		if !v || start+win+maxLag > len(samples) {
+			continue
+		}
+		track[i] = detectPitch(samples[start:start+win+maxLag], win, minLag, maxLag, sr)
+	}
+	return medianFilter(track, 2)
+}
+
+// downsample 按整数倍降采样，降采样前取区间均值作为简单低通
+// 基频分析不需要高频成分，降采样可显著减少自相关计算量
+func downsample(samples []float64, rate, target int) ([]float64, int) {
+	factor := rate / target
+	if factor <= 1 {
+		return samples, rate
+	}
+	out := make([]float64, len(samples)/factor)
+	for i := range out {
+		var sum float64
+		for _, s := range samples[i*factor : (i+1)*factor] {
+			sum += s
+		}
+		out[i] = sum / float64(factor)
+	}
+	return out, rate / factor
+}
+
+// detectPitch 归一化自相关法估计单帧基频
+func detectPitch(x []float64, win, minLag, maxLag int, sr float64) float64 {
+	var energy0 float64
+	for _, s := range x[:win] {
+		energy0 += s * s
+	}
+	if energy0 == 0 {
+		return 0
+	}
+
+	acf := make([]float64, maxLag+2)
+	best := 0.0
+	for lag := minLag; lag <= maxLag+1 && lag+win <= len(x); lag++ {
+		var corr, energyLag float64
+		for j := 0; j < win; j++ {
+			corr += x[j] * x[j+lag]
+			energyLag += x[j+lag] * x[j+lag]
+		}
+		if energyLag == 0 {
+			continue
+		}
+		acf[lag] = corr / math.Sqrt(energy0*energyLag)
+		if acf[lag] > best {
+			best = acf[lag]
+		}
+	}
+	if best < voicingThreshold {
+		return 0
+	}
+
+	// 周期的整数倍处自相关同样很高，取第一个接近最大值的峰，避免半频错误
+	for lag := minLag + 1; lag <= maxLag; lag++ {
+		if acf[lag] >= octaveTolerance*best && acf[lag] >= acf[lag-1] && acf[lag] >= acf[lag+1] {
+			return sr / float64(lag)
+		}
+	}
+	return 0
+}
+
+// medianFilter 对浊音帧做中值滤波，消除倍频和半频跳变
+func medianFilter(track []float64, radius int) []float64 {
+	out := make([]float64, len(track))
+	window := make([]float64, 0, 2*radius+1)
+	for i, f := range track {
+		if f == 0 {
+			continue
+		}
+		window = window[:0]
+		for j := i - radius; j <= i+radius; j++ {
+			if j >= 0 && j < len(track) && track[j] > 0 {
+				window = append(window, track[j])
+			}
+		}
+		sort.Float64s(window)
+		out[i] = window[len(window)/2]
+	}
+	return out
+}
+
+// Contour 以半音表示、相对中位基频的音高曲线
+// 时间轴归一化到发声区间并重采样为固定点数，使不同语速的录音可以比较
+// 浊音帧过少时返回 nil
+func (a *Analysis) Contour() []float64 {
+	track := a.PitchTrack()
+
+	var times, semis []float64
+	var voiced []float64
+	for _, f := range track {
+		if f > 0 {
+			voiced = append(voiced, f)
+		}
+	}
+	if len(voiced) < 5 {
+		return nil
+	}
+	sort.Float64s(voiced)
+	median := voiced[len(voiced)/2]
+
+	span := a.SpeechSpan()
+	origin := a.Segments[0].Start
+	for i, f := range track {
+		if f == 0 {
+			continue
+		}
+		times = append(times, (frameTime(i)-origin)/span)
+		semis = append(semis, 12*math.Log2(f/median))
+	}
+	return resample(times, semis, contourPoints)
+}
+
+// resample 线性插值到 [0, 1] 上的等距点，清音间隙由两侧浊音插值填补
+func resample(times, values []float64, points int) []float64 {
+	out := make([]float64, points)
+	j := 0
+	for i := range out {
+		t := float64(i) / float64(points-1)
+		for j < len(times)-1 && times[j+1] < t {
+			j++
+		}
+		switch {
+		case t <= times[0]:
+			out[i] = values[0]
+		case j >= len(times)-1:
+			out[i] = values[len(values)-1]
+		default:
+			t0, t1 := times[j], times[j+1]
+			w := 0.0
+			if t1 > t0 {
+				w = (t - t0) / (t1 - t0)
+			}
+			out[i] = values[j]*(1-w) + values[j+1]*w
+		}
+	}
+	return out
+}
+
+// stddev 标准差
+func stddev(x []float64) float64 {
+	if len(x) == 0 {
+		return 0
+	}
+	var mean float64
+	for _, v := range x {
+		mean += v
+	}
+	mean /= float64(len(x))
+	var sum float64
+	for _, v := range x {
+		sum += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sum / float64(len(x)))
+}
+
+// correlation 皮尔逊相关系数
+func correlation(x, y []float64) float64 {
+	n := len(x)
+	if n != len(y) || n == 0 {
+		return 0
+	}
+	var mx, my float64
+	for i := range x {
+		mx += x[i]
+		my += y[i]
+	}
+	mx /= float64(n)
+	my /= float64(n)
+	var sxy, sxx, syy float64
+	for i := range x {
+		dx, dy := x[i]-mx, y[i]-my
+		sxy += dx * dy
+		sxx += dx * dx
+		syy += dy * dy
+	}
+	if sxx == 0 || syy == 0 {
+		return 0
+	}
+	return sxy / math.Sqrt(sxx*syy)
+}
+
+// rmse 均方根误差
+func rmse(x, y []float64) float64 {
+	var sum float64
+	for i := range x {
+		d := x[i] - y[i]
+		sum += d * d
+	}
+	return math.Sqrt(sum / float64(len(x)))
+}
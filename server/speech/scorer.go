@@ -0,0 +1,94 @@
+package speech
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// 评分错误
+var (
+	ErrUnsupportedFormat = errors.New("不支持的音频格式")
+	ErrNoSpeech          = errors.New("未检测到有效语音")
+	ErrAudioTooLong      = errors.New("录音时长超出限制")
+)
+
+// ScoreRequest 发音评分请求
+// 音频保持原始字节，由具体实现决定支持哪些格式
+type ScoreRequest struct {
+	Audio           []byte // 用户录音
+	Format          string // 录音格式，如 wav / m4a
+	ReferenceText   string // 参考文本
+	ReferenceAudio  []byte // 参考音频（原声），可为空
+	ReferenceFormat string // 参考音频格式
+	Language        string // 语言，如 en-US
+}
+
+// Score 发音评分结果
+// 字段与客户端 SpeakingScore 对应，各项均为 0-100
+type Score struct {
+	Pronunciation float64       `json:"pronunciation"` // 发音准确度
+	Fluency       float64       `json:"fluency"`       // 流利度
+	Intonation    float64       `json:"intonation"`    // 语调自然度
+	Overall       float64       `json:"overall"`       // 综合评分
+	Feedback      []string      `json:"feedback"`      // 改进建议
+	Engine        string        `json:"engine"`        // 评分引擎
+	Details       *ScoreDetails `json:"details,omitempty"`
+}
+
+// ScoreDetails 评分依据的声学指标
+type ScoreDetails struct {
+	Duration          float64  `json:"duration"`                    // 录音时长（秒）
+	SpeechDuration    float64  `json:"speech_duration"`             // 发声时长（秒）
+	SpeechRate        float64  `json:"speech_rate"`                 // 语速（词/分钟）
+	PauseRatio        float64  `json:"pause_ratio"`                 // 停顿占比
+	LongPauses        int      `json:"long_pauses"`                 // 长停顿次数
+	Syllables         int      `json:"syllables"`                   // 检测到的音节数
+	ExpectedSyllables int      `json:"expected_syllables"`          // 参考文本音节数
+	PitchRange        float64  `json:"pitch_range"`                 // 音高变化幅度（半音标准差）
+	PitchCorrelation  *float64 `json:"pitch_correlation,omitempty"` // 与原声音高曲线的相关系数
+}
+
+// PronunciationScorer 发音评分引擎
+// 内置离线实现 local，云端语音评测服务实现该接口并注册后即可通过配置切换
+type PronunciationScorer interface {
+	// Name 引擎名称，对应配置中的 speaking.scorer
+	Name() string
+	// Score 对录音评分
+	Score(ctx context.Context, req *ScoreRequest) (*Score, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]PronunciationScorer)
+)
+
+// Register 注册评分引擎，同名引擎会被替换
+func Register(s PronunciationScorer) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[s.Name()] = s
+}
+
+// Get 按名称获取评分引擎
+func Get(name string) (PronunciationScorer, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	s, ok := registry[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown pronunciation scorer %q (available: %v)", name, names())
+	}
+	return s, nil
+}
+
+// names 已注册的引擎名称，调用方需持有读锁
+func names() []string {
+	list := make([]string, 0, len(registry))
+	for name := range registry {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return list
+}
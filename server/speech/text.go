@@ -0,0 +1,58 @@
+package speech
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Words 拆分参考文本中的英文单词
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+}
+
+// TextSyllables 估计参考文本的音节数
+func TextSyllables(text string) int {
+	total := 0
+	for _, w := range Words(text) {
+		total += wordSyllables(w)
+	}
+	return total
+}
+
+// wordSyllables 按元音组估计单词音节数
+// 规则覆盖大多数常见词：词尾不发音的 e 不计，辅音 + le 结尾计一个音节
+func wordSyllables(word string) int {
+	word = strings.ReplaceAll(word, "'", "")
+	if word == "" {
+		return 0
+	}
+
+	count := 0
+	prevVowel := false
+	for i, r := range word {
+		vowel := strings.ContainsRune("aeiou", r) || (r == 'y' && i > 0)
+		if vowel && !prevVowel {
+			count++
+		}
+		prevVowel = vowel
+	}
+
+	// 词尾 e 一般不发音，但 table、little 这类辅音 + le 自成音节
+	n := len(word)
+	silentE := n > 2 && word[n-1] == 'e' && !isVowel(word[n-2])
+	syllabicLE := n > 3 && word[n-2] == 'l' && !isVowel(word[n-3])
+	if silentE && !syllabicLE {
+		count--
+	}
+	if count < 1 {
+		count = 1
+	}
+	return count
+}
+
+// isVowel 判断字母是否为元音
+func isVowel(b byte) bool {
+	return strings.IndexByte("aeiouy", b) >= 0
+}
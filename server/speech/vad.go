@@ -0,0 +1,219 @@
+package speech
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// frameLength / frameHop 分析帧长和帧移（秒）
+	frameLength = 0.025
+	frameHop    = 0.010
+	// minDynamicRange 语音与底噪至少相差的分贝数，低于该值视为没有说话
+	minDynamicRange = 10.0
+	// gapBridge 短于该时长的静音视为词间过渡而非停顿（秒）
+	gapBridge = 0.15
+	// minSegment 短于该时长的发声段视为噪声（秒）
+	minSegment = 0.06
+	// longPause 长停顿阈值（秒）
+	longPause = 0.5
+	// nucleusDip 相邻音节核之间能量至少下降的分贝数
+	nucleusDip = 2.0
+	// minNucleusGap 相邻音节核的最小间隔（秒）
+	minNucleusGap = 0.1
+)
+
+// Segment 发声段，单位为秒
+type Segment struct {
+	Start float64
+	End   float64
+}
+
+// Duration 发声段时长
+func (s Segment) Duration() float64 {
+	return s.End - s.Start
+}
+
+// Analysis 录音的能量分析结果
+type Analysis struct {
+	Audio     *Audio
+	Energy    []float64 // 每帧能量 (dB)
+	Voiced    []bool    // 每帧是否为语音
+	Segments  []Segment // 发声段
+	Threshold float64   // 语音判定阈值 (dB)
+	NoiseDB   float64   // 底噪 (dB)
+	PeakDB    float64   // 语音峰值 (dB)
+}
+
+// SpeechSpan 从第一个发声段开始到最后一个发声段结束的时长
+func (a *Analysis) SpeechSpan() float64 {
+	if len(a.Segments) == 0 {
+		return 0
+	}
+	return a.Segments[len(a.Segments)-1].End - a.Segments[0].Start
+}
+
+// PhonationTime 发声段总时长
+func (a *Analysis) PhonationTime() float64 {
+	var total float64
+	for _, s := range a.Segments {
+		total += s.Duration()
+	}
+	return total
+}
+
+// Pauses 发声段之间的停顿时长
+func (a *Analysis) Pauses() []float64 {
+	var pauses []float64
+	for i := 1; i < len(a.Segments); i++ {
+		pauses = append(pauses, a.Segments[i].Start-a.Segments[i-1].End)
+	}
+	return pauses
+}
+
+// Analyze 基于短时能量的语音活动检测 (VAD)
+// 阈值根据录音自身的底噪和峰值自适应确定，不依赖录音音量
+func Analyze(audio *Audio) (*Analysis, error) {
+	frameLen := int(frameLength * float64(audio.SampleRate))
+	hop := int(frameHop * float64(audio.SampleRate))
+	if frameLen == 0 || hop == 0 || len(audio.Samples) < frameLen {
+		return nil, ErrNoSpeech
+	}
+
+	n := (len(audio.Samples)-frameLen)/hop + 1
+	energy := make([]float64, n)
+	for i := 0; i < n; i++ {
+		var sum float64
+		for _, s := range audio.Samples[i*hop : i*hop+frameLen] {
+			sum += s * s
+		}
+		energy[i] = 10 * math.Log10(sum/float64(frameLen)+1e-12)
+	}
+
+	sorted := append([]float64(nil), energy...)
+	sort.Float64s(sorted)
+	noise := percentile(sorted, 0.1)
+	peak := percentile(sorted, 0.98)
+	if peak-noise < minDynamicRange {
+		return nil, ErrNoSpeech
+	}
+	threshold := noise + math.Max(0.3*(peak-noise), 6)
+
+	voiced := make([]bool, n)
+	for i, e := range energy {
+		voiced[i] = e >= threshold
+	}
+
+	a := &Analysis{
+		Audio:     audio,
+		Energy:    energy,
+		Voiced:    voiced,
+		Threshold: threshold,
+		NoiseDB:   noise,
+		PeakDB:    peak,
+	}
+	a.Segments = segments(voiced)
+	if len(a.Segments) == 0 {
+		return nil, ErrNoSpeech
+	}
+	return a, nil
+}
+
+// segments 将逐帧判定合并为发声段
+// 先桥接过短的静音，再丢弃过短的发声段
+func segments(voiced []bool) []Segment {
+	var raw []Segment
+	start := -1
+	for i, v := range voiced {
+		switch {
+		case v && start < 0:
+			start = i
+		case !v && start >= 0:
+			raw = append(raw, Segment{Start: frameTime(start), End: frameTime(i-1) + frameLength})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		raw = append(raw, Segment{Start: frameTime(start), End: frameTime(len(voiced)-1) + frameLength})
+	}
+
+	var merged []Segment
+	for _, s := range raw {
+		if len(merged) > 0 && s.Start-merged[len(merged)-1].End < gapBridge {
+			merged[len(merged)-1].End = s.End
+			continue
+		}
+		merged = append(merged, s)
+	}
+
+	result := merged[:0]
+	for _, s := range merged {
+		if s.Duration() >= minSegment {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// CountSyllables 统计能量曲线中的音节核
+// 音节核为语音帧中的能量峰，与前一个峰之间需有足够的能量低谷
+func (a *Analysis) CountSyllables() int {
+	smooth := movingAverage(a.Energy, 3)
+	minGap := int(minNucleusGap / frameHop)
+
+	count := 0
+	lastPeak := -minGap
+	valley := math.Inf(1)
+	for i := 1; i < len(smooth)-1; i++ {
+		if smooth[i] < valley {
+			valley = smooth[i]
+		}
+		if !a.Voiced[i] || smooth[i] < smooth[i-1] || smooth[i] < smooth[i+1] {
+			continue
+		}
+		if smooth[i]-valley < nucleusDip && count > 0 {
+			continue
+		}
+		if i-lastPeak < minGap {
+			continue
+		}
+		count++
+		lastPeak = i
+		valley = smooth[i]
+	}
+	return count
+}
+
+// frameTime 帧起始时间
+func frameTime(i int) float64 {
+	return float64(i) * frameHop
+}
+
+// percentile 已排序切片的分位数
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(p * float64(len(sorted)-1))
+	return sorted[idx]
+}
+
+// movingAverage 滑动平均平滑
+func movingAverage(x []float64, radius int) []float64 {
+	out := make([]float64, len(x))
+	for i := range x {
+		lo, hi := i-radius, i+radius
+		if lo < 0 {
+			lo = 0
+		}
+		if hi >= len(x) {
+			hi = len(x) - 1
+		}
+		var sum float64
+		for j := lo; j <= hi; j++ {
+			sum += x[j]
+		}
+		out[i] = sum / float64(hi-lo+1)
+	}
+	return out
+}
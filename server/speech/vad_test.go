@@ -0,0 +1,69 @@
+package speech
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+// tone 生成指定采样率的正弦波，前后各留一段静音
+func tone(sampleRate int, seconds float64) *PCM {
+	n := int(seconds * float64(sampleRate))
+	samples := make([]float64, n)
+	for i := n / 4; i < n*3/4; i++ {
+		samples[i] = 0.5 * math.Sin(2*math.Pi*220*float64(i)/float64(sampleRate))
+	}
+	return &PCM{Channels: [][]float64{samples}, SampleRate: sampleRate}
+}
+
+func TestAnalyzeLowSampleRate(t *testing.T) {
+	for _, rate := range []int{1, 50, 99} {
+		audio := &Audio{Samples: make([]float64, 1000), SampleRate: rate}
+		if _, err := Analyze(audio); !errors.Is(err, ErrNoSpeech) {
+			t.Errorf("Analyze(%d Hz) err = %v, want ErrNoSpeech", rate, err)
+		}
+	}
+}
+
+func TestDecodeWAVSampleRate(t *testing.T) {
+	tests := []struct {
+		rate    int
+		wantErr error
+	}{
+		{50, ErrUnsupportedFormat},
+		{minSampleRate - 1, ErrUnsupportedFormat},
+		{minSampleRate, nil},
+		{16000, nil},
+	}
+	for _, tt := range tests {
+		_, err := DecodeWAVChannels(EncodeWAV(tone(tt.rate, 1)))
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("DecodeWAVChannels(%d Hz) err = %v, want %v", tt.rate, err, tt.wantErr)
+		}
+	}
+}
+
+func TestLocalScorerLowSampleRate(t *testing.T) {
+	_, err := LocalScorer{}.Score(context.Background(), &ScoreRequest{
+		Audio:         EncodeWAV(tone(50, 4)),
+		Format:        "wav",
+		ReferenceText: "Hello there",
+	})
+	if !errors.Is(err, ErrUnsupportedFormat) {
+		t.Fatalf("Score(50 Hz) err = %v, want ErrUnsupportedFormat", err)
+	}
+}
+
+func TestAnalyzeSegments(t *testing.T) {
+	a, err := Analyze(&Audio{Samples: tone(16000, 2).Channels[0], SampleRate: 16000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Segments) != 1 {
+		t.Fatalf("segments = %+v, want 1", a.Segments)
+	}
+	if s := a.Segments[0]; math.Abs(s.Start-0.5) > 0.05 || math.Abs(s.End-1.5) > 0.05 {
+		t.Errorf("segment = %+v, want about [0.5, 1.5]", s)
+	}
+}
@@ -0,0 +1,200 @@
+package speech
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// WAV 格式编码
+const (
+	wavFormatPCM        = 1
+	wavFormatFloat      = 3
+	wavFormatExtensible = 0xFFFE
+)
+
+// minSampleRate 支持的最低采样率，低于电话音质的录音无法分析
+const minSampleRate = 8000
+
+// Audio 单声道浮点采样
+type Audio struct {
+	Samples    []float64 // 取值范围 [-1, 1]
+	SampleRate int
+}
+
+// Duration 音频时长（秒）
+func (a *Audio) Duration() float64 {
+	if a.SampleRate == 0 {
+		return 0
+	}
+	return float64(len(a.Samples)) / float64(a.SampleRate)
+}
+
+// PCM 多声道浮点采样
+type PCM struct {
+	Channels   [][]float64 // 每个声道一组采样，取值范围 [-1, 1]
+	SampleRate int
+}
+
+// Frames 每个声道的采样数
+func (p *PCM) Frames() int {
+	if len(p.Channels) == 0 {
+		return 0
+	}
+	return len(p.Channels[0])
+}
+
+// Duration 音频时长（秒）
+func (p *PCM) Duration() float64 {
+	if p.SampleRate == 0 {
+		return 0
+	}
+	return float64(p.Frames()) / float64(p.SampleRate)
+}
+
+// DecodeWAV 解码 WAV 文件
+// 支持 8/16/24/32 位整数 PCM 和 32 位浮点，多声道取平均
+func DecodeWAV(data []byte) (*Audio, error) {
+	pcm, err := DecodeWAVChannels(data)
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]float64, pcm.Frames())
+	for _, ch := range pcm.Channels {
+		for i, v := range ch {
+			samples[i] += v
+		}
+	}
+	for i := range samples {
+		samples[i] /= float64(len(pcm.Channels))
+	}
+	return &Audio{Samples: samples, SampleRate: pcm.SampleRate}, nil
+}
+
+// DecodeWAVChannels 解码 WAV 文件并保留各声道
+func DecodeWAVChannels(data []byte) (*PCM, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, ErrUnsupportedFormat
+	}
+
+	var (
+		format, channels, bits uint16
+		sampleRate             uint32
+		pcm                    []byte
+		haveFmt                bool
+	)
+	for off := 12; off+8 <= len(data); {
+		id := string(data[off : off+4])
+		size := int(binary.LittleEndian.Uint32(data[off+4 : off+8]))
+		body := data[off+8:]
+		if size > len(body) {
+			// 录音中断时 data 块长度可能未回填，按实际长度读取
+			size = len(body)
+		}
+		body = body[:size]
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, fmt.Errorf("wav: fmt chunk too short")
+			}
+			format = binary.LittleEndian.Uint16(body[0:2])
+			channels = binary.LittleEndian.Uint16(body[2:4])
+			sampleRate = binary.LittleEndian.Uint32(body[4:8])
+			bits = binary.LittleEndian.Uint16(body[14:16])
+			if format == wavFormatExtensible && size >= 26 {
+				format = binary.LittleEndian.Uint16(body[24:26])
+			}
+			haveFmt = true
+		case "data":
+			pcm = body
+		}
+
+		off += 8 + size + size%2
+	}
+	if !haveFmt || pcm == nil {
+		return nil, fmt.Errorf("wav: missing fmt or data chunk")
+	}
+	if channels == 0 || sampleRate == 0 {
+		return nil, fmt.Errorf("wav: invalid header")
+	}
+	if sampleRate < minSampleRate {
+		return nil, fmt.Errorf("%w: 采样率 %d Hz 低于 %d Hz", ErrUnsupportedFormat, sampleRate, minSampleRate)
+	}
+
+	bytesPerSample := int(bits / 8)
+	decode, err := sampleDecoder(format, bits)
+	if err != nil {
+		return nil, err
+	}
+
+	frameSize := bytesPerSample * int(channels)
+	frames := len(pcm) / frameSize
+	out := &PCM{Channels: make([][]float64, channels), SampleRate: int(sampleRate)}
+	for ch := range out.Channels {
+		out.Channels[ch] = make([]float64, frames)
+	}
+	for i := 0; i < frames; i++ {
+		for ch := 0; ch < int(channels); ch++ {
+			pos := i*frameSize + ch*bytesPerSample
+			out.Channels[ch][i] = decode(pcm[pos : pos+bytesPerSample])
+		}
+	}
+	return out, nil
+}
+
+// EncodeWAV 编码为 16 位整数 PCM 的 WAV 文件
+func EncodeWAV(p *PCM) []byte {
+	channels, frames := len(p.Channels), p.Frames()
+	dataSize := frames * channels * 2
+	buf := make([]byte, 44+dataSize)
+	copy(buf[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(buf[4:8], uint32(36+dataSize))
+	copy(buf[8:12], "WAVE")
+	copy(buf[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(buf[16:20], 16)
+	binary.LittleEndian.PutUint16(buf[20:22], wavFormatPCM)
+	binary.LittleEndian.PutUint16(buf[22:24], uint16(channels))
+	binary.LittleEndian.PutUint32(buf[24:28], uint32(p.SampleRate))
+	binary.LittleEndian.PutUint32(buf[28:32], uint32(p.SampleRate*channels*2))
+	binary.LittleEndian.PutUint16(buf[32:34], uint16(channels*2))
+	binary.LittleEndian.PutUint16(buf[34:36], 16)
+	copy(buf[36:40], "data")
+	binary.LittleEndian.PutUint32(buf[40:44], uint32(dataSize))
+
+	pos := 44
+	for i := 0; i < frames; i++ {
+		for ch := 0; ch < channels; ch++ {
+			v := math.Round(math.Max(-1, math.Min(1, p.Channels[ch][i])) * 32767)
+			binary.LittleEndian.PutUint16(buf[pos:pos+2], uint16(int16(v)))
+			pos += 2
+		}
+	}
+	return buf
+}
+
+// sampleDecoder 返回单个采样的解码函数
+func sampleDecoder(format, bits uint16) (func([]byte) float64, error) {
+	switch {
+	case format == wavFormatPCM && bits == 8:
+		return func(b []byte) float64 { return (float64(b[0]) - 128) / 128 }, nil
+	case format == wavFormatPCM && bits == 16:
+		return func(b []byte) float64 {
+			return float64(int16(binary.LittleEndian.Uint16(b))) / 32768
+		}, nil
+	case format == wavFormatPCM && bits == 24:
+		return func(b []byte) float64 {
+			v := int32(b[0]) | int32(b[1])<<8 | int32(int8(b[2]))<<16
+			return float64(v) / 8388608
+		}, nil
+	case format == wavFormatPCM && bits == 32:
+		return func(b []byte) float64 {
+			return float64(int32(binary.LittleEndian.Uint32(b))) / 2147483648
+		}, nil
+	case format == wavFormatFloat && bits == 32:
+		return func(b []byte) float64 {
+			return float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: wav format %d, %d bits", ErrUnsupportedFormat, format, bits)
+}
@@ -0,0 +1,150 @@
+package speech
+
+import "math"
+
+const (
+	// wsolaFrame WSOLA 分析帧长（秒），约为两个基音周期以上
+	wsolaFrame = 0.040
+	// wsolaTolerance 每帧允许偏离名义位置的最大距离（秒）
+	wsolaTolerance = 0.010
+	// wsolaSearchRate 相似度搜索时的等效采样率，降采样比较以减少计算量
+	wsolaSearchRate = 8000
+	// MinSpeed / MaxSpeed 支持的变速范围
+	MinSpeed = 0.5
+	MaxSpeed = 2.0
+)
+
+// TimeStretch 使用 WSOLA（波形相似叠加）变速不变调
+// speed > 1 加快、< 1 放慢，输出时长约为原时长 / speed。
+// 各声道共用同一组帧位置，保证声道间同步；相似度在混合后的单声道上计算
+func TimeStretch(p *PCM, speed float64) *PCM {
+	speed = math.Max(MinSpeed, math.Min(MaxSpeed, speed))
+	frames := p.Frames()
+	frame := int(wsolaFrame * float64(p.SampleRate))
+	if speed == 1 || frames < 2*frame || frame < 4 {
+		return copyPCM(p)
+	}
+
+	hop := frame / 2 // 合成帧移，汉宁窗 50% 重叠叠加后增益恒定
+	tolerance := int(wsolaTolerance * float64(p.SampleRate))
+	step := max(1, p.SampleRate/wsolaSearchRate)
+	window := hann(frame)
+	mono := mixdown(p)
+
+	outFrames := int(float64(frames) / speed)
+	out := &PCM{Channels: make([][]float64, len(p.Channels)), SampleRate: p.SampleRate}
+	for ch := range out.Channels {
+		out.Channels[ch] = make([]float64, outFrames+frame)
+	}
+	gain := make([]float64, outFrames+frame)
+
+	prev, end := 0, 0 // 上一帧在原音频中的起点；已写入的输出长度
+	for k := 0; k*hop < outFrames; k++ {
+		pos := 0
+		if k > 0 {
+			// 在名义位置附近寻找与上一帧自然延续最相似的片段，使叠加处相位连续
+			nominal := int(math.Round(float64(k*hop) * speed))
+			pos = bestOffset(mono, prev+hop, nominal, tolerance, frame, step)
+		}
+		if pos+frame > frames {
+			break
+		}
+		at := k * hop
+		for ch, src := range p.Channels {
+			dst := out.Channels[ch]
+			for i := 0; i < frame; i++ {
+				dst[at+i] += src[pos+i] * window[i]
+			}
+		}
+		for i := 0; i < frame; i++ {
+			gain[at+i] += window[i]
+		}
+		prev, end = pos, at+frame
+	}
+	outFrames = min(outFrames, end)
+
+	// 按窗函数叠加增益归一化，首尾只有单帧覆盖的部分也能保持原音量
+	for i := range gain {
+		if gain[i] < 1e-3 {
+			gain[i] = 1
+		}
+	}
+	for ch := range out.Channels {
+		dst := out.Channels[ch][:outFrames]
+		for i := range dst {
+			dst[i] /= gain[i]
+		}
+		out.Channels[ch] = dst
+	}
+	return out
+}
+
+// bestOffset 在 [nominal-tolerance, nominal+tolerance] 内寻找与 target 处片段互相关最大的起点
+// 先按 step 粗搜，再在最优点附近逐点细搜
+func bestOffset(x []float64, target, nominal, tolerance, frame, step int) int {
+	lo := max(0, nominal-tolerance)
+	hi := min(len(x)-frame, nominal+tolerance)
+	if target+frame > len(x) || hi < lo {
+		return min(max(nominal, 0), max(len(x)-frame, 0))
+	}
+
+	best, bestScore := lo, math.Inf(-1)
+	for pos := lo; pos <= hi; pos += step {
+		if score := correlate(x, target, pos, frame, step); score > bestScore {
+			best, bestScore = pos, score
+		}
+	}
+	if step > 1 {
+		coarse := best
+		for pos := max(lo, coarse-step+1); pos <= min(hi, coarse+step-1); pos++ {
+			if score := correlate(x, target, pos, frame, 1); score > bestScore {
+				best, bestScore = pos, score
+			}
+		}
+	}
+	return best
+}
+
+// correlate 两段等长片段的互相关，每隔 step 个采样取一点
+func correlate(x []float64, a, b, n, step int) float64 {
+	var sum float64
+	for i := 0; i < n; i += step {
+		sum += x[a+i] * x[b+i]
+	}
+	return sum
+}
+
+// hann 周期汉宁窗
+func hann(n int) []float64 {
+	w := make([]float64, n)
+	for i := range w {
+		w[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(n))
+	}
+	return w
+}
+
+// mixdown 多声道取平均混为单声道
+func mixdown(p *PCM) []float64 {
+	if len(p.Channels) == 1 {
+		return p.Channels[0]
+	}
+	mono := make([]float64, p.Frames())
+	for _, ch := range p.Channels {
+		for i, v := range ch {
+			mono[i] += v
+		}
+	}
+	for i := range mono {
+		mono[i] /= float64(len(p.Channels))
+	}
+	return mono
+}
+
+// copyPCM 复制音频
+func copyPCM(p *PCM) *PCM {
+	out := &PCM{Channels: make([][]float64, len(p.Channels)), SampleRate: p.SampleRate}
+	for ch, src := range p.Channels {
+		out.Channels[ch] = append([]float64(nil), src...)
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+package speech
+
+import (
+	"math"
+	"testing"
+)
+
+// sine 生成立体声正弦波，右声道音量为左声道的一半
+func sine(sampleRate int, freq, seconds float64) *PCM {
+	n := int(float64(sampleRate) * seconds)
+	left, right := make([]float64, n), make([]float64, n)
+	for i := range left {
+		left[i] = 0.5 * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate))
+		right[i] = left[i] / 2
+	}
+	return &PCM{Channels: [][]float64{left, right}, SampleRate: sampleRate}
+}
+
+// zeroCrossingRate 每秒过零次数，正弦波为频率的两倍
+func zeroCrossingRate(x []float64, sampleRate int) float64 {
+	n := 0
+	for i := 1; i < len(x); i++ {
+		if (x[i-1] < 0) != (x[i] < 0) {
+			n++
+		}
+	}
+	return float64(n) * float64(sampleRate) / float64(len(x)-1)
+}
+
+// rms 均方根
+func rms(x []float64) float64 {
+	var sum float64
+	for _, v := range x {
+		sum += v * v
+	}
+	return math.Sqrt(sum / float64(len(x)))
+}
+
+func TestTimeStretch(t *testing.T) {
+	const sampleRate, freq = 16000, 220.0
+	in := sine(sampleRate, freq, 2)
+	tests := []struct {
+		name  string
+		speed float64
+		want  float64 // 实际生效的倍速
+	}{
+		{"slow", 0.75, 0.75},
+		{"fast", 1.25, 1.25},
+		{"min", 0.5, 0.5},
+		{"max", 2, 2},
+		{"clamped low", 0.1, MinSpeed},
+		{"clamped high", 3, MaxSpeed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := TimeStretch(in, tt.speed)
+			if out.SampleRate != sampleRate || len(out.Channels) != 2 || len(out.Channels[1]) != out.Frames() {
+				t.Fatalf("output shape: rate %d, channels %d", out.SampleRate, len(out.Channels))
+			}
+			// 输出时长约为原时长 / speed，末尾最多少一帧
+			want := float64(in.Frames()) / tt.want
+			frame := wsolaFrame * sampleRate
+			if got := float64(out.Frames()); got > want || got < want-frame {
+				t.Errorf("frames = %v, want about %v", got, want)
+			}
+
+			// 去掉首尾后检查音高和音量不变
+			left, right := out.Channels[0], out.Channels[1]
+			mid := left[len(left)/4 : len(left)*3/4]
+			if got := zeroCrossingRate(mid, sampleRate); math.Abs(got-2*freq) > 2*freq*0.05 {
+				t.Errorf("zero crossing rate = %v, want about %v", got, 2*freq)
+			}
+			if got, want := rms(mid), 0.5/math.Sqrt2; math.Abs(got-want) > want*0.1 {
+				t.Errorf("rms = %v, want about %v", got, want)
+			}
+			// 声道共用帧位置，两声道的比例保持不变
+			for i := range left {
+				if math.IsNaN(left[i]) || math.Abs(left[i]-2*right[i]) > 1e-9 {
+					t.Fatalf("sample %d: left %v right %v", i, left[i], right[i])
+				}
+			}
+		})
+	}
+}
+
+func TestTimeStretchCopies(t *testing.T) {
+	tests := []struct {
+		name  string
+		in    *PCM
+		speed float64
+	}{
+		{"normal speed", sine(16000, 220, 1), 1},
+		{"shorter than two frames", sine(16000, 220, 0.05), 1.25},
+		{"empty", &PCM{Channels: [][]float64{{}}, SampleRate: 16000}, 0.75},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := TimeStretch(tt.in, tt.speed)
+			if out.Frames() != tt.in.Frames() || len(out.Channels) != len(tt.in.Channels) {
+				t.Fatalf("frames = %d, want %d", out.Frames(), tt.in.Frames())
+			}
+			for ch := range out.Channels {
+				for i, v := range out.Channels[ch] {
+					if v != tt.in.Channels[ch][i] {
+						t.Fatalf("channel %d sample %d changed", ch, i)
+					}
+				}
+				if len(out.Channels[ch]) > 0 {
+					out.Channels[ch][0] = 9
+					if tt.in.Channels[ch][0] == 9 {
+						t.Fatal("output shares memory with input")
+					}
+				}
+			}
+		})
+	}
+}
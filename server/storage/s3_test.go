@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"server/config"
+)
+
+const (
+	testAccessKey = "minio-access"
+	testSecretKey = "minio-secret"
+)
+
+func TestS3Sign(t *testing.T) {
+	s, err := NewS3(config.S3Config{
+		Endpoint: "http://minio.local:9000", Bucket: "kky", PathStyle: true,
+		AccessKey: testAccessKey, SecretKey: testSecretKey,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := s.newRequest(context.Background(), http.MethodPut, "recordings/1/a b.wav", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "audio/wav")
+	s.sign(req, unsignedPayload, time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	if got := req.URL.EscapedPath(); got != "/kky/recordings/1/a%20b.wav" {
+		t.Errorf("path = %s", got)
+	}
+	// 期望值由独立的 SigV4 实现计算
+	want := "AWS4-HMAC-SHA256 Credential=minio-access/20260102/us-east-1/s3/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date, " +
+		"Signature=995942b3e289c3a87dd4036dd7bdb242df1f5e81e7032c3b30e212dccc3010f3"
+	if got := req.Header.Get("Authorization"); got != want {
+		t.Errorf("Authorization =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestS3VirtualHost(t *testing.T) {
+	s, err := NewS3(config.S3Config{
+		Endpoint: "https://s3.example.com", Bucket: "kky", AccessKey: testAccessKey, SecretKey: testSecretKey,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := s.newRequest(context.Background(), http.MethodGet, "avatars/1/64.jpg", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := req.URL.String(); got != "https://kky.s3.example.com/avatars/1/64.jpg" {
+		t.Errorf("url = %s", got)
+	}
+}
+
+func TestS3PutOpenDelete(t *testing.T) {
+	srv := newFakeS3(t)
+	s, err := NewS3(config.S3Config{
+		Endpoint: srv.URL, Region: "cn-north-1", Bucket: "kky", PathStyle: true,
+		AccessKey: testAccessKey, SecretKey: testSecretKey,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	key := "recordings/1/2026-10/a b+c.wav"
+	data := []byte("RIFF....WAVE")
+
+	if err := s.Put(ctx, key, bytes.NewReader(data), int64(len(data)), "audio/wav"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	obj, err := s.Open(ctx, key)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	got, _ := io.ReadAll(obj.Body)
+	obj.Body.Close()
+	if !bytes.Equal(got, data) || obj.Size != int64(len(data)) || obj.ContentType != "audio/wav" {
+		t.Errorf("object = %q size %d type %q", got, obj.Size, obj.ContentType)
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := s.Open(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open after delete err = %v, want ErrNotFound", err)
+	}
+	// 删除不存在的对象不报错
+	if err := s.Delete(ctx, key); err != nil {
+		t.Errorf("Delete missing: %v", err)
+	}
+
+	bad, _ := NewS3(config.S3Config{
+		Endpoint: srv.URL, Region: "cn-north-1", Bucket: "kky", PathStyle: true,
+		AccessKey: testAccessKey, SecretKey: "wrong",
+	})
+	err = bad.Put(ctx, key, bytes.NewReader(data), int64(len(data)), "audio/wav")
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Errorf("Put with wrong secret err = %v, want 403", err)
+	}
+}
+
+// authPattern 解析 Authorization 请求头
+var authPattern = regexp.MustCompile(`^AWS4-HMAC-SHA256 Credential=([^/]+)/(\d{8})/([^/]+)/s3/aws4_request, SignedHeaders=([^,]+), Signature=([0-9a-f]{64})$`)
+
+// newFakeS3 启动内存中的 S3 服务，校验每个请求的 SigV4 签名
+func newFakeS3(t *testing.T) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	objects := map[string][]byte{}
+	types := map[string]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !verifySignature(r) {
+			w.WriteHeader(http.StatusForbidden)
+			io.WriteString(w, "<Error><Code>SignatureDoesNotMatch</Code></Error>")
+			return
+		}
+		path := r.URL.Path
+		if !strings.HasPrefix(path, "/kky/") {
+			t.Errorf("path %s not in bucket", path)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPut:
+			body, _ := io.ReadAll(r.Body)
+			objects[path], types[path] = body, r.Header.Get("Content-Type")
+		case http.MethodGet:
+			body, ok := objects[path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", types[path])
+			w.Write(body)
+		case http.MethodDelete:
+			delete(objects, path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// verifySignature 按收到的请求重新计算签名
+func verifySignature(r *http.Request) bool {
+	m := authPattern.FindStringSubmatch(r.Header.Get("Authorization"))
+	if m == nil || m[1] != testAccessKey {
+		return false
+	}
+	date, region, signed := m[2], m[3], strings.Split(m[4], ";")
+	amzDate := r.Header.Get("X-Amz-Date")
+	if !strings.HasPrefix(amzDate, date) {
+		return false
+	}
+	if !sort.StringsAreSorted(signed) {
+		return false
+	}
+	var headers strings.Builder
+	for _, name := range signed {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		headers.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	canonical := strings.Join([]string{
+		r.Method, r.URL.EscapedPath(), r.URL.RawQuery, headers.String(), m[4], r.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+	digest := sha256.Sum256([]byte(canonical))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + date + "/" + region + "/s3/aws4_request\n" + hex.EncodeToString(digest[:])
+
+	mac := func(key []byte, data string) []byte {
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte(data))
+		return h.Sum(nil)
+	}
+	key := mac([]byte("AWS4"+testSecretKey), date)
+	key = mac(key, region)
+	key = mac(key, "s3")
+	key = mac(key, "aws4_request")
+	return hex.EncodeToString(mac(key, stringToSign)) == m[5]
+}
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"server/config"
+)
+
+// ErrNotFound 对象不存在
+var ErrNotFound = errors.New("object not found")
+
+// Object 读取到的对象
+type Object struct {
+	Body        io.ReadCloser
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Storage 对象存储
+// 录音、头像等用户文件通过该接口读写，key 使用 / 分隔的相对路径
+type Storage interface {
+	// Put 写入对象，已存在时覆盖
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Open 读取对象，不存在时返回 ErrNotFound
+	Open(ctx context.Context, key string) (*Object, error)
+	// Delete 删除对象，对象不存在时不报错
+	Delete(ctx context.Context, key string) error
+}
+
+// store 全局存储实例
+var store Storage
+
+// Init 根据配置初始化存储后端
+func Init(cfg config.StorageConfig) error {
+	s, err := New(cfg)
+	if err != nil {
+		return err
+	}
+	store = s
+	return nil
+}
+
+// Get 获取全局存储实例
+func Get() Storage {
+	return store
+}
+
+// New 创建存储后端
+func New(cfg config.StorageConfig) (Storage, error) {
+	switch cfg.Backend {
+	case "", "local":
+		return NewLocal(cfg.LocalDir)
+	case "s3":
+		return NewS3(cfg.S3)
+	}
+	return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+}
+
+// cleanKey 校验并规范化对象 key，拒绝绝对路径和 ..
+func cleanKey(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	cleaned := path.Clean(key)
+	if cleaned != key || cleaned == "." || strings.HasPrefix(cleaned, "../") || cleaned == ".." {
+		return "", fmt.Errorf("invalid object key %q", key)
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,81 @@
+package study
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata" // 内置时区数据库，避免依赖部署环境的 zoneinfo
+
+	"gorm.io/gorm"
+	"server/config"
+	"server/models"
+)
+
+// Calendar 用户的学习日历
+// 学习日按用户时区计算，并在切换时刻（如凌晨4点）而非午夜进入新的一天
+type Calendar struct {
+	Location        *time.Location
+	RolloverHour    int
+	FreezesPerMonth int // 每月可用的连续学习保护次数，0 表示未开启
+}
+
+// CalendarFor 根据用户设置和服务端默认配置构造学习日历
+// 用户时区无效时回退到默认时区
+func CalendarFor(user *models.User) Calendar {
+	cfg := config.Get().Study
+
+	loc, err := LoadTimezone(user.Timezone)
+	if err != nil || user.Timezone == "" {
+		loc, err = LoadTimezone(cfg.DefaultTimezone)
+		if err != nil {
+			loc = time.Local
+		}
+	}
+
+	hour := cfg.DayRolloverHour
+	if user.DayRolloverHour != nil {
+		hour = *user.DayRolloverHour
+	}
+	if hour < 0 || hour > 23 {
+		hour = 0
+	}
+
+	cal := Calendar{Location: loc, RolloverHour: hour}
+	if user.StreakFreeze {
+		cal.FreezesPerMonth = cfg.StreakFreezesPerMonth
+	}
+	return cal
+}
+
+// CalendarForUser 读取用户设置并构造学习日历
+func CalendarForUser(db *gorm.DB, userID uint) (Calendar, error) {
+	var user models.User
+	if err := db.First(&user, userID).Error; err != nil {
+		return Calendar{}, err
+	}
+	return CalendarFor(&user), nil
+}
+
+// LoadTimezone 解析IANA时区名
+// 拒绝 "Local" 等依赖服务器环境的名称
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("invalid timezone %q", name)
+	}
+	return time.LoadLocation(name)
+}
+
+// Day 返回时间所属的学习日 (YYYY-MM-DD)
+func (c Calendar) Day(t time.Time) string {
+	return c.shift(t).Format(dayLayout)
+}
+
+// StartOfDay 返回时间所属学习日的开始时刻
+func (c Calendar) StartOfDay(t time.Time) time.Time {
+	y, m, d := c.shift(t).Date()
+	return time.Date(y, m, d, c.RolloverHour, 0, 0, 0, c.Location)
+}
+
+// shift 转换到用户时区并减去切换时刻，使切换时刻之前的时间计入前一天
+func (c Calendar) shift(t time.Time) time.Time {
+	return t.In(c.Location).Add(-time.Duration(c.RolloverHour) * time.Hour)
+}
@@ -0,0 +1,240 @@
+package study
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"server/models"
+)
+
+const (
+	// MaxBackdate 事件时间最多早于服务器时间的时长，用于离线同步
+	MaxBackdate = 72 * time.Hour
+	// maxClockSkew 允许客户端时钟超前的误差，超出部分按服务器时间记录
+	maxClockSkew = 5 * time.Minute
+	// dayLayout 学习日格式
+	dayLayout = "2006-01-02"
+	// monthLayout 保护次数按月计算
+	monthLayout = "2006-01"
+)
+
+// serverOnlyTypes 只由服务端记录的事件类型，如复习单词时记录的 word_learned，客户端提交会重复计数
+var serverOnlyTypes = map[string]bool{
+	models.StudyEventWordLearned: true,
+}
+
+// serverIDPrefix 服务端生成的事件ID前缀
+// 事件按 (user_id, event_id) 去重，客户端使用该前缀会抢占服务端事件的ID，使其被当作重复事件忽略
+const serverIDPrefix = "vocab:"
+
+// maxAmount 每种事件单次允许的最大数量
+var maxAmount = map[string]int{
+	models.StudyEventWordLearned:       200,
+	models.StudyEventListeningMinutes:  240,
+	models.StudyEventSpeakingMinutes:   240,
+	models.StudyEventExerciseCompleted: 50,
+}
+
+// 事件校验错误
+var (
+	ErrInvalidEventID = errors.New("事件ID无效")
+	ErrInvalidType    = errors.New("事件类型无效")
+	ErrInvalidAmount  = errors.New("事件数量无效")
+	ErrTooOld         = errors.New("事件时间过早")
+	ErrServerOnly     = errors.New("该事件由服务端记录，不能由客户端提交")
+	ErrReservedID     = errors.New("事件ID使用了服务端保留的前缀")
+)
+
+// Event 待记录的学习事件
+type Event struct {
+	EventID    string
+	Type       string
+	Amount     int
+	RefID      string
+	OccurredAt time.Time
+}
+
+// Normalize 校验事件并补全默认值
+// Amount 为0时按1计；未提供时间或时间超前时使用服务器时间
+func (e *Event) Normalize(now time.Time) error {
+	if e.EventID == "" || len(e.EventID) > 64 {
+		return ErrInvalidEventID
+	}
+	max, ok := maxAmount[e.Type]
+	if !ok {
+		return ErrInvalidType
+	}
+	if e.Amount == 0 {
+		e.Amount = 1
+	}
+	if e.Amount < 0 || e.Amount > max {
+		return fmt.Errorf("%w: 应在1-%d之间", ErrInvalidAmount, max)
+	}
+	if len(e.RefID) > 64 {
+		e.RefID = e.RefID[:64]
+	}
+	if e.OccurredAt.IsZero() || e.OccurredAt.After(now.Add(maxClockSkew)) {
+		e.OccurredAt = now
+	}
+	if e.OccurredAt.Before(now.Add(-MaxBackdate)) {
+		return ErrTooOld
+	}
+	return nil
+}
+
+// CheckClientEvent 校验客户端可以提交的事件：不能是服务端记录的类型，也不能使用服务端的ID前缀
+func CheckClientEvent(e *Event) error {
+	if serverOnlyTypes[e.Type] {
+		return ErrServerOnly
+	}
+	if strings.HasPrefix(e.EventID, serverIDPrefix) {
+		return ErrReservedID
+	}
+	return nil
+}
+
+// WordLearnedEventID 首次学习单词时记录的事件ID，以单词ID去重
+func WordLearnedEventID(wordID uint) string {
+	return fmt.Sprintf("%sword:%d", serverIDPrefix, wordID)
+}
+
+// Record 写入学习事件
+// 学习日按写入时的用户日历确定，之后修改时区不会改变已记录事件的归属
+// 返回 false 表示该事件ID已记录过
+func Record(tx *gorm.DB, userID uint, cal Calendar, e Event) (bool, error) {
+	record := models.StudyEvent{
+		UserID:     userID,
+		EventID:    e.EventID,
+		Type:       e.Type,
+		Amount:     e.Amount,
+		RefID:      e.RefID,
+		OccurredAt: e.OccurredAt,
+		Day:        cal.Day(e.OccurredAt),
+	}
+	result := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "event_id"}},
+		DoNothing: true,
+	}).Create(&record)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// RecomputeStats 根据学习事件重新汇总用户统计并写回用户表
+func RecomputeStats(tx *gorm.DB, userID uint, now time.Time) (models.UserStats, error) {
+	var stats models.UserStats
+
+	var user models.User
+	if err := tx.First(&user, userID).Error; err != nil {
+		return stats, err
+	}
+	cal := CalendarFor(&user)
+
+	var totals []struct {
+		Type  string
+		Total int
+	}
+	if err := tx.Model(&models.StudyEvent{}).
+		Select("type, SUM(amount) AS total").
+		Where("user_id = ?", userID).
+		Group("type").
+		Scan(&totals).Error; err != nil {
+		return stats, err
+	}
+	for _, t := range totals {
+		switch t.Type {
+		case models.StudyEventWordLearned:
+			stats.TotalWordsLearned = t.Total
+		case models.StudyEventListeningMinutes:
+			stats.TotalListeningMinutes = t.Total
+		case models.StudyEventSpeakingMinutes:
+			stats.TotalSpeakingMinutes = t.Total
+		}
+	}
+
+	var days []string
+	if err := tx.Model(&models.StudyEvent{}).
+		Distinct("day").
+		Where("user_id = ?", userID).
+		Order("day DESC").
+		Pluck("day", &days).Error; err != nil {
+		return stats, err
+	}
+	stats.TotalStudyDays = len(days)
+	stats.CurrentStreak, stats.StreakFreezesLeft = currentStreak(days, cal.Day(now), cal.FreezesPerMonth)
+
+	var last models.StudyEvent
+	err := tx.Where("user_id = ?", userID).Order("occurred_at DESC").First(&last).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return stats, err
+	}
+	if err == nil {
+		t := last.OccurredAt
+		stats.LastStudyDate = &t
+	}
+
+	err = tx.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"stats_total_study_days":        stats.TotalStudyDays,
+		"stats_current_streak":          stats.CurrentStreak,
+		"stats_total_words_learned":     stats.TotalWordsLearned,
+		"stats_total_listening_minutes": stats.TotalListeningMinutes,
+		"stats_total_speaking_minutes":  stats.TotalSpeakingMinutes,
+		"stats_last_study_date":         stats.LastStudyDate,
+		"stats_streak_freezes_left":     stats.StreakFreezesLeft,
+	}).Error
+	return stats, err
+}
+
+// currentStreak 计算截至今天的连续学习天数
+// days 为按降序排列的学习日；今天尚未学习时从昨天开始计算，不中断连续记录。
+// freezes 为每月可跳过的天数，只有被后续学习日接上的空缺才会消耗保护次数。
+// 返回连续学习天数（不含跳过的天数）和本月剩余保护次数
+func currentStreak(days []string, today string, freezes int) (int, int) {
+	expected, err := time.Parse(dayLayout, today)
+	if err != nil {
+		return 0, freezes
+	}
+	thisMonth := expected.Format(monthLayout)
+	if len(days) == 0 {
+		return 0, freezes
+	}
+	if days[0] < today {
+		expected = expected.AddDate(0, 0, -1)
+	}
+
+	used := map[string]int{}
+	var gap map[string]int
+	streak := 0
+	for i := 0; i < len(days); {
+		day := expected.Format(dayLayout)
+		switch {
+		case days[i] > day:
+			// 时钟误差导致的未来学习日，忽略
+			i++
+		case days[i] == day:
+			for month, n := range gap {
+				used[month] += n
+			}
+			gap = nil
+			streak++
+			i++
+			expected = expected.AddDate(0, 0, -1)
+		default:
+			month := expected.Format(monthLayout)
+			if used[month]+gap[month] >= freezes {
+				return streak, freezes - used[thisMonth]
+			}
+			if gap == nil {
+				gap = map[string]int{}
+			}
+			gap[month]++
+			expected = expected.AddDate(0, 0, -1)
+		}
+	}
+	return streak, freezes - used[thisMonth]
+}
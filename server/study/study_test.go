@@ -0,0 +1,60 @@
+package study
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"server/models"
+)
+
+func TestCheckClientEvent(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   Event
+		wantErr error
+	}{
+		{"listening minutes", Event{EventID: "c1b2", Type: models.StudyEventListeningMinutes}, nil},
+		{"server only type", Event{EventID: "c1b3", Type: models.StudyEventWordLearned}, ErrServerOnly},
+		{"server id prefix", Event{EventID: WordLearnedEventID(123), Type: models.StudyEventListeningMinutes}, ErrReservedID},
+		{"prefix inside id", Event{EventID: "app:vocab:1", Type: models.StudyEventExerciseCompleted}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckClientEvent(&tt.event); !errors.Is(err, tt.wantErr) {
+				t.Errorf("CheckClientEvent(%+v) = %v, want %v", tt.event, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name       string
+		event      Event
+		wantErr    error
+		wantAt     time.Time
+		wantAmount int
+	}{
+		{"default amount and time", Event{EventID: "a", Type: models.StudyEventExerciseCompleted}, nil, now, 1},
+		{"backdated", Event{EventID: "a", Type: models.StudyEventListeningMinutes, Amount: 5, OccurredAt: now.Add(-time.Hour)}, nil, now.Add(-time.Hour), 5},
+		{"future clamped", Event{EventID: "a", Type: models.StudyEventListeningMinutes, OccurredAt: now.Add(time.Hour)}, nil, now, 1},
+		{"too old", Event{EventID: "a", Type: models.StudyEventListeningMinutes, OccurredAt: now.Add(-MaxBackdate - time.Minute)}, ErrTooOld, time.Time{}, 0},
+		{"unknown type", Event{EventID: "a", Type: "sleep_minutes"}, ErrInvalidType, time.Time{}, 0},
+		{"amount too large", Event{EventID: "a", Type: models.StudyEventListeningMinutes, Amount: 241}, ErrInvalidAmount, time.Time{}, 0},
+		{"missing id", Event{Type: models.StudyEventListeningMinutes}, ErrInvalidEventID, time.Time{}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := tt.event
+			err := e.Normalize(now)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Normalize() = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (!e.OccurredAt.Equal(tt.wantAt) || e.Amount != tt.wantAmount) {
+				t.Errorf("event = %+v, want time %v amount %d", e, tt.wantAt, tt.wantAmount)
+			}
+		})
+	}
+}
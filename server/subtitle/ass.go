@@ -0,0 +1,88 @@
+package subtitle
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// defaultASSFields [Events] 段未声明 Format 时使用的字段顺序
+var defaultASSFields = []string{"layer", "start", "end", "style", "name", "marginl", "marginr", "marginv", "effect", "text"}
+
+// assOverridePattern 样式覆盖代码，如 {\an8}、{\fad(200,200)}
+var assOverridePattern = regexp.MustCompile(`\{[^}]*\}`)
+
+// parseASS 解析 ASS/SSA 字幕
+// 只读取 [Events] 段的 Dialogue 行；同一时间轴的中英文可以在一行内用 \N 分隔，也可以分成两行
+func parseASS(lines []string) (*Track, error) {
+	t := &Track{}
+	section := ""
+	fields := defaultASSFields
+	hasEvents := false
+	for i, raw := range lines {
+		line := strings.TrimSpace(raw)
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(line)
+			hasEvents = hasEvents || section == "[events]"
+			continue
+		}
+		if section != "[events]" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "Format":
+			fields = fields[:0:0]
+			for _, f := range strings.Split(value, ",") {
+				fields = append(fields, strings.ToLower(strings.TrimSpace(f)))
+			}
+			if indexOf(fields, "start") < 0 || indexOf(fields, "end") < 0 || indexOf(fields, "text") != len(fields)-1 {
+				t.addIssue(i+1, issueError, "Format 行缺少 Start/End 字段或 Text 不是最后一个字段")
+				return t, nil
+			}
+		case "Dialogue":
+			t.parseDialogue(i+1, value, fields)
+		}
+	}
+	if !hasEvents {
+		return nil, errors.New("ASS 文件缺少 [Events] 段")
+	}
+	return t, nil
+}
+
+// parseDialogue 解析一行 Dialogue
+func (t *Track) parseDialogue(line int, value string, fields []string) {
+	values := strings.SplitN(value, ",", len(fields))
+	if len(values) < len(fields) {
+		t.addIssue(line, issueError, "Dialogue 字段数不足，已跳过")
+		return
+	}
+	start, ok := parseClock(values[indexOf(fields, "start")])
+	if !ok {
+		t.addIssue(line, issueError, "开始时间格式错误: %q，已跳过", strings.TrimSpace(values[indexOf(fields, "start")]))
+		return
+	}
+	end, ok := parseClock(values[indexOf(fields, "end")])
+	if !ok {
+		t.addIssue(line, issueError, "结束时间格式错误: %q，已跳过", strings.TrimSpace(values[indexOf(fields, "end")]))
+		return
+	}
+
+	text := assOverridePattern.ReplaceAllString(values[len(values)-1], "")
+	text = strings.NewReplacer(`\N`, "\n", `\n`, "\n", `\h`, " ").Replace(text)
+	t.addCue(line, start, end, strings.Split(text, "\n"))
+}
+
+// indexOf 返回字段下标，不存在时返回 -1
+func indexOf(fields []string, name string) int {
+	for i, f := range fields {
+		if f == name {
+			return i
+		}
+	}
+	return -1
+}
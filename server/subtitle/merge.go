@@ -0,0 +1,192 @@
+package subtitle
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Options 句子合并参数
+type Options struct {
+	MaxGap      float64 // 相邻字幕间隔不超过该值（秒）时才可能合并
+	MaxDuration float64 // 合并后句子的最长时长（秒）
+}
+
+// DefaultOptions 默认合并参数
+var DefaultOptions = Options{MaxGap: 1.5, MaxDuration: 15}
+
+// minSentenceDuration 调整重叠后句子的最短时长（秒），更短时与下一句合并
+const minSentenceDuration = 0.3
+
+// Sentence 合并后的句子
+type Sentence struct {
+	Line        int     `json:"line"`       // 首条字幕的行号
+	Start       float64 `json:"start_time"` // 秒
+	End         float64 `json:"end_time"`   // 秒
+	Text        string  `json:"text"`
+	Translation string  `json:"translation"`
+}
+
+// Result 字幕转换结果
+type Result struct {
+	Cues         int        `json:"cues"`         // 英文字幕条数
+	Sentences    []Sentence `json:"sentences"`    // 合并后的句子
+	Untranslated int        `json:"untranslated"` // 没有译文的句子数
+	Issues       []Issue    `json:"issues"`
+	Truncated    bool       `json:"issues_truncated"` // 问题过多时只保留前若干条
+}
+
+// Build 将字幕轨转换为句子
+// 英文字幕按时间排序后配上译文：双语字幕直接使用同一条中的中文，
+// 单独的中文字幕（同一文件中的中文行或 translation 轨）按时间重叠最多的原则配给英文字幕；
+// 随后把未以句末标点结尾的相邻字幕合并为完整的句子，并消除句子间的时间重叠
+func Build(track *Track, translation *Track, opts Options) *Result {
+	r := &Result{Issues: append([]Issue{}, track.Issues...)}
+	if translation != nil {
+		r.Issues = append(r.Issues, translation.Issues...)
+	}
+	if len(r.Issues) > maxIssues {
+		r.Issues = r.Issues[:maxIssues]
+		r.Truncated = true
+	}
+
+	var english, chinese []Cue
+	for _, c := range track.Cues {
+		if c.Text != "" {
+			english = append(english, c)
+		} else {
+			chinese = append(chinese, c)
+		}
+	}
+	// 单独上传的译文轨中只取中文
+	if translation != nil {
+		for _, c := range translation.Cues {
+			if c.Translation == "" {
+				r.addIssue(c.Line, issueWarning, "译文字幕不含中文，已忽略")
+				continue
+			}
+			chinese = append(chinese, Cue{Line: c.Line, Start: c.Start, End: c.End, Translation: c.Translation})
+		}
+	}
+	sort.SliceStable(english, func(i, j int) bool { return english[i].Start < english[j].Start })
+	sort.SliceStable(chinese, func(i, j int) bool { return chinese[i].Start < chinese[j].Start })
+	r.Cues = len(english)
+
+	r.pairTranslations(english, chinese)
+	r.Sentences = mergeCues(english, opts)
+	r.resolveOverlaps()
+	for _, s := range r.Sentences {
+		if s.Translation == "" {
+			r.Untranslated++
+		}
+	}
+	return r
+}
+
+// addIssue 记录问题，超过上限后标记截断
+func (r *Result) addIssue(line int, level, message string) {
+	if len(r.Issues) >= maxIssues {
+		r.Truncated = true
+		return
+	}
+	r.Issues = append(r.Issues, Issue{Line: line, Level: level, Message: message})
+}
+
+// pairTranslations 为没有内嵌译文的英文字幕配上时间重叠最多的中文字幕
+func (r *Result) pairTranslations(english, chinese []Cue) {
+	inline := make([]bool, len(english))
+	for i, c := range english {
+		inline[i] = c.Translation != ""
+	}
+	for _, zh := range chinese {
+		best, bestOverlap := -1, 0.0
+		for i, en := range english {
+			if en.Start >= zh.End {
+				break
+			}
+			if overlap := min(en.End, zh.End) - max(en.Start, zh.Start); overlap > bestOverlap {
+				best, bestOverlap = i, overlap
+			}
+		}
+		switch {
+		case best < 0:
+			r.addIssue(zh.Line, issueWarning, "未找到时间对应的英文字幕，译文已忽略")
+		case inline[best]:
+			// 双语字幕已有译文，不再重复追加
+		default:
+			english[best].Translation = joinText(english[best].Translation, zh.Translation)
+		}
+	}
+}
+
+// mergeCues 将未以句末标点结尾的相邻字幕合并为句子
+func mergeCues(cues []Cue, opts Options) []Sentence {
+	var out []Sentence
+	for _, c := range cues {
+		if n := len(out); n > 0 {
+			cur := &out[n-1]
+			if !endsSentence(cur.Text) && c.Start-cur.End <= opts.MaxGap && c.End-cur.Start <= opts.MaxDuration {
+				cur.End = max(cur.End, c.End)
+				cur.Text = joinText(cur.Text, c.Text)
+				cur.Translation = joinText(cur.Translation, c.Translation)
+				continue
+			}
+		}
+		out = append(out, Sentence{Line: c.Line, Start: c.Start, End: c.End, Text: c.Text, Translation: c.Translation})
+	}
+	return out
+}
+
+// resolveOverlaps 消除句子间的时间重叠
+// 上一句结束晚于下一句开始时截断上一句；截断后过短则与下一句合并
+func (r *Result) resolveOverlaps() {
+	if len(r.Sentences) < 2 {
+		return
+	}
+	out := r.Sentences[:1]
+	for _, s := range r.Sentences[1:] {
+		prev := &out[len(out)-1]
+		if s.Start < prev.End {
+			if s.Start-prev.Start < minSentenceDuration {
+				r.addIssue(s.Line, issueWarning, "与上一句时间几乎完全重叠，已合并为一句")
+				prev.End = max(prev.End, s.End)
+				prev.Text = joinText(prev.Text, s.Text)
+				prev.Translation = joinText(prev.Translation, s.Translation)
+				continue
+			}
+			r.addIssue(prev.Line, issueWarning, "与下一句时间重叠，已提前结束时间")
+			prev.End = s.Start
+		}
+		out = append(out, s)
+	}
+	r.Sentences = out
+}
+
+// endsSentence 判断文本是否以句末标点结尾
+// 省略号通常表示话没说完，不视为句末
+func endsSentence(s string) bool {
+	s = strings.TrimRight(s, "\"'”’)]» ")
+	if s == "" {
+		return true
+	}
+	if strings.HasSuffix(s, "...") || strings.HasSuffix(s, "…") {
+		return false
+	}
+	last, _ := utf8.DecodeLastRuneInString(s)
+	switch last {
+	case '.', '!', '?', '—':
+		return true
+	}
+	return strings.HasSuffix(s, "--")
+}
+
+// joinText 用空格连接两段文本
+func joinText(a, b string) string {
+	switch {
+	case a == "":
+		return b
+	case b == "":
+		return a
+	}
+	return a + " " + b
+}
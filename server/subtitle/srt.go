@@ -0,0 +1,84 @@
+package subtitle
+
+import (
+	"strings"
+)
+
+// parseSRT 解析 SRT 字幕
+// 每条字幕由序号、时间轴和若干行文本组成，字幕之间用空行分隔
+func parseSRT(lines []string) *Track {
+	t := &Track{}
+	for _, b := range splitBlocks(lines) {
+		t.parseCueBlock(b)
+	}
+	return t
+}
+
+// block 以空行分隔的一段文本
+type block struct {
+	line  int // 首行行号，从1开始
+	lines []string
+}
+
+// splitBlocks 按空行切分
+func splitBlocks(lines []string) []block {
+	var blocks []block
+	var cur *block
+	for i, l := range lines {
+		if strings.TrimSpace(l) == "" {
+			cur = nil
+			continue
+		}
+		if cur == nil {
+			blocks = append(blocks, block{line: i + 1})
+			cur = &blocks[len(blocks)-1]
+		}
+		cur.lines = append(cur.lines, l)
+	}
+	return blocks
+}
+
+// parseCueBlock 解析一段 SRT/WebVTT 字幕
+// 首行可以是序号或标识；缺少空行分隔的相邻字幕会按时间轴行拆开
+func (t *Track) parseCueBlock(b block) {
+	i := 0
+	if !strings.Contains(b.lines[0], "-->") {
+		if len(b.lines) < 2 || !strings.Contains(b.lines[1], "-->") {
+			t.addIssue(b.line, issueError, "缺少时间轴，已跳过")
+			return
+		}
+		i = 1
+	}
+
+	for i < len(b.lines) {
+		timingLine := b.line + i
+		start, end, err := parseTiming(b.lines[i])
+		i++
+		var text []string
+		for i < len(b.lines) && !startsCue(b.lines, i) {
+			text = append(text, b.lines[i])
+			i++
+		}
+		if i < len(b.lines) && !strings.Contains(b.lines[i], "-->") {
+			// 下一条字幕的序号
+			i++
+		}
+		if err != nil {
+			t.addIssue(timingLine, issueError, "%s，已跳过", err.Error())
+			continue
+		}
+		t.addCue(timingLine, start, end, text)
+	}
+}
+
+// startsCue 判断第 i 行是否开始一条新字幕（时间轴行，或序号后紧跟时间轴行）
+func startsCue(lines []string, i int) bool {
+	if strings.Contains(lines[i], "-->") {
+		return true
+	}
+	if i+1 < len(lines) && strings.Contains(lines[i+1], "-->") {
+		var n int
+		return parseUint(strings.TrimSpace(lines[i]), &n)
+	}
+	return false
+}
@@ -0,0 +1,272 @@
+package subtitle
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/simplifiedchinese"
+	xunicode "golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// 支持的字幕格式
+const (
+	FormatSRT = "srt"
+	FormatVTT = "vtt"
+	FormatASS = "ass"
+)
+
+// maxIssues 报告中保留的问题条数上限
+const maxIssues = 500
+
+const (
+	issueError   = "error"
+	issueWarning = "warning"
+)
+
+// Cue 一条字幕
+// 双语字幕中英文放在 Text，中文放在 Translation
+type Cue struct {
+	Line        int     // 时间轴所在行号
+	Start       float64 // 开始时间（秒）
+	End         float64 // 结束时间（秒）
+	Text        string  // 英文
+	Translation string  // 中文
+}
+
+// Issue 解析问题
+type Issue struct {
+	Line    int    `json:"line"`
+	Level   string `json:"level"` // error: 字幕被跳过; warning: 字幕已导入但做了调整
+	Message string `json:"message"`
+}
+
+// Track 解析后的字幕轨
+type Track struct {
+	Format string
+	Cues   []Cue
+	Issues []Issue
+}
+
+// addIssue 记录问题，超过上限后丢弃
+func (t *Track) addIssue(line int, level, format string, args ...any) {
+	if len(t.Issues) < maxIssues {
+		t.Issues = append(t.Issues, Issue{Line: line, Level: level, Message: fmt.Sprintf(format, args...)})
+	}
+}
+
+// DetectFormat 根据文件名判断字幕格式
+func DetectFormat(filename string) (string, error) {
+	switch ext := strings.ToLower(filepath.Ext(filename)); ext {
+	case ".srt":
+		return FormatSRT, nil
+	case ".vtt":
+		return FormatVTT, nil
+	case ".ass", ".ssa":
+		return FormatASS, nil
+	default:
+		return "", fmt.Errorf("不支持的字幕类型 %q", ext)
+	}
+}
+
+// Parse 按格式解析字幕文件
+// 自动识别 UTF-8/UTF-16 BOM，非 UTF-8 内容按 GB18030 解码
+func Parse(format string, data []byte) (*Track, error) {
+	text, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i := range lines {
+		lines[i] = strings.TrimRight(lines[i], "\r")
+	}
+
+	var t *Track
+	switch format {
+	case FormatSRT:
+		t = parseSRT(lines)
+	case FormatVTT:
+		t, err = parseVTT(lines)
+	case FormatASS:
+		t, err = parseASS(lines)
+	default:
+		return nil, fmt.Errorf("不支持的字幕格式 %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	t.Format = format
+	return t, nil
+}
+
+// decode 将字幕内容转为 UTF-8
+func decode(data []byte) (string, error) {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xEF, 0xBB, 0xBF}):
+		data = data[3:]
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}), bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		dec := xunicode.UTF16(xunicode.LittleEndian, xunicode.ExpectBOM).NewDecoder()
+		out, _, err := transform.Bytes(dec, data)
+		if err != nil {
+			return "", fmt.Errorf("UTF-16 解码失败: %w", err)
+		}
+		return string(out), nil
+	}
+	if utf8.Valid(data) {
+		return string(data), nil
+	}
+	// 国内字幕组常用 GBK 编码
+	out, _, err := transform.Bytes(simplifiedchinese.GB18030.NewDecoder(), data)
+	if err != nil {
+		return "", fmt.Errorf("无法识别字幕文件编码: %w", err)
+	}
+	return string(out), nil
+}
+
+var (
+	htmlTagPattern = regexp.MustCompile(`<[^>]*>`)
+	soundPattern   = regexp.MustCompile(`\[[^\]]*\]|（[^）]*）|♪|♫`)
+	spacePattern   = regexp.MustCompile(`[ \t\x{00a0}\x{3000}]+`)
+)
+
+// cleanLine 去除格式标签和音效说明，规范空白
+func cleanLine(s string) string {
+	s = htmlTagPattern.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	s = soundPattern.ReplaceAllString(s, "")
+	s = strings.TrimSpace(spacePattern.ReplaceAllString(s, " "))
+	return s
+}
+
+// hasCJK 判断文本是否包含中文
+func hasCJK(s string) bool {
+	for _, r := range s {
+		if unicode.Is(unicode.Han, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasLatin 判断文本是否包含英文字母
+func hasLatin(s string) bool {
+	for _, r := range s {
+		if r < utf8.RuneSelf && unicode.IsLetter(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// splitBilingual 将字幕各行按语言分为英文和中文
+// 含汉字的行视为译文，其余含字母的行视为英文原文
+func splitBilingual(lines []string) (english, chinese string) {
+	var en, zh []string
+	for _, l := range lines {
+		// 对话字幕行首的 "-" 表示换人说话
+		l = strings.TrimSpace(strings.TrimPrefix(cleanLine(l), "-"))
+		switch {
+		case l == "":
+		case hasCJK(l):
+			zh = append(zh, l)
+		case hasLatin(l):
+			en = append(en, l)
+		}
+	}
+	return strings.Join(en, " "), strings.Join(zh, " ")
+}
+
+// addCue 清洗并加入一条字幕，无对白时跳过
+func (t *Track) addCue(line int, start, end float64, textLines []string) {
+	if end <= start {
+		t.addIssue(line, issueError, "结束时间不晚于开始时间，已跳过")
+		return
+	}
+	en, zh := splitBilingual(textLines)
+	if en == "" && zh == "" {
+		t.addIssue(line, issueWarning, "字幕不含对白，已跳过")
+		return
+	}
+	t.Cues = append(t.Cues, Cue{Line: line, Start: start, End: end, Text: en, Translation: zh})
+}
+
+// parseClock 解析 [hh:]mm:ss[.,]fff 形式的时间戳
+func parseClock(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	parts := strings.Split(s, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, false
+	}
+	var h, m int
+	var sec float64
+	if len(parts) == 3 {
+		if !parseUint(parts[0], &h) {
+			return 0, false
+		}
+		parts = parts[1:]
+	}
+	if !parseUint(parts[0], &m) || m >= 60 {
+		return 0, false
+	}
+	secStr := strings.Replace(parts[1], ",", ".", 1)
+	whole, frac, _ := strings.Cut(secStr, ".")
+	var s0 int
+	if !parseUint(whole, &s0) || s0 >= 60 {
+		return 0, false
+	}
+	sec = float64(s0)
+	if frac != "" {
+		var f int
+		if !parseUint(frac, &f) {
+			return 0, false
+		}
+		div := 1.0
+		for range frac {
+			div *= 10
+		}
+		sec += float64(f) / div
+	}
+	return float64(h*3600+m*60) + sec, true
+}
+
+// parseUint 解析纯数字字符串
+func parseUint(s string, out *int) bool {
+	if s == "" || len(s) > 9 {
+		return false
+	}
+	n := 0
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+		n = n*10 + int(r-'0')
+	}
+	*out = n
+	return true
+}
+
+// parseTiming 解析 "start --> end" 时间轴，忽略 end 后的 WebVTT 样式设置
+func parseTiming(line string) (start, end float64, err error) {
+	left, right, ok := strings.Cut(line, "-->")
+	if !ok {
+		return 0, 0, fmt.Errorf("时间轴格式错误: %q", line)
+	}
+	if fields := strings.Fields(right); len(fields) > 0 {
+		right = fields[0]
+	}
+	start, ok = parseClock(left)
+	if !ok {
+		return 0, 0, fmt.Errorf("开始时间格式错误: %q", strings.TrimSpace(left))
+	}
+	end, ok = parseClock(right)
+	if !ok {
+		return 0, 0, fmt.Errorf("结束时间格式错误: %q", strings.TrimSpace(right))
+	}
+	return start, end, nil
+}
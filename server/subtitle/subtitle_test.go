@@ -0,0 +1,239 @@
+package subtitle
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const bilingualSRT = `1
+00:00:01,000 --> 00:00:02,500
+I want to go home.
+我想回家。
+
+2
+00:00:03,000 --> 00:00:04,000
+<i>But it's raining,</i>
+但是在下雨，
+
+3
+00:00:04,200 --> 00:00:05,500
+so let's wait.
+所以等等吧。
+
+4
+00:00:06,000 --> 00:00:05,000
+Bad timing.
+
+5
+00:00:07,000 --> 00:00:08,000
+[music]
+`
+
+func TestBuildBilingualSRT(t *testing.T) {
+	track, err := Parse(FormatSRT, []byte("\xEF\xBB\xBF"+strings.ReplaceAll(bilingualSRT, "\n", "\r\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := Build(track, nil, DefaultOptions)
+
+	want := []Sentence{
+		{Line: 2, Start: 1, End: 2.5, Text: "I want to go home.", Translation: "我想回家。"},
+		{Line: 7, Start: 3, End: 5.5, Text: "But it's raining, so let's wait.", Translation: "但是在下雨， 所以等等吧。"},
+	}
+	if !reflect.DeepEqual(r.Sentences, want) {
+		t.Errorf("sentences = %+v, want %+v", r.Sentences, want)
+	}
+	if r.Cues != 3 || r.Untranslated != 0 {
+		t.Errorf("cues = %d, untranslated = %d", r.Cues, r.Untranslated)
+	}
+	wantIssues := []Issue{
+		{Line: 17, Level: issueError, Message: "结束时间不晚于开始时间，已跳过"},
+		{Line: 21, Level: issueWarning, Message: "字幕不含对白，已跳过"},
+	}
+	if !reflect.DeepEqual(r.Issues, wantIssues) {
+		t.Errorf("issues = %+v, want %+v", r.Issues, wantIssues)
+	}
+}
+
+func TestBuildSeparateTranslation(t *testing.T) {
+	en, err := Parse(FormatSRT, []byte("1\n00:00:01,000 --> 00:00:03,000\nGood morning.\n\n2\n00:00:04,000 --> 00:00:06,000\nSee you.\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	zh, err := Parse(FormatSRT, []byte("1\n00:00:00,900 --> 00:00:03,100\n早上好。\n\n2\n00:00:10,000 --> 00:00:11,000\n多余的译文\n\n3\n00:00:04,000 --> 00:00:05,000\nHello\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := Build(en, zh, DefaultOptions)
+
+	want := []Sentence{
+		{Line: 2, Start: 1, End: 3, Text: "Good morning.", Translation: "早上好。"},
+		{Line: 6, Start: 4, End: 6, Text: "See you."},
+	}
+	if !reflect.DeepEqual(r.Sentences, want) {
+		t.Errorf("sentences = %+v, want %+v", r.Sentences, want)
+	}
+	if r.Untranslated != 1 {
+		t.Errorf("untranslated = %d, want 1", r.Untranslated)
+	}
+	wantIssues := []Issue{
+		{Line: 10, Level: issueWarning, Message: "译文字幕不含中文，已忽略"},
+		{Line: 6, Level: issueWarning, Message: "未找到时间对应的英文字幕，译文已忽略"},
+	}
+	if !reflect.DeepEqual(r.Issues, wantIssues) {
+		t.Errorf("issues = %+v, want %+v", r.Issues, wantIssues)
+	}
+}
+
+func TestParseFormats(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		want   []Cue
+		issues []int // 问题所在行号
+	}{
+		{
+			name:   "vtt",
+			format: FormatVTT,
+			data: "WEBVTT\n\nNOTE 注释\n00:00:00.000 --> 00:00:09.000\n\n" +
+				"intro\n00:01.000 --> 00:02.500 align:start\n<v Bob>Hi <c.loud>there</c>!</v>\n嗨！\n\n" +
+				"00:00:03.000 --> 00:00:0x.000\nBroken\n",
+			want:   []Cue{{Line: 7, Start: 1, End: 2.5, Text: "Hi there!", Translation: "嗨！"}},
+			issues: []int{11},
+		},
+		{
+			name:   "srt cues without blank line",
+			format: FormatSRT,
+			data:   "1\n00:00:01,000 --> 00:00:02,000\nOne.\n2\n00:00:02,000 --> 00:00:03,000\n- Two.\n- Three.\n",
+			want: []Cue{
+				{Line: 2, Start: 1, End: 2, Text: "One."},
+				{Line: 5, Start: 2, End: 3, Text: "Two. Three."},
+			},
+		},
+		{
+			name:   "srt missing timing",
+			format: FormatSRT,
+			data:   "1\nno timing here\n\n2\n00:00:01,000 --> 00:00:02,000\nOK.\n",
+			want:   []Cue{{Line: 5, Start: 1, End: 2, Text: "OK."}},
+			issues: []int{1},
+		},
+		{
+			name:   "ass",
+			format: FormatASS,
+			data: "[Script Info]\nTitle: test\n\n[Events]\n" +
+				"Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n" +
+				`Dialogue: 0,0:00:01.50,0:00:03.00,Default,,0,0,0,,{\an8}Wait, what?\N等等，什么？` + "\n" +
+				"Dialogue: 0,bad,0:00:04.00,Default,,0,0,0,,Skipped\n" +
+				"Comment: 0,0:00:05.00,0:00:06.00,Default,,0,0,0,,Ignored\n",
+			want:   []Cue{{Line: 6, Start: 1.5, End: 3, Text: "Wait, what?", Translation: "等等，什么？"}},
+			issues: []int{7},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			track, err := Parse(tt.format, []byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(track.Cues, tt.want) {
+				t.Errorf("cues = %+v, want %+v", track.Cues, tt.want)
+			}
+			var lines []int
+			for _, is := range track.Issues {
+				lines = append(lines, is.Line)
+			}
+			if !reflect.DeepEqual(lines, tt.issues) {
+				t.Errorf("issue lines = %v, want %v (%+v)", lines, tt.issues, track.Issues)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name, format, data string
+	}{
+		{"vtt without header", FormatVTT, "00:00:01.000 --> 00:00:02.000\nHi\n"},
+		{"ass without events", FormatASS, "[Script Info]\nTitle: x\n"},
+		{"unknown format", "txt", "hello"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.format, []byte(tt.data)); err == nil {
+				t.Error("Parse succeeded, want error")
+			}
+		})
+	}
+}
+
+func TestMergeAndOverlap(t *testing.T) {
+	tests := []struct {
+		name string
+		cues []Cue
+		want []Sentence
+	}{
+		{
+			name: "gap too long is not merged",
+			cues: []Cue{
+				{Line: 1, Start: 0, End: 1, Text: "And then"},
+				{Line: 2, Start: 3, End: 4, Text: "nothing."},
+			},
+			want: []Sentence{
+				{Line: 1, Start: 0, End: 1, Text: "And then"},
+				{Line: 2, Start: 3, End: 4, Text: "nothing."},
+			},
+		},
+		{
+			name: "ellipsis continues sentence",
+			cues: []Cue{
+				{Line: 1, Start: 0, End: 1, Text: "I think..."},
+				{Line: 2, Start: 1.2, End: 2, Text: "maybe not."},
+			},
+			want: []Sentence{{Line: 1, Start: 0, End: 2, Text: "I think... maybe not."}},
+		},
+		{
+			name: "overlap trims previous and merges near duplicates",
+			cues: []Cue{
+				{Line: 1, Start: 0, End: 3, Text: "Hello."},
+				{Line: 2, Start: 2, End: 4, Text: "World."},
+				{Line: 3, Start: 2.1, End: 5, Text: "Again."},
+			},
+			want: []Sentence{
+				{Line: 1, Start: 0, End: 2, Text: "Hello."},
+				{Line: 2, Start: 2, End: 5, Text: "World. Again."},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := Build(&Track{Cues: tt.cues}, nil, DefaultOptions)
+			if !reflect.DeepEqual(r.Sentences, tt.want) {
+				t.Errorf("sentences = %+v, want %+v", r.Sentences, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	tests := []struct {
+		in   string
+		want float64
+		ok   bool
+	}{
+		{"00:00:01,500", 1.5, true},
+		{"01:02:03.25", 3723.25, true},
+		{"02:03.000", 123, true},
+		{"0:00:01.50", 1.5, true},
+		{"00:60:00,000", 0, false},
+		{"1.5", 0, false},
+		{"aa:00:01,000", 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseClock(tt.in)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("parseClock(%q) = %v, %v, want %v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package subtitle
+
+import (
+	"errors"
+	"strings"
+)
+
+// parseVTT 解析 WebVTT 字幕
+// 跳过 NOTE/STYLE/REGION 块，文本中的 <v>、<c>、<i> 等标签及行内时间戳会被去除
+func parseVTT(lines []string) (*Track, error) {
+	blocks := splitBlocks(lines)
+	if len(blocks) == 0 || !strings.HasPrefix(strings.TrimSpace(blocks[0].lines[0]), "WEBVTT") {
+		return nil, errors.New("WebVTT 文件必须以 WEBVTT 开头")
+	}
+
+	t := &Track{}
+	for _, b := range blocks[1:] {
+		first := strings.TrimSpace(b.lines[0])
+		if first == "NOTE" || strings.HasPrefix(first, "NOTE ") || first == "STYLE" || first == "REGION" {
+			continue
+		}
+		t.parseCueBlock(b)
+	}
+	return t, nil
+}
@@ -1,48 +1,74 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"server/config"
 )
 
-// JWT密钥
-var jwtSecret = []byte("kaikouyi-secret-key-2024")
+const (
+	// AccessTokenTTL 访问令牌有效期
+	AccessTokenTTL = 15 * time.Minute
+	// RefreshTokenTTL 刷新令牌有效期
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
 
 // Claims JWT声明结构
 type Claims struct {
-	UserID   uint   `json:"user_id"`
-	Username string `json:"username"`
+	UserID    uint   `json:"user_id"`
+	Username  string `json:"username"`
+	SessionID string `json:"sid,omitempty"` // 会话ID，对应刷新令牌的 FamilyID
 	jwt.RegisteredClaims
 }
 
-// GenerateToken 生成JWT令牌
-// 参数: userID 用户ID, username 用户名
+// GenerateToken 生成JWT访问令牌
+// 参数: userID 用户ID, username 用户名, sessionID 会话ID
 // 返回: token字符串和错误
-func GenerateToken(userID uint, username string) (string, error) {
-	// 设置过期时间7天
-	expirationTime := time.Now().Add(7 * 24 * time.Hour)
+func GenerateToken(userID uint, username string, sessionID string) (string, error) {
+	key := keys.signing
+	if key == nil {
+		return "", errors.New("no active signing key")
+	}
+
+	now := time.Now()
 	claims := &Claims{
-		UserID:   userID,
-		Username: username,
+		UserID:    userID,
+		Username:  username,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(now.Add(AccessTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	token := jwt.NewWithClaims(key.method, claims)
+	token.Header["kid"] = key.kid
+	return token.SignedString(key.signKey)
 }
 
 // ValidateToken 验证JWT令牌
+// 根据头部的 kid 选择验证密钥，并要求算法与该密钥一致
 // 参数: tokenString token字符串
 // 返回: Claims解析结果和错误
 func ValidateToken(tokenString string) (*Claims, error) {
 	claims := &Claims{}
 	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
+		kid, _ := token.Header["kid"].(string)
+		key, ok := keys.byKID[kid]
+		if !ok {
+			return nil, fmt.Errorf("unknown kid %q", kid)
+		}
+		if token.Method.Alg() != key.method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s for kid %q", token.Method.Alg(), kid)
+		}
+		return key.verifyKey, nil
+	}, jwt.WithValidMethods(supportedAlgorithms))
 	if err != nil {
 		return nil, err
 	}
@@ -51,3 +77,53 @@ func ValidateToken(tokenString string) (*Claims, error) {
 	}
 	return claims, nil
 }
+
+// GenerateRefreshToken 生成不透明的刷新令牌
+// 返回: 交给客户端的令牌明文、用于入库的摘要和错误
+func GenerateRefreshToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken 计算令牌的SHA-256摘要
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewSessionID 生成随机会话ID
+func NewSessionID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// InitJWT 根据配置加载签名密钥
+// 未配置任何密钥时生成一个临时HS256密钥，重启后已签发的令牌全部失效
+func InitJWT(cfg config.JWTConfig) error {
+	if len(cfg.Keys) == 0 {
+		secret := make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+		Warn("InitJWT - No signing keys configured, using an ephemeral HS256 key")
+		cfg = config.JWTConfig{
+			ActiveKID: "ephemeral",
+			Keys:      []config.JWTKey{{KID: "ephemeral", Algorithm: "HS256", Secret: string(secret)}},
+		}
+	}
+
+	ring, err := loadKeyRing(cfg)
+	if err != nil {
+		return err
+	}
+	keys = ring
+	Info("InitJWT - Loaded %d signing keys, active kid: %s (%s)", len(ring.byKID), ring.signing.kid, ring.signing.method.Alg())
+	return nil
+}
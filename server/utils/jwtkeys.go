@@ -0,0 +1,194 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/golang-jwt/jwt/v5"
+	"server/config"
+)
+
+// supportedAlgorithms 允许的签名算法
+var supportedAlgorithms = []string{
+	jwt.SigningMethodHS256.Alg(),
+	jwt.SigningMethodRS256.Alg(),
+	jwt.SigningMethodEdDSA.Alg(),
+}
+
+// signingKey 已解析的签名/验证密钥
+type signingKey struct {
+	kid       string
+	method    jwt.SigningMethod
+	signKey   interface{} // 为空表示该密钥仅用于验证
+	verifyKey interface{}
+	publicKey crypto.PublicKey // 非对称密钥的公钥，用于发布JWKS
+}
+
+// keyRing 当前加载的密钥集合
+type keyRing struct {
+	signing *signingKey
+	byKID   map[string]*signingKey
+}
+
+var keys = &keyRing{byKID: map[string]*signingKey{}}
+
+// JWK JSON Web Key
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKSet JSON Web Key Set
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// loadKeyRing 解析配置中的所有密钥
+func loadKeyRing(cfg config.JWTConfig) (*keyRing, error) {
+	ring := &keyRing{byKID: make(map[string]*signingKey, len(cfg.Keys))}
+	for _, k := range cfg.Keys {
+		if k.KID == "" {
+			return nil, fmt.Errorf("jwt key without kid")
+		}
+		if _, dup := ring.byKID[k.KID]; dup {
+			return nil, fmt.Errorf("duplicate jwt kid %q", k.KID)
+		}
+		key, err := parseKey(k)
+		if err != nil {
+			return nil, fmt.Errorf("jwt key %q: %w", k.KID, err)
+		}
+		ring.byKID[k.KID] = key
+	}
+
+	active := cfg.ActiveKID
+	if active == "" && len(cfg.Keys) == 1 {
+		active = cfg.Keys[0].KID
+	}
+	key, ok := ring.byKID[active]
+	if !ok {
+		return nil, fmt.Errorf("active kid %q not found", active)
+	}
+	if key.signKey == nil {
+		return nil, fmt.Errorf("active kid %q has no private key", active)
+	}
+	ring.signing = key
+	return ring, nil
+}
+
+// parseKey 按算法解析单个密钥
+func parseKey(k config.JWTKey) (*signingKey, error) {
+	key := &signingKey{kid: k.KID}
+
+	switch k.Algorithm {
+	case "HS256":
+		if len(k.Secret) < 32 {
+			return nil, fmt.Errorf("HS256 secret must be at least 32 bytes")
+		}
+		key.method = jwt.SigningMethodHS256
+		key.signKey = []byte(k.Secret)
+		key.verifyKey = []byte(k.Secret)
+
+	case "RS256":
+		key.method = jwt.SigningMethodRS256
+		if k.PrivateKeyFile != "" {
+			data, err := os.ReadFile(k.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			priv, err := jwt.ParseRSAPrivateKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			key.signKey = priv
+			key.publicKey = &priv.PublicKey
+		} else if k.PublicKeyFile != "" {
+			data, err := os.ReadFile(k.PublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			pub, err := jwt.ParseRSAPublicKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			key.publicKey = pub
+		} else {
+			return nil, fmt.Errorf("RS256 requires private_key_file or public_key_file")
+		}
+		key.verifyKey = key.publicKey
+
+	case "EdDSA":
+		key.method = jwt.SigningMethodEdDSA
+		if k.PrivateKeyFile != "" {
+			data, err := os.ReadFile(k.PrivateKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			priv, err := jwt.ParseEdPrivateKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			key.signKey = priv
+			key.publicKey = priv.(ed25519.PrivateKey).Public()
+		} else if k.PublicKeyFile != "" {
+			data, err := os.ReadFile(k.PublicKeyFile)
+			if err != nil {
+				return nil, err
+			}
+			pub, err := jwt.ParseEdPublicKeyFromPEM(data)
+			if err != nil {
+				return nil, err
+			}
+			key.publicKey = pub
+		} else {
+			return nil, fmt.Errorf("EdDSA requires private_key_file or public_key_file")
+		}
+		key.verifyKey = key.publicKey
+
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", k.Algorithm)
+	}
+
+	return key, nil
+}
+
+// PublicJWKS 返回所有非对称密钥的公钥集合
+// HS256 共享密钥不会被发布
+func PublicJWKS() JWKSet {
+	set := JWKSet{Keys: []JWK{}}
+	for _, key := range keys.byKID {
+		switch pub := key.publicKey.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Kid: key.kid,
+				Use: "sig",
+				Alg: key.method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Kid: key.kid,
+				Use: "sig",
+				Alg: key.method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
@@ -0,0 +1,65 @@
+package waveform
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"server/speech"
+)
+
+// ErrUnsupported 服务端无法解码该格式
+var ErrUnsupported = errors.New("暂不支持该音频格式的波形")
+
+const (
+	// decodeRate 外部解码器输出的采样率，波形只需要包络，无需原始采样率
+	decodeRate = 16000
+	// decodeTimeout 单个文件的解码超时
+	decodeTimeout = 2 * time.Minute
+)
+
+// Decode 将音频解码为单声道采样
+// WAV 使用内置解码器；MP3、AAC 等压缩格式需配置 ffmpeg，未配置时返回 ErrUnsupported
+func Decode(ctx context.Context, data []byte, contentType, ffmpegPath string) (*speech.Audio, error) {
+	if contentType == "audio/wav" {
+		a, err := speech.DecodeWAV(data)
+		if err == nil || ffmpegPath == "" {
+			return a, err
+		}
+	}
+	if ffmpegPath == "" {
+		return nil, ErrUnsupported
+	}
+	return decodeFFmpeg(ctx, data, ffmpegPath)
+}
+
+// decodeFFmpeg 调用 ffmpeg 解码为 16 位单声道 PCM
+func decodeFFmpeg(ctx context.Context, data []byte, ffmpegPath string) (*speech.Audio, error) {
+	ctx, cancel := context.WithTimeout(ctx, decodeTimeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, ffmpegPath,
+		"-v", "error", "-i", "pipe:0",
+		"-f", "s16le", "-ac", "1", "-ar", fmt.Sprint(decodeRate), "pipe:1")
+	cmd.Stdin = bytes.NewReader(data)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg: %v: %s", err, bytes.TrimSpace(stderr.Bytes()))
+	}
+
+	raw := stdout.Bytes()
+	samples := make([]float64, len(raw)/2)
+	for i := range samples {
+		samples[i] = float64(int16(binary.LittleEndian.Uint16(raw[2*i:]))) / 32768
+	}
+	if len(samples) == 0 {
+		return nil, errors.New("ffmpeg: no audio decoded")
+	}
+	return &speech.Audio{Samples: samples, SampleRate: decodeRate}, nil
+}
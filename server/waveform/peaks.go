@@ -0,0 +1,58 @@
+package waveform
+
+import "math"
+
+// Resolutions 生成的分辨率（每秒峰值对数），从粗到细
+// 粗分辨率用于整段进度条，细分辨率用于单句放大显示
+var Resolutions = []int{10, 50, 200}
+
+// DefaultResolution 未指定分辨率时使用
+const DefaultResolution = 50
+
+// Peaks 按每秒 resolution 段计算采样的最小值、最大值
+// 返回的数据依次为每段的最小值、最大值，量化为 8 位有符号整数
+func Peaks(samples []float64, sampleRate, resolution int) []byte {
+	if sampleRate <= 0 || resolution <= 0 || len(samples) == 0 {
+		return nil
+	}
+	per := float64(sampleRate) / float64(resolution)
+	n := int(math.Ceil(float64(len(samples)) / per))
+	out := make([]byte, 0, 2*n)
+	for i := 0; i < n; i++ {
+		lo := int(float64(i) * per)
+		hi := min(int(float64(i+1)*per), len(samples))
+		if hi <= lo {
+			hi = min(lo+1, len(samples))
+		}
+		minV, maxV := samples[lo], samples[lo]
+		for _, v := range samples[lo+1 : hi] {
+			minV = min(minV, v)
+			maxV = max(maxV, v)
+		}
+		out = append(out, byte(quantize(minV)), byte(quantize(maxV)))
+	}
+	return out
+}
+
+// Nearest 返回与请求值最接近的已生成分辨率
+func Nearest(resolution int) int {
+	best := Resolutions[0]
+	for _, r := range Resolutions[1:] {
+		if abs(r-resolution) < abs(best-resolution) {
+			best = r
+		}
+	}
+	return best
+}
+
+// quantize 将 [-1, 1] 的采样值量化为 8 位有符号整数
+func quantize(v float64) int8 {
+	return int8(math.Max(-128, math.Min(127, math.Round(v*127))))
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,224 @@
+package waveform
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"server/models"
+	"server/storage"
+	"server/utils"
+)
+
+const (
+	// batchSize 每次从数据库取出的待处理任务数
+	batchSize = 20
+	// maxAttempts 读取存储等临时错误的重试次数，解码失败不重试
+	maxAttempts = 3
+	// maxErrorLen 记录的失败原因长度上限
+	maxErrorLen = 255
+)
+
+// kick 唤醒后台任务，容量为 1，多次入队只唤醒一次
+var kick = make(chan struct{}, 1)
+
+// Enqueue 为音频创建波形生成任务，已存在时重新生成
+func Enqueue(db *gorm.DB, sourceType string, sourceID uint) error {
+	w := models.Waveform{SourceType: sourceType, SourceID: sourceID, Status: models.WaveformStatusPending}
+	err := db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "source_type"}, {Name: "source_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"status":     models.WaveformStatusPending,
+			"error":      "",
+			"attempts":   0,
+			"updated_at": time.Now(),
+			"deleted_at": nil,
+		}),
+	}).Create(&w).Error
+	if err != nil {
+		return err
+	}
+	select {
+	case kick <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Find 查询音频的波形，不存在时返回 gorm.ErrRecordNotFound
+func Find(db *gorm.DB, sourceType string, sourceID uint) (*models.Waveform, error) {
+	var w models.Waveform
+	err := db.Where("source_type = ? AND source_id = ?", sourceType, sourceID).First(&w).Error
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// Remove 删除音频的波形及峰值数据
+func Remove(db *gorm.DB, sourceType string, sourceID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var ids []uint
+		err := tx.Model(&models.Waveform{}).Unscoped().
+			Where("source_type = ? AND source_id = ?", sourceType, sourceID).
+			Pluck("id", &ids).Error
+		if err != nil || len(ids) == 0 {
+			return err
+		}
+		if err := tx.Unscoped().Where("waveform_id IN ?", ids).Delete(&models.WaveformPeaks{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.Waveform{}, ids).Error
+	})
+}
+
+// StartWorker 启动后台任务，生成等待中的波形
+// 新任务入队时立即唤醒，另外每隔 interval 检查一次，接上重启前未完成的任务
+func StartWorker(db *gorm.DB, store storage.Storage, ffmpegPath string, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			n, err := RunPending(context.Background(), db, store, ffmpegPath)
+			if err != nil {
+				utils.Error("Waveform worker - Run failed after %d waveforms: %v", n, err)
+			} else if n > 0 {
+				utils.Info("Waveform worker - Processed %d waveforms", n)
+			}
+			select {
+			case <-ticker.C:
+			case <-kick:
+			}
+		}
+	}()
+}
+
+// RunPending 处理当前所有等待中的任务，返回处理数量
+// 每个任务每轮只处理一次，临时错误留到下一轮重试
+func RunPending(ctx context.Context, db *gorm.DB, store storage.Storage, ffmpegPath string) (int, error) {
+	processed := 0
+	var lastID uint
+	for {
+		var batch []models.Waveform
+		err := db.Where("status = ? AND id > ?", models.WaveformStatusPending, lastID).
+			Order("id ASC").Limit(batchSize).Find(&batch).Error
+		if err != nil {
+			return processed, err
+		}
+		if len(batch) == 0 {
+			return processed, nil
+		}
+		for i := range batch {
+			if err := processGuarded(ctx, db, store, ffmpegPath, &batch[i]); err != nil {
+				return processed, err
+			}
+			lastID = batch[i].ID
+			processed++
+		}
+		if err := ctx.Err(); err != nil {
+			return processed, err
+		}
+	}
+}
+
+// Process 解码音频并写入各分辨率的峰值
+// 只有数据库错误会返回 error，音频相关的失败记录在任务状态中
+func Process(ctx context.Context, db *gorm.DB, store storage.Storage, ffmpegPath string, w *models.Waveform) error {
+	data, contentType, err := load(ctx, db, store, w)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// 音频已删除，任务随之作废
+		return Remove(db, w.SourceType, w.SourceID)
+	}
+	if errors.Is(err, storage.ErrNotFound) {
+		return finish(db, w, models.WaveformStatusFailed, "音频文件不存在")
+	}
+	if err != nil {
+		w.Attempts++
+		if w.Attempts >= maxAttempts {
+			return finish(db, w, models.WaveformStatusFailed, err.Error())
+		}
+		utils.Warn("Waveform worker - Load failed: Source=%s/%d, Attempt=%d: %v", w.SourceType, w.SourceID, w.Attempts, err)
+		return db.Model(w).Update("attempts", w.Attempts).Error
+	}
+
+	audio, err := Decode(ctx, data, contentType, ffmpegPath)
+	if errors.Is(err, ErrUnsupported) {
+		return finish(db, w, models.WaveformStatusUnsupported, err.Error())
+	}
+	if err != nil {
+		utils.Warn("Waveform worker - Decode failed: Source=%s/%d: %v", w.SourceType, w.SourceID, err)
+		return finish(db, w, models.WaveformStatusFailed, err.Error())
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("waveform_id = ?", w.ID).Delete(&models.WaveformPeaks{}).Error; err != nil {
+			return err
+		}
+		for _, r := range Resolutions {
+			data := Peaks(audio.Samples, audio.SampleRate, r)
+			peaks := models.WaveformPeaks{WaveformID: w.ID, Resolution: r, Length: len(data) / 2, Data: data}
+			if err := tx.Create(&peaks).Error; err != nil {
+				return err
+			}
+		}
+		w.Duration = audio.Duration()
+		return finish(tx, w, models.WaveformStatusReady, "")
+	})
+}
+
+// processGuarded 处理一个任务，解码用户上传的音频时 panic 记为任务失败，不再重试
+func processGuarded(ctx context.Context, db *gorm.DB, store storage.Storage, ffmpegPath string, w *models.Waveform) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			utils.Error("Waveform worker - Panic: Source=%s/%d: %v\n%s", w.SourceType, w.SourceID, r, debug.Stack())
+			err = finish(db, w, models.WaveformStatusFailed, fmt.Sprintf("panic: %v", r))
+		}
+	}()
+	return Process(ctx, db, store, ffmpegPath, w)
+}
+
+// finish 更新任务的最终状态
+func finish(db *gorm.DB, w *models.Waveform, status, reason string) error {
+	if len(reason) > maxErrorLen {
+		reason = reason[:maxErrorLen]
+	}
+	w.Status, w.Error = status, reason
+	return db.Model(w).Select("status", "error", "attempts", "duration").Updates(w).Error
+}
+
+// load 读取任务对应的音频内容
+func load(ctx context.Context, db *gorm.DB, store storage.Storage, w *models.Waveform) ([]byte, string, error) {
+	var key, contentType string
+	switch w.SourceType {
+	case models.WaveformSourceAudio:
+		var asset models.AudioAsset
+		if err := db.First(&asset, w.SourceID).Error; err != nil {
+			return nil, "", err
+		}
+		key, contentType = asset.StorageKey, asset.ContentType
+	case models.WaveformSourceRecording:
+		var rec models.Recording
+		if err := db.First(&rec, w.SourceID).Error; err != nil {
+			return nil, "", err
+		}
+		key, contentType = rec.StorageKey, rec.ContentType
+	default:
+		return nil, "", fmt.Errorf("unknown waveform source %q: %w", w.SourceType, gorm.ErrRecordNotFound)
+	}
+
+	obj, err := store.Open(ctx, key)
+	if err != nil {
+		return nil, "", err
+	}
+	defer obj.Body.Close()
+	data, err := io.ReadAll(obj.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("read audio: %w", err)
+	}
+	return data, contentType, nil
+}